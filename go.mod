@@ -3,6 +3,7 @@ module github.com/mrostamii/rancher-mcp-server
 go 1.23.0
 
 require (
+	github.com/gorilla/websocket v1.5.0
 	github.com/mark3labs/mcp-go v0.44.1
 	github.com/spf13/cobra v1.8.1
 	github.com/spf13/viper v1.19.0
@@ -10,6 +11,7 @@ require (
 	k8s.io/apimachinery v0.30.14
 	k8s.io/cli-runtime v0.30.14
 	k8s.io/client-go v0.30.14
+	sigs.k8s.io/yaml v1.3.0
 )
 
 require (
@@ -62,7 +64,6 @@ require (
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/mux v1.8.0 // indirect
-	github.com/gorilla/websocket v1.5.0 // indirect
 	github.com/gosuri/uitable v0.0.4 // indirect
 	github.com/gregjones/httpcache v0.0.0-20180305231024-9cad4c3443a7 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
@@ -161,5 +162,4 @@ require (
 	sigs.k8s.io/kustomize/api v0.13.5-0.20230601165947-6ce0bf390ce3 // indirect
 	sigs.k8s.io/kustomize/kyaml v0.14.3-0.20230601165947-6ce0bf390ce3 // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.1 // indirect
-	sigs.k8s.io/yaml v1.3.0 // indirect
 )
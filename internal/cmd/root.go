@@ -42,6 +42,11 @@ func NewRootCommand() *cobra.Command {
 	flags.StringSliceVar(&cfg.Toolsets, "toolsets", cfg.Toolsets, "Toolsets: harvester, rancher (Steve + Norman /v3), kubernetes, helm, fleet")
 	flags.StringSliceVar(&cfg.AllowedNamespaces, "allowed-namespaces", cfg.AllowedNamespaces, "Namespaces to allow (empty = all except denied)")
 	flags.StringSliceVar(&cfg.DeniedNamespaces, "denied-namespaces", cfg.DeniedNamespaces, "Namespaces to always deny")
+	flags.BoolVar(&cfg.AllowExec, "allow-exec", cfg.AllowExec, "Enable kubernetes_exec (non-interactive pod exec)")
+	flags.StringSliceVar(&cfg.ExecAllowedCommands, "exec-allowed-commands", cfg.ExecAllowedCommands, "Executables kubernetes_exec may run (\"*\" = any)")
+	flags.StringSliceVar(&cfg.ProbeAllowedPorts, "probe-allowed-ports", cfg.ProbeAllowedPorts, "Ports (number or name) kubernetes_http_probe may target (empty = any)")
+	flags.StringVar(&cfg.ExportDir, "export-dir", cfg.ExportDir, "Local directory kubernetes_export may write manifests to (empty = inline only)")
+	flags.IntVar(&cfg.MaxBulkObjects, "max-bulk-objects", cfg.MaxBulkObjects, "Max objects a selector-based bulk operation (delete_collection, label, annotate) may touch")
 	flags.String("config", "", "Config file (TOML or YAML)")
 	_ = viper.BindPFlag("config", flags.Lookup("config"))
 
@@ -57,6 +62,11 @@ func NewRootCommand() *cobra.Command {
 	_ = viper.BindPFlag("toolsets", root.PersistentFlags().Lookup("toolsets"))
 	_ = viper.BindPFlag("allowed_namespaces", root.PersistentFlags().Lookup("allowed-namespaces"))
 	_ = viper.BindPFlag("denied_namespaces", root.PersistentFlags().Lookup("denied-namespaces"))
+	_ = viper.BindPFlag("allow_exec", root.PersistentFlags().Lookup("allow-exec"))
+	_ = viper.BindPFlag("exec_allowed_commands", root.PersistentFlags().Lookup("exec-allowed-commands"))
+	_ = viper.BindPFlag("probe_allowed_ports", root.PersistentFlags().Lookup("probe-allowed-ports"))
+	_ = viper.BindPFlag("max_bulk_objects", root.PersistentFlags().Lookup("max-bulk-objects"))
+	_ = viper.BindPFlag("export_dir", root.PersistentFlags().Lookup("export-dir"))
 
 	viper.SetEnvPrefix(envPrefix)
 	viper.AutomaticEnv()
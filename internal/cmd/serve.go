@@ -30,11 +30,16 @@ func runServe(cfg *config.Config) error {
 	)
 
 	policy := &security.Policy{
-		ReadOnly:           cfg.ReadOnly,
-		DisableDestructive: cfg.DisableDestructive,
-		ShowSensitiveData:  cfg.ShowSensitiveData,
-		AllowedNamespaces:  cfg.AllowedNamespaces,
-		DeniedNamespaces:   cfg.DeniedNamespaces,
+		ReadOnly:            cfg.ReadOnly,
+		DisableDestructive:  cfg.DisableDestructive,
+		ShowSensitiveData:   cfg.ShowSensitiveData,
+		AllowedNamespaces:   cfg.AllowedNamespaces,
+		DeniedNamespaces:    cfg.DeniedNamespaces,
+		AllowExec:           cfg.AllowExec,
+		ExecAllowedCommands: cfg.ExecAllowedCommands,
+		ProbeAllowedPorts:   cfg.ProbeAllowedPorts,
+		MaxBulkObjects:      cfg.MaxBulkObjects,
+		ExportDir:           cfg.ExportDir,
 	}
 
 	steveClient := rancher.NewSteveClient(cfg.RancherServerURL, cfg.RancherToken, cfg.TLSInsecure)
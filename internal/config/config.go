@@ -20,6 +20,19 @@ type Config struct {
 	AllowedNamespaces  []string `mapstructure:"allowed_namespaces"`
 	DeniedNamespaces   []string `mapstructure:"denied_namespaces"`
 
+	// Pod exec (kubernetes_exec); off by default and independent of read_only
+	AllowExec           bool     `mapstructure:"allow_exec"`
+	ExecAllowedCommands []string `mapstructure:"exec_allowed_commands"`
+
+	// HTTP probe (kubernetes_http_probe) port allowlist; empty = any
+	ProbeAllowedPorts []string `mapstructure:"probe_allowed_ports"`
+
+	// Selector-based bulk operations (delete_collection, label, annotate): max objects per call
+	MaxBulkObjects int `mapstructure:"max_bulk_objects"`
+
+	// Local directory kubernetes_export may write manifests to; empty = inline output only
+	ExportDir string `mapstructure:"export_dir"`
+
 	// Toolsets (enabled set names)
 	Toolsets []string `mapstructure:"toolsets"`
 }
@@ -27,13 +40,16 @@ type Config struct {
 // DefaultConfig returns defaults for running as stdio MCP server.
 func DefaultConfig() *Config {
 	return &Config{
-		Port:               0,
-		LogLevel:           2,
+		Port:                0,
+		LogLevel:            2,
 		Transport:           "stdio",
-		ReadOnly:           true,
-		DisableDestructive: false,
-		ShowSensitiveData:  false,
-		DeniedNamespaces:   []string{"kube-system", "cattle-system"},
-		Toolsets:           []string{"harvester"},
+		ReadOnly:            true,
+		DisableDestructive:  false,
+		ShowSensitiveData:   false,
+		DeniedNamespaces:    []string{"kube-system", "cattle-system"},
+		AllowExec:           false,
+		ExecAllowedCommands: []string{"cat", "ls", "ps", "df", "head", "tail", "date", "hostname", "id", "uname"},
+		MaxBulkObjects:      50,
+		Toolsets:            []string{"harvester"},
 	}
 }
@@ -2,6 +2,7 @@ package security
 
 import (
 	"fmt"
+	"path"
 	"slices"
 	"strings"
 )
@@ -10,11 +11,16 @@ import (
 // Checks happen at registration time (write tools not registered when read-only)
 // and at runtime in handlers as defense-in-depth.
 type Policy struct {
-	ReadOnly           bool
-	DisableDestructive bool
-	ShowSensitiveData  bool
-	AllowedNamespaces  []string // Empty = all allowed (except denied)
-	DeniedNamespaces   []string // Always blocked
+	ReadOnly            bool
+	DisableDestructive  bool
+	ShowSensitiveData   bool
+	AllowedNamespaces   []string // Empty = all allowed (except denied)
+	DeniedNamespaces    []string // Always blocked
+	AllowExec           bool     // Pod exec is opt-in, independent of ReadOnly
+	ExecAllowedCommands []string // Executables (argv[0] basename) exec may run; "*" = any
+	ProbeAllowedPorts   []string // Ports (number or name) HTTP probes may target; empty = any
+	MaxBulkObjects      int      // Max objects per selector-based bulk operation; <= 0 = no bulk operations
+	ExportDir           string   // Local directory manifests may be exported to; empty = inline only
 }
 
 // CanWrite returns true if write operations (create, update, action) are allowed.
@@ -46,6 +52,144 @@ func (p *Policy) CheckDestructive() error {
 	return nil
 }
 
+// CanExec returns true if pod exec is enabled.
+func (p *Policy) CanExec() bool {
+	return p.AllowExec
+}
+
+// execWrappers run the command given in their arguments; allowing one by name would allow any command.
+var execWrappers = map[string]bool{
+	"sh": true, "bash": true, "ash": true, "dash": true, "zsh": true, "busybox": true, "xargs": true, "find": true,
+	"nice": true, "nohup": true, "timeout": true, "stdbuf": true, "ionice": true, "chroot": true, "nsenter": true,
+	"sudo": true, "su": true, "time": true, "watch": true, "setsid": true, "taskset": true, "command": true, "exec": true,
+}
+
+// CheckExec returns an error if exec is disabled or the command's executable is not in ExecAllowedCommands.
+// argv[0] is checked by basename; arguments are passed to the container without a shell. env is unwrapped
+// and the command it runs checked in turn; other wrappers (shells, xargs, timeout, ...) are refused unless
+// "*" is allowed. Without show-sensitive-data, arguments naming process environments (/proc/<pid>/environ)
+// or service account credentials are refused.
+func (p *Policy) CheckExec(command []string) error {
+	if !p.AllowExec {
+		return fmt.Errorf("exec is disabled (allow-exec=false)")
+	}
+	if len(command) == 0 || command[0] == "" {
+		return fmt.Errorf("exec command is empty")
+	}
+	if !p.ShowSensitiveData {
+		for _, arg := range command[1:] {
+			if sensitiveExecPath(arg) {
+				return fmt.Errorf("argument %q reads process environments or service account credentials; requires show-sensitive-data", arg)
+			}
+		}
+	}
+	if slices.Contains(p.ExecAllowedCommands, "*") {
+		return nil
+	}
+	bin := path.Base(command[0])
+	if !slices.Contains(p.ExecAllowedCommands, bin) && !slices.Contains(p.ExecAllowedCommands, command[0]) {
+		return fmt.Errorf("command %q is not in exec allowed commands", bin)
+	}
+	if bin == "env" {
+		wrapped, split := envArgs(command[1:])
+		if split {
+			return fmt.Errorf("env -S/--split-string is not allowed; pass the command as separate arguments")
+		}
+		if len(wrapped) > 0 {
+			return p.CheckExec(wrapped)
+		}
+		return nil
+	}
+	if execWrappers[bin] {
+		return fmt.Errorf("command %q runs other commands and is only allowed with exec allowed commands \"*\"", bin)
+	}
+	return nil
+}
+
+// EnvCommand returns the command env(1) would run after its options and NAME=value assignments.
+func EnvCommand(args []string) []string {
+	command, _ := envArgs(args)
+	return command
+}
+
+// envArgs parses env(1) arguments the way GNU getopt does (short option clusters, unambiguous long option
+// prefixes) and returns the command env would run, and whether -S/--split-string is given, which makes env
+// run a command line from the option's value.
+func envArgs(args []string) (command []string, split bool) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return args[i+1:], split
+		case strings.HasPrefix(a, "--"):
+			name, _, hasValue := strings.Cut(a[2:], "=")
+			switch {
+			case strings.HasPrefix("split-string", name):
+				split = true
+				if !hasValue {
+					i++
+				}
+			case (strings.HasPrefix("unset", name) || strings.HasPrefix("chdir", name)) && !hasValue:
+				i++ // option with a separate value
+			}
+		case strings.HasPrefix(a, "-") && a != "-":
+			for j, c := range a[1:] {
+				if c == 'S' {
+					split = true
+				}
+				if c == 'u' || c == 'C' || c == 'S' {
+					if j == len(a)-2 {
+						i++ // value in the next argument
+					}
+					break // the rest of the cluster is the value
+				}
+			}
+		case strings.Contains(a, "="):
+		default:
+			return args[i:], split
+		}
+	}
+	return nil, split
+}
+
+// sensitiveExecPath reports whether an exec argument names a process environment or mounted service
+// account credentials, which would bypass Secret redaction.
+func sensitiveExecPath(arg string) bool {
+	if _, v, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(arg, "-") {
+		arg = v // --file=/proc/1/environ
+	}
+	clean := path.Clean(arg)
+	return path.Base(clean) == "environ" ||
+		strings.Contains(clean, "serviceaccount") ||
+		strings.Contains(clean, "secrets/kubernetes.io")
+}
+
+// CheckProbePort returns an error if port may not be targeted by an HTTP probe.
+// When ProbeAllowedPorts is non-empty, port must be given and match an entry (case-insensitive for names).
+func (p *Policy) CheckProbePort(port string) error {
+	if len(p.ProbeAllowedPorts) == 0 {
+		return nil
+	}
+	if port == "" {
+		return fmt.Errorf("port is required when probe allowed ports are configured")
+	}
+	if !slices.ContainsFunc(p.ProbeAllowedPorts, func(a string) bool { return strings.EqualFold(a, port) }) {
+		return fmt.Errorf("port %q is not in probe allowed ports", port)
+	}
+	return nil
+}
+
+// CheckBulk returns an error if a selector-based bulk operation matching count objects exceeds MaxBulkObjects.
+func (p *Policy) CheckBulk(count int) error {
+	if p.MaxBulkObjects <= 0 {
+		return fmt.Errorf("bulk operations are disabled (max-bulk-objects=%d)", p.MaxBulkObjects)
+	}
+	if count > p.MaxBulkObjects {
+		return fmt.Errorf("%d objects match, more than max-bulk-objects=%d; narrow the selector", count, p.MaxBulkObjects)
+	}
+	return nil
+}
+
 // CanShowSecret returns true if sensitive data (e.g. Secret data) may be shown.
 func (p *Policy) CanShowSecret() bool {
 	return p.ShowSensitiveData
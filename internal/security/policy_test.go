@@ -49,7 +49,7 @@ func TestPolicy_CheckNamespace(t *testing.T) {
 	t.Run("denied overrides allowed", func(t *testing.T) {
 		p := &Policy{
 			AllowedNamespaces: []string{"default", "app"},
-			DeniedNamespaces:   []string{"default"},
+			DeniedNamespaces:  []string{"default"},
 		}
 		if err := p.CheckNamespace("default"); err == nil {
 			t.Error("default should be denied even when in allowed")
@@ -183,3 +183,114 @@ func TestPolicy_CheckWrite_CheckDestructive(t *testing.T) {
 		}
 	})
 }
+
+func TestPolicy_CheckExec(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		p := &Policy{ExecAllowedCommands: []string{"cat"}}
+		err := p.CheckExec([]string{"cat", "/etc/hosts"})
+		if err == nil || !strings.Contains(err.Error(), "allow-exec") {
+			t.Errorf("expected allow-exec error, got %v", err)
+		}
+	})
+
+	t.Run("allowlist by basename", func(t *testing.T) {
+		p := &Policy{AllowExec: true, ExecAllowedCommands: []string{"cat", "ls"}}
+		if err := p.CheckExec([]string{"/bin/cat", "/etc/hosts"}); err != nil {
+			t.Errorf("/bin/cat should be allowed: %v", err)
+		}
+		if err := p.CheckExec([]string{"sh", "-c", "cat /etc/hosts"}); err == nil {
+			t.Error("sh should not be allowed")
+		}
+		if err := p.CheckExec(nil); err == nil {
+			t.Error("empty command should not be allowed")
+		}
+	})
+
+	t.Run("wrappers", func(t *testing.T) {
+		p := &Policy{AllowExec: true, ExecAllowedCommands: []string{"cat", "env", "sh"}}
+		if err := p.CheckExec([]string{"env", "sh", "-c", "id"}); err == nil {
+			t.Error("env must not run a command the allowlist refuses")
+		}
+		if err := p.CheckExec([]string{"env", "-u", "HOME", "A=1", "cat", "/etc/hosts"}); err != nil {
+			t.Errorf("env running an allowed command should be allowed: %v", err)
+		}
+		if err := p.CheckExec([]string{"env", "-i", "A=1"}); err != nil {
+			t.Errorf("plain env should be allowed: %v", err)
+		}
+		if err := p.CheckExec([]string{"sh", "-c", "id"}); err == nil || !strings.Contains(err.Error(), "runs other commands") {
+			t.Errorf("listed shell should still be refused: %v", err)
+		}
+		for _, command := range [][]string{
+			{"env", "-S", "sh -c id"},
+			{"env", "-S", "sh -c id", "cat"},
+			{"env", "-iS", "sh -c id"},
+			{"env", "--split-string", "sh -c id", "cat"},
+			{"env", "--split-string=sh -c id", "cat"},
+			{"env", "--split=sh -c id"},
+		} {
+			if err := p.CheckExec(command); err == nil || !strings.Contains(err.Error(), "split-string") {
+				t.Errorf("%q should be refused: %v", command, err)
+			}
+		}
+	})
+
+	t.Run("sensitive paths", func(t *testing.T) {
+		p := &Policy{AllowExec: true, ExecAllowedCommands: []string{"*"}}
+		for _, arg := range []string{"/proc/1/environ", "/proc/self/task/7/environ", "../proc/1/environ", "--file=/proc/1/environ", "/var/run/secrets/kubernetes.io/serviceaccount/token"} {
+			if err := p.CheckExec([]string{"cat", arg}); err == nil || !strings.Contains(err.Error(), "show-sensitive-data") {
+				t.Errorf("%s should be refused: %v", arg, err)
+			}
+		}
+		p.ShowSensitiveData = true
+		if err := p.CheckExec([]string{"cat", "/proc/1/environ"}); err != nil {
+			t.Errorf("show-sensitive-data should allow environ: %v", err)
+		}
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		p := &Policy{AllowExec: true, ExecAllowedCommands: []string{"*"}}
+		if err := p.CheckExec([]string{"sh", "-c", "true"}); err != nil {
+			t.Errorf("wildcard should allow sh: %v", err)
+		}
+	})
+
+	t.Run("independent of read-only", func(t *testing.T) {
+		p := &Policy{ReadOnly: true, AllowExec: true, ExecAllowedCommands: []string{"env"}}
+		if err := p.CheckExec([]string{"env"}); err != nil {
+			t.Errorf("env should be allowed: %v", err)
+		}
+	})
+}
+
+func TestPolicy_CheckProbePort(t *testing.T) {
+	p := &Policy{}
+	if err := p.CheckProbePort(""); err != nil {
+		t.Errorf("any port should be allowed without a list: %v", err)
+	}
+	p = &Policy{ProbeAllowedPorts: []string{"8080", "metrics"}}
+	if err := p.CheckProbePort("8080"); err != nil {
+		t.Errorf("8080 should be allowed: %v", err)
+	}
+	if err := p.CheckProbePort("Metrics"); err != nil {
+		t.Errorf("metrics should be allowed (case insensitive): %v", err)
+	}
+	if err := p.CheckProbePort("22"); err == nil {
+		t.Error("22 should not be allowed")
+	}
+	if err := p.CheckProbePort(""); err == nil {
+		t.Error("empty port should not be allowed when a list is set")
+	}
+}
+
+func TestPolicy_CheckBulk(t *testing.T) {
+	if err := (&Policy{}).CheckBulk(1); err == nil {
+		t.Error("bulk operations should be disabled when MaxBulkObjects is 0")
+	}
+	p := &Policy{MaxBulkObjects: 10}
+	if err := p.CheckBulk(10); err != nil {
+		t.Errorf("10 objects should be allowed: %v", err)
+	}
+	if err := p.CheckBulk(11); err == nil {
+		t.Error("11 objects should exceed the limit")
+	}
+}
@@ -0,0 +1,101 @@
+package rancher
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// consoleProtocol is the KubeVirt serial console subprotocol: raw bytes in binary frames.
+const consoleProtocol = "plain.kubevirt.io"
+
+// ConsoleOptions for a serial console capture.
+type ConsoleOptions struct {
+	Duration  time.Duration // How long to read; required
+	MaxBytes  int           // Keep only the last MaxBytes bytes; 0 = unlimited
+	SendEnter bool          // Send a carriage return after connecting to provoke a prompt
+}
+
+// ConsoleResult is the captured serial console output.
+type ConsoleResult struct {
+	Output    []byte
+	Bytes     int  // Total bytes received
+	Truncated bool // Output holds only the last MaxBytes bytes
+}
+
+// SerialConsole connects to the serial console of a running VirtualMachineInstance through the Rancher proxy
+// (subresources.kubevirt.io console over WebSocket) and captures its output for opts.Duration.
+func (c *SteveClient) SerialConsole(ctx context.Context, clusterID, namespace, vmi string, opts ConsoleOptions) (*ConsoleResult, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/k8s/clusters/%s/apis/subresources.kubevirt.io/v1/namespaces/%s/virtualmachineinstances/%s/console", c.baseURL, clusterID, namespace, vmi))
+	if err != nil {
+		return nil, fmt.Errorf("console url: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: c.insecure},
+		Subprotocols:     []string{consoleProtocol},
+		HandshakeTimeout: 30 * time.Second,
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.token)
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("console dial %s: %w", resp.Status, err)
+		}
+		return nil, fmt.Errorf("console dial: %w", err)
+	}
+	defer conn.Close()
+
+	if opts.SendEnter {
+		if err := conn.WriteMessage(websocket.BinaryMessage, []byte("\r")); err != nil {
+			return nil, fmt.Errorf("console write: %w", err)
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	res := &ConsoleResult{}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var netErr net.Error
+			if ctx.Err() == nil && !(errors.As(err, &netErr) && netErr.Timeout()) && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return res, fmt.Errorf("console read: %w", err)
+			}
+			break
+		}
+		res.Bytes += len(data)
+		res.Output = append(res.Output, data...)
+		if opts.MaxBytes > 0 && len(res.Output) > opts.MaxBytes {
+			res.Output = res.Output[len(res.Output)-opts.MaxBytes:]
+			res.Truncated = true
+		}
+	}
+	return res, nil
+}
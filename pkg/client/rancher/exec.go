@@ -0,0 +1,183 @@
+package rancher
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Kubernetes remote command channel protocols (newest first). v5 adds an explicit close signal.
+const (
+	execProtocolV5 = "v5.channel.k8s.io"
+	execProtocolV4 = "v4.channel.k8s.io"
+)
+
+// Stream channel numbers used by the channel protocols.
+const (
+	execChannelStdout = 1
+	execChannelStderr = 2
+	execChannelError  = 3
+	execChannelClose  = 255
+)
+
+// ExecOptions for a non-interactive pod exec.
+type ExecOptions struct {
+	Container string
+	Command   []string
+	Timeout   time.Duration // 0 = no timeout beyond ctx
+	MaxBytes  int           // Cap on combined stdout+stderr; 0 = unlimited
+}
+
+// ExecResult is the outcome of a pod exec.
+type ExecResult struct {
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exit_code"`
+	Error     string `json:"error,omitempty"`
+	Truncated bool   `json:"truncated"`
+	TimedOut  bool   `json:"timed_out"`
+	Protocol  string `json:"protocol"`
+}
+
+// Exec runs a command in a pod container through the Rancher proxy using the pods/exec subresource
+// over WebSocket (v5/v4 channel protocol). stdin and TTY are not attached.
+func (c *SteveClient) Exec(ctx context.Context, clusterID, namespace, pod string, opts ExecOptions) (*ExecResult, error) {
+	if len(opts.Command) == 0 {
+		return nil, fmt.Errorf("exec: command is required")
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/k8s/clusters/%s/api/v1/namespaces/%s/pods/%s/exec", c.baseURL, clusterID, namespace, pod))
+	if err != nil {
+		return nil, fmt.Errorf("exec url: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	q := url.Values{}
+	for _, arg := range opts.Command {
+		q.Add("command", arg)
+	}
+	if opts.Container != "" {
+		q.Set("container", opts.Container)
+	}
+	q.Set("stdout", "true")
+	q.Set("stderr", "true")
+	u.RawQuery = q.Encode()
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: c.insecure},
+		Subprotocols:     []string{execProtocolV5, execProtocolV4},
+		HandshakeTimeout: 30 * time.Second,
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.token)
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("exec dial %s: %w", resp.Status, err)
+		}
+		return nil, fmt.Errorf("exec dial: %w", err)
+	}
+	defer conn.Close()
+
+	res := &ExecResult{Protocol: conn.Subprotocol()}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	var stdout, stderr strings.Builder
+	total := 0
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var netErr net.Error
+			if ctx.Err() != nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+				res.TimedOut = true
+			}
+			break
+		}
+		if len(data) == 0 {
+			continue
+		}
+		channel, payload := data[0], data[1:]
+		switch channel {
+		case execChannelStdout, execChannelStderr:
+			if opts.MaxBytes > 0 && total+len(payload) > opts.MaxBytes {
+				payload = payload[:opts.MaxBytes-total]
+				res.Truncated = true
+			}
+			total += len(payload)
+			if channel == execChannelStdout {
+				stdout.Write(payload)
+			} else {
+				stderr.Write(payload)
+			}
+		case execChannelError:
+			res.ExitCode, res.Error = parseExecStatus(payload)
+		case execChannelClose:
+			// v5 close signal for a single stream; the connection closes when the command exits.
+		}
+		if res.Truncated {
+			break
+		}
+	}
+	res.Stdout = stdout.String()
+	res.Stderr = stderr.String()
+	return res, nil
+}
+
+// parseExecStatus decodes the metav1.Status sent on the error channel into an exit code and message.
+func parseExecStatus(payload []byte) (int, string) {
+	var status struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+		Details struct {
+			Causes []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"causes"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(payload, &status); err != nil {
+		return -1, strings.TrimSpace(string(payload))
+	}
+	if status.Status == "Success" {
+		return 0, ""
+	}
+	for _, cause := range status.Details.Causes {
+		if cause.Reason == "ExitCode" {
+			if code, err := strconv.Atoi(cause.Message); err == nil {
+				return code, ""
+			}
+		}
+	}
+	return -1, status.Message
+}
@@ -16,18 +16,36 @@ const (
 	// Steve VM type (KubeVirt)
 	TypeVirtualMachines         = "kubevirt.io.virtualmachines"
 	TypeVirtualMachineInstances = "kubevirt.io.virtualmachineinstances"
+	TypeVirtualMachineInstanceMigrations = "kubevirt.io.virtualmachineinstancemigrations"
 	// Harvester CRDs
 	TypeVirtualMachineImages   = "harvesterhci.io.virtualmachineimages"
 	TypeVirtualMachineBackups  = "harvesterhci.io.v1beta1.virtualmachinebackups"
 	TypeVirtualMachineRestores = "harvesterhci.io.v1beta1.virtualmachinerestores"
 	TypeAddons                 = "harvesterhci.io.v1beta1.addons"
 	TypeSettings               = "harvesterhci.io.v1beta1.settings"
+	TypeKeyPairs               = "harvesterhci.io.v1beta1.keypairs"
+	TypeVMTemplates            = "harvesterhci.io.v1beta1.virtualmachinetemplates"
+	TypeVMTemplateVersions     = "harvesterhci.io.v1beta1.virtualmachinetemplateversions"
 	// KubeOVN CRDs (when kubeovn-operator addon is enabled)
 	TypeVpcs   = "kubeovn.io.v1.vpcs"
 	TypeSubnets = "kubeovn.io.v1.subnets"
 	// KubeVirt snapshot API (Harvester uses this for in-cluster VM snapshots, not harvesterhci.io)
 	TypeVirtualMachineSnapshots = "snapshot.kubevirt.io.v1beta1.virtualmachinesnapshots"
+	TypeVolumeSnapshots         = "snapshot.storage.k8s.io.v1.volumesnapshots"
 	TypePersistentVolumeClaims  = "v1.persistentvolumeclaims"
+	TypeSecrets                 = "v1.secrets"
+	// Longhorn (Harvester's storage backend, in the longhorn-system namespace)
+	TypeLonghornVolumes  = "longhorn.io.v1beta2.volumes"
+	TypeLonghornReplicas = "longhorn.io.v1beta2.replicas"
+	TypeLonghornNodes    = "longhorn.io.v1beta2.nodes"
+	// Harvester node-disk-manager disks (longhorn-system namespace), host networking and PCI passthrough
+	TypeBlockDevices    = "harvesterhci.io.v1beta1.blockdevices"
+	TypeClusterNetworks = "network.harvesterhci.io.v1beta1.clusternetworks"
+	TypeVlanConfigs     = "network.harvesterhci.io.v1beta1.vlanconfigs"
+	TypeVlanStatuses    = "network.harvesterhci.io.v1beta1.vlanstatuses"
+	TypeLinkMonitors    = "network.harvesterhci.io.v1beta1.linkmonitors"
+	TypePCIDevices      = "devices.harvesterhci.io.v1beta1.pcidevices"
+	TypePCIDeviceClaims = "devices.harvesterhci.io.v1beta1.pcideviceclaims"
 	// NetworkAttachmentDefinition for Harvester networks
 	TypeNetworkAttachmentDefinition = "k8s.cni.cncf.io.networkattachmentdefinitions"
 	// Rancher management (use clusterID = "local")
@@ -91,11 +109,26 @@ type TypeMeta struct {
 }
 
 type ObjectMeta struct {
-	Name            string            `json:"name"`
-	Namespace       string            `json:"namespace"`
-	ResourceVersion string            `json:"resourceVersion,omitempty"`
-	Annotations     map[string]string `json:"annotations,omitempty"`
-	Labels          map[string]string `json:"labels,omitempty"`
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid,omitempty"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty"`
+	Generation        int64             `json:"generation,omitempty"`
+	CreationTimestamp string            `json:"creationTimestamp,omitempty"`
+	DeletionTimestamp string            `json:"deletionTimestamp,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	OwnerReferences   []OwnerReference  `json:"ownerReferences,omitempty"`
+	Finalizers        []string          `json:"finalizers,omitempty"`
+}
+
+// OwnerReference identifies the owner (controller) of an object.
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller *bool  `json:"controller,omitempty"`
 }
 
 // ListOpts for list requests.
@@ -143,6 +176,7 @@ type k8sAPIPath struct {
 var steveTypeToK8sAPIPathMap = map[string]*k8sAPIPath{
 	TypeVirtualMachines:             {group: "kubevirt.io", version: "v1", resource: "virtualmachines"},
 	TypeVirtualMachineInstances:     {group: "kubevirt.io", version: "v1", resource: "virtualmachineinstances"},
+	TypeVirtualMachineInstanceMigrations: {group: "kubevirt.io", version: "v1", resource: "virtualmachineinstancemigrations"},
 	TypeVirtualMachineImages:        {group: "harvesterhci.io", version: "v1beta1", resource: "virtualmachineimages"},
 	TypeVirtualMachineSnapshots:     {group: "snapshot.kubevirt.io", version: "v1beta1", resource: "virtualmachinesnapshots"},
 	TypeVirtualMachineBackups:       {group: "harvesterhci.io", version: "v1beta1", resource: "virtualmachinebackups"},
@@ -890,12 +924,45 @@ func (c *SteveClient) updateK8sNativeByPath(ctx context.Context, clusterID strin
 	}, nil
 }
 
+// Propagation policies for DeleteOptions.
+const (
+	PropagationForeground = "Foreground"
+	PropagationBackground = "Background"
+	PropagationOrphan     = "Orphan"
+)
+
+// DeleteOptions are optional Kubernetes delete options. Zero values use the server defaults.
+type DeleteOptions struct {
+	PropagationPolicy  string // Foreground, Background or Orphan
+	GracePeriodSeconds *int64
+}
+
+// query encodes the options as Kubernetes delete query parameters (accepted by Steve and the native API).
+func (o DeleteOptions) query() string {
+	q := url.Values{}
+	if o.PropagationPolicy != "" {
+		q.Set("propagationPolicy", o.PropagationPolicy)
+	}
+	if o.GracePeriodSeconds != nil {
+		q.Set("gracePeriodSeconds", fmt.Sprintf("%d", *o.GracePeriodSeconds))
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
 // Delete a resource. namespace empty for cluster-scoped.
 // For native-first types (e.g. snapshot) tries native API first; otherwise tries Steve first, then native on 404.
 func (c *SteveClient) Delete(ctx context.Context, clusterID, resourceType, namespace, name string) error {
+	return c.DeleteWithOptions(ctx, clusterID, resourceType, namespace, name, DeleteOptions{})
+}
+
+// DeleteWithOptions deletes a resource with a propagation policy and/or grace period.
+func (c *SteveClient) DeleteWithOptions(ctx context.Context, clusterID, resourceType, namespace, name string, opts DeleteOptions) error {
 	if steveTypeNativeFirst(resourceType) {
 		if path := steveTypeToK8sAPIPath(resourceType); path != nil {
-			err := c.deleteK8sNativeByPath(ctx, clusterID, path, namespace, name)
+			err := c.deleteK8sNativeByPath(ctx, clusterID, path, namespace, name, opts)
 			if err == nil {
 				return nil
 			}
@@ -904,23 +971,23 @@ func (c *SteveClient) Delete(ctx context.Context, clusterID, resourceType, names
 			}
 		}
 	}
-	err := c.delete(ctx, clusterID, resourceType, namespace, name)
+	err := c.delete(ctx, clusterID, resourceType, namespace, name, opts)
 	if err != nil && strings.Contains(err.Error(), "404") {
 		if path := steveTypeToK8sAPIPath(resourceType); path != nil {
-			return c.deleteK8sNativeByPath(ctx, clusterID, path, namespace, name)
+			return c.deleteK8sNativeByPath(ctx, clusterID, path, namespace, name, opts)
 		}
 	}
 	return err
 }
 
-func (c *SteveClient) delete(ctx context.Context, clusterID, resourceType, namespace, name string) error {
+func (c *SteveClient) delete(ctx context.Context, clusterID, resourceType, namespace, name string, opts DeleteOptions) error {
 	var path string
 	if namespace != "" {
 		path = fmt.Sprintf("/k8s/clusters/%s/v1/namespaces/%s/%s/%s", clusterID, namespace, resourceType, name)
 	} else {
 		path = fmt.Sprintf("/k8s/clusters/%s/v1/%s/%s", clusterID, resourceType, name)
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+path, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+path+opts.query(), nil)
 	if err != nil {
 		return err
 	}
@@ -930,7 +997,7 @@ func (c *SteveClient) delete(ctx context.Context, clusterID, resourceType, names
 		return fmt.Errorf("steve delete request: %w", err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("steve delete %s: %s", resp.Status, string(body))
 	}
@@ -938,14 +1005,14 @@ func (c *SteveClient) delete(ctx context.Context, clusterID, resourceType, names
 }
 
 // deleteK8sNativeByPath deletes a resource via the native Kubernetes API.
-func (c *SteveClient) deleteK8sNativeByPath(ctx context.Context, clusterID string, path *k8sAPIPath, namespace, name string) error {
+func (c *SteveClient) deleteK8sNativeByPath(ctx context.Context, clusterID string, path *k8sAPIPath, namespace, name string, opts DeleteOptions) error {
 	var urlPath string
 	if namespace != "" {
 		urlPath = fmt.Sprintf("/k8s/clusters/%s%s/namespaces/%s/%s/%s", clusterID, path.basePath(), namespace, path.resource, name)
 	} else {
 		urlPath = fmt.Sprintf("/k8s/clusters/%s%s/%s/%s", clusterID, path.basePath(), path.resource, name)
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+urlPath, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+urlPath+opts.query(), nil)
 	if err != nil {
 		return err
 	}
@@ -955,7 +1022,7 @@ func (c *SteveClient) deleteK8sNativeByPath(ctx context.Context, clusterID strin
 		return fmt.Errorf("k8s delete request: %w", err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("k8s delete %s: %s", resp.Status, string(body))
 	}
@@ -974,7 +1041,12 @@ func SteveType(apiVersion, kind string) string {
 	case "ingress":
 		k = "ingresses"
 	default:
-		if !strings.HasSuffix(k, "s") {
+		switch {
+		case strings.HasSuffix(k, "class"):
+			k += "es" // storageclasses, ingressclasses, priorityclasses
+		case len(k) > 1 && strings.HasSuffix(k, "y") && !strings.ContainsRune("aeiou", rune(k[len(k)-2])):
+			k = strings.TrimSuffix(k, "y") + "ies" // networkpolicies
+		case !strings.HasSuffix(k, "s"):
 			k += "s"
 		}
 	}
@@ -7,6 +7,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 func TestSteveType(t *testing.T) {
@@ -24,6 +27,9 @@ func TestSteveType(t *testing.T) {
 		{"v1", "Event", "core.v1.events"},
 		{"v1", "Ingress", "core.v1.ingresses"},
 		{"networking.k8s.io/v1", "Ingress", "networking.k8s.io.v1.ingresses"},
+		{"networking.k8s.io/v1", "NetworkPolicy", "networking.k8s.io.v1.networkpolicies"},
+		{"storage.k8s.io/v1", "StorageClass", "storage.k8s.io.v1.storageclasses"},
+		{"gateway.networking.k8s.io/v1", "Gateway", "gateway.networking.k8s.io.v1.gateways"},
 	}
 	for _, tt := range tests {
 		got := SteveType(tt.apiVersion, tt.kind)
@@ -227,3 +233,127 @@ func TestSteveClient_Delete(t *testing.T) {
 		t.Fatalf("Delete: %v", err)
 	}
 }
+
+func TestSteveClient_DeleteWithOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/v1/namespaces/default/apps.v1.deployments/web" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("propagationPolicy") != "Foreground" || q.Get("gracePeriodSeconds") != "0" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	client := NewSteveClient(srv.URL, "token", true)
+	grace := int64(0)
+	err := client.DeleteWithOptions(context.Background(), "c-xxx", "apps.v1.deployments", "default", "web", DeleteOptions{PropagationPolicy: PropagationForeground, GracePeriodSeconds: &grace})
+	if err != nil {
+		t.Fatalf("DeleteWithOptions: %v", err)
+	}
+}
+
+func TestSteveClient_ProxyGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/api/v1/namespaces/default/services/https:web:8443/proxy/metrics" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Write([]byte("up 1"))
+	}))
+	defer srv.Close()
+
+	client := NewSteveClient(srv.URL, "token", true)
+	resp, err := client.ProxyGet(context.Background(), "c-xxx", "default", "services", "https", "web", "8443", "/metrics", nil, 0)
+	if err != nil || string(resp.Body) != "up 1" {
+		t.Fatalf("ProxyGet: resp=%v err=%v", resp, err)
+	}
+	for _, tc := range []struct{ name, port, path string }{
+		{"web", "8443", "/../../secrets"},
+		{"web", "8443", "/a/%2e%2e/%2E%2E/secrets"},
+		{"web", "8443", "/%252e%252e/secrets"},
+		{"web", "8443", "/a\\..\\..\\secrets"},
+		{"web/../../secrets/x", "8443", "/"},
+		{"web", "8443/../../secrets", "/"},
+		{"..", "", "/"},
+	} {
+		if _, err := client.ProxyGet(context.Background(), "c-xxx", "default", "services", "http", tc.name, tc.port, tc.path, nil, 0); err == nil {
+			t.Errorf("ProxyGet(name=%q port=%q path=%q): expected error", tc.name, tc.port, tc.path)
+		}
+	}
+}
+
+func TestSteveClient_Exec(t *testing.T) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"v4.channel.k8s.io"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/api/v1/namespaces/default/pods/web/exec" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.URL.Query()["command"]; strings.Join(got, " ") != "ls -la /data" {
+			t.Errorf("command = %v", got)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{1}, "total 0\n"...))
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{2}, "ls: cannot access\n"...))
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{3}, `{"status":"Failure","reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"2"}]}}`...))
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer srv.Close()
+
+	client := NewSteveClient(srv.URL, "token", true)
+	res, err := client.Exec(context.Background(), "c-xxx", "default", "web", ExecOptions{Command: []string{"ls", "-la", "/data"}, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if res.Protocol != "v4.channel.k8s.io" || res.ExitCode != 2 || res.Stdout != "total 0\n" || res.Stderr != "ls: cannot access\n" || res.TimedOut {
+		t.Errorf("result = %+v", res)
+	}
+
+	res, err = client.Exec(context.Background(), "c-xxx", "default", "web", ExecOptions{Command: []string{"ls", "-la", "/data"}, MaxBytes: 4})
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if !res.Truncated || res.Stdout != "tota" {
+		t.Errorf("truncated result = %+v", res)
+	}
+}
+
+func TestSteveClient_SerialConsole(t *testing.T) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"plain.kubevirt.io"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/apis/subresources.kubevirt.io/v1/namespaces/default/virtualmachineinstances/vm1/console" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		if _, in, err := conn.ReadMessage(); err != nil || string(in) != "\r" {
+			t.Errorf("expected Enter from the client, got %q (%v)", in, err)
+		}
+		conn.WriteMessage(websocket.BinaryMessage, []byte("Booting from Hard Disk...\r\n"))
+		conn.WriteMessage(websocket.BinaryMessage, []byte("login: "))
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer srv.Close()
+
+	client := NewSteveClient(srv.URL, "token", true)
+	res, err := client.SerialConsole(context.Background(), "c-xxx", "default", "vm1", ConsoleOptions{Duration: 5 * time.Second, MaxBytes: 12, SendEnter: true})
+	if err != nil {
+		t.Fatalf("SerialConsole: %v", err)
+	}
+	if string(res.Output) != "...\r\nlogin: " || res.Bytes != 34 || !res.Truncated {
+		t.Errorf("result = %q bytes=%d truncated=%v", res.Output, res.Bytes, res.Truncated)
+	}
+}
@@ -0,0 +1,164 @@
+package rancher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Content types accepted by the Kubernetes API for PATCH requests.
+const (
+	PatchTypeMerge     = "application/merge-patch+json"
+	PatchTypeJSON      = "application/json-patch+json"
+	PatchTypeStrategic = "application/strategic-merge-patch+json"
+	PatchTypeApply     = "application/apply-patch+yaml"
+)
+
+// ResourcePath returns the native Kubernetes API path for a Steve resource type
+// (e.g. "apps.v1.deployments", "default", "web" -> /apis/apps/v1/namespaces/default/deployments/web).
+// name may be empty for the collection path.
+func ResourcePath(resourceType, namespace, name string) (string, error) {
+	p := steveTypeToK8sAPIPath(resourceType)
+	if p == nil {
+		return "", fmt.Errorf("cannot resolve API path for %q", resourceType)
+	}
+	out := p.basePath()
+	if namespace != "" {
+		out += "/namespaces/" + namespace
+	}
+	out += "/" + p.resource
+	if name != "" {
+		out += "/" + name
+	}
+	return out, nil
+}
+
+// K8sRequest issues a request against the native Kubernetes API of a cluster through the Rancher proxy
+// (/k8s/clusters/<id><apiPath>). apiPath starts with /api/ or /apis/; use it for subresources
+// (scale, proxy, eviction) and calls that SteveResource cannot represent. contentType defaults to
+// application/json when body is set. Non-2xx statuses are returned, not treated as errors.
+func (c *SteveClient) K8sRequest(ctx context.Context, clusterID, method, apiPath string, query url.Values, body []byte, contentType string) ([]byte, int, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/k8s/clusters/%s%s", c.baseURL, clusterID, apiPath))
+	if err != nil {
+		return nil, 0, fmt.Errorf("k8s request url: %w", err)
+	}
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), rdr)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("k8s request: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("k8s read body: %w", err)
+	}
+	return b, resp.StatusCode, nil
+}
+
+// ProxyResponse is a bounded response from the pods/services proxy subresource.
+type ProxyResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Truncated  bool
+}
+
+// ProxyGet issues a GET through the proxy subresource of a Service or Pod
+// (/api/v1/namespaces/<ns>/<services|pods>/[<scheme>:]<name>:<port>/proxy/<path>). At most maxBytes of the
+// body are read (0 = unlimited); Truncated reports whether more was available.
+func (c *SteveClient) ProxyGet(ctx context.Context, clusterID, namespace, resource, scheme, name, port, path string, query url.Values, maxBytes int64) (*ProxyResponse, error) {
+	if resource != "services" && resource != "pods" {
+		return nil, fmt.Errorf("proxy: unsupported resource %q (use services or pods)", resource)
+	}
+	if err := checkProxyTarget(namespace, name, port, path); err != nil {
+		return nil, err
+	}
+	target := name
+	if scheme != "" && scheme != "http" {
+		target = scheme + ":" + target
+	}
+	if port != "" {
+		target += ":" + port
+	}
+	apiPath := fmt.Sprintf("/api/v1/namespaces/%s/%s/%s/proxy/%s", namespace, resource, target, strings.TrimPrefix(path, "/"))
+	u, err := url.Parse(fmt.Sprintf("%s/k8s/clusters/%s%s", c.baseURL, clusterID, apiPath))
+	if err != nil {
+		return nil, fmt.Errorf("proxy url: %w", err)
+	}
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("proxy request: %w", err)
+	}
+	defer resp.Body.Close()
+	var rdr io.Reader = resp.Body
+	if maxBytes > 0 {
+		rdr = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	b, err := io.ReadAll(rdr)
+	if err != nil {
+		return nil, fmt.Errorf("proxy read body: %w", err)
+	}
+	out := &ProxyResponse{StatusCode: resp.StatusCode, Header: resp.Header}
+	if maxBytes > 0 && int64(len(b)) > maxBytes {
+		b = b[:maxBytes]
+		out.Truncated = true
+	}
+	out.Body = b
+	return out, nil
+}
+
+// checkProxyTarget rejects proxy targets that would leave the intended Service or Pod: namespace, name and
+// port are single path segments, and path may not climb out of the proxy with ".." (also when escaped).
+func checkProxyTarget(namespace, name, port, path string) error {
+	for _, v := range []struct{ what, value string }{{"namespace", namespace}, {"name", name}, {"port", port}} {
+		if strings.ContainsAny(v.value, "/\\?#%") || v.value == "." || v.value == ".." {
+			return fmt.Errorf("proxy: invalid %s %q", v.what, v.value)
+		}
+	}
+	// Unescape until stable so that %2e%2e and double-escaped forms are caught too.
+	for unescaped := path; ; {
+		for _, seg := range strings.FieldsFunc(unescaped, func(r rune) bool { return r == '/' || r == '\\' }) {
+			if seg == ".." {
+				return fmt.Errorf("proxy: path %q may not contain \"..\" segments", path)
+			}
+		}
+		next, err := url.PathUnescape(unescaped)
+		if err != nil {
+			return fmt.Errorf("proxy: invalid path %q: %v", path, err)
+		}
+		if next == unescaped {
+			return nil
+		}
+		unescaped = next
+	}
+}
@@ -0,0 +1,134 @@
+package rancher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Watch event types as sent by the Kubernetes watch API.
+const (
+	WatchEventAdded    = "ADDED"
+	WatchEventModified = "MODIFIED"
+	WatchEventDeleted  = "DELETED"
+	WatchEventBookmark = "BOOKMARK"
+	WatchEventError    = "ERROR"
+)
+
+// WatchOpts for watch requests.
+type WatchOpts struct {
+	Namespace       string
+	LabelSelector   string
+	FieldSelector   string
+	ResourceVersion string
+	TimeoutSeconds  int
+}
+
+// WatchEvent is a single event from a Kubernetes watch stream.
+type WatchEvent struct {
+	Type   string
+	Object SteveResource
+}
+
+// Watch streams changes for a resource type through the Rancher proxy using the native Kubernetes
+// watch API (?watch=true). fn is called for each event; returning done=true or an error stops the watch.
+// Watch returns nil when fn is done or the server closes the stream (e.g. after TimeoutSeconds);
+// callers that need to keep waiting should re-watch from the last seen resourceVersion.
+func (c *SteveClient) Watch(ctx context.Context, clusterID, resourceType string, opts WatchOpts, fn func(WatchEvent) (bool, error)) error {
+	path := steveTypeToK8sAPIPath(resourceType)
+	if path == nil {
+		return fmt.Errorf("watch: cannot resolve API path for %q", resourceType)
+	}
+	var urlPath string
+	if opts.Namespace != "" {
+		urlPath = fmt.Sprintf("/k8s/clusters/%s%s/namespaces/%s/%s", clusterID, path.basePath(), opts.Namespace, path.resource)
+	} else {
+		urlPath = fmt.Sprintf("/k8s/clusters/%s%s/%s", clusterID, path.basePath(), path.resource)
+	}
+	u, err := url.Parse(c.baseURL + urlPath)
+	if err != nil {
+		return fmt.Errorf("k8s watch url: %w", err)
+	}
+	q := u.Query()
+	q.Set("watch", "true")
+	q.Set("allowWatchBookmarks", "true")
+	if opts.LabelSelector != "" {
+		q.Set("labelSelector", opts.LabelSelector)
+	}
+	if opts.FieldSelector != "" {
+		q.Set("fieldSelector", opts.FieldSelector)
+	}
+	if opts.ResourceVersion != "" {
+		q.Set("resourceVersion", opts.ResourceVersion)
+	}
+	if opts.TimeoutSeconds > 0 {
+		q.Set("timeoutSeconds", fmt.Sprintf("%d", opts.TimeoutSeconds))
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("k8s watch request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("k8s watch %s: %s", resp.Status, string(body))
+	}
+
+	// The watch stream is a sequence of JSON objects {"type": ..., "object": {...}}.
+	dec := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var raw struct {
+			Type   string          `json:"type"`
+			Object json.RawMessage `json:"object"`
+		}
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("k8s watch decode: %w", err)
+		}
+		if raw.Type == WatchEventError {
+			var status struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			}
+			_ = json.Unmarshal(raw.Object, &status)
+			return fmt.Errorf("k8s watch error %d: %s", status.Code, status.Message)
+		}
+		var item struct {
+			APIVersion string      `json:"apiVersion"`
+			Kind       string      `json:"kind"`
+			Metadata   ObjectMeta  `json:"metadata"`
+			Spec       interface{} `json:"spec,omitempty"`
+			Status     interface{} `json:"status,omitempty"`
+		}
+		if err := json.Unmarshal(raw.Object, &item); err != nil {
+			continue
+		}
+		done, err := fn(WatchEvent{
+			Type: raw.Type,
+			Object: SteveResource{
+				TypeMeta:   TypeMeta{Kind: item.Kind, APIVersion: item.APIVersion},
+				ObjectMeta: item.Metadata,
+				Spec:       item.Spec,
+				Status:     item.Status,
+			},
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
 )
 
 func (t *Toolset) gitrepoCreateTool() mcp.Tool {
@@ -19,6 +21,8 @@ func (t *Toolset) gitrepoCreateTool() mcp.Tool {
 		mcp.WithString("branch", mcp.Description("Branch to track (default: main)")),
 		mcp.WithString("paths", mcp.Description("Comma-separated paths in repo (e.g. path1,path2)")),
 		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+		mcp.WithBoolean("wait", mcp.Description("Wait until the GitRepo reports Ready=True (default: false)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Max seconds to wait when wait=true (default: 300)")),
 	)
 }
 
@@ -73,6 +77,19 @@ func (t *Toolset) gitrepoCreateHandler(ctx context.Context, req mcp.CallToolRequ
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("fleet_gitrepo_create: %v", err)), nil
 	}
+	if req.GetBool("wait", false) {
+		cond, _ := wait.ParseCondition("condition=Ready")
+		timeout := time.Duration(req.GetInt("timeout_seconds", 300)) * time.Second
+		if timeout <= 0 {
+			timeout = 300 * time.Second
+		}
+		target := wait.Target{Cluster: localCluster, ResourceType: rancher.TypeFleetGitRepos, Namespace: namespace, Name: name}
+		waited, err := wait.Until(ctx, t.client, target, cond, timeout, wait.Progress(ctx, req, timeout))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("GitRepo %q created but: %v", name, err)), nil
+		}
+		res = waited.Object
+	}
 	data := map[string]interface{}{
 		"metadata": res.ObjectMeta,
 		"spec":     res.Spec,
@@ -0,0 +1,239 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+)
+
+var blockDeviceActions = map[string]bool{"provision": true, "unprovision": true, "set_tags": true}
+
+func (t *Toolset) blockDeviceListTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_blockdevice_list",
+		mcp.WithDescription("List the disks node-disk-manager found on Harvester hosts (BlockDevices) and whether each can be added to Longhorn, with the reasons it cannot (mounted, inactive, partition of a provisioned disk) and whether existing data must be force-formatted"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("host", mcp.Description("Only disks of this host (default: all hosts)")),
+		mcp.WithString("show", mcp.Description("unprovisioned, provisioned or all (default: unprovisioned)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) blockDeviceProvisionTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_blockdevice_provision",
+		mcp.WithDescription("Add a BlockDevice to Longhorn as a disk (provision), remove it (unprovision) or change its Longhorn disk tags (set_tags), as the Harvester host disk page does. Refuses disks that are mounted or inactive, and unprovisioning disks that still hold Longhorn replicas. force_format wipes existing partitions and filesystems and requires destructive operations to be allowed."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("BlockDevice name (see harvester_blockdevice_list)")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action: provision, unprovision, set_tags")),
+		mcp.WithBoolean("force_format", mcp.Description("provision: wipe the disk's existing partitions and filesystem first (default: false)")),
+		mcp.WithString("tags", mcp.Description("provision/set_tags: comma-separated Longhorn disk tags, e.g. ssd,fast (set_tags with empty tags clears them)")),
+	)
+}
+
+func (t *Toolset) blockDeviceListHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	host := req.GetString("host", "")
+	show := req.GetString("show", "unprovisioned")
+	if show != "unprovisioned" && show != "provisioned" && show != "all" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid show %q; allowed: unprovisioned, provisioned, all", show)), nil
+	}
+	format := req.GetString("format", "json")
+
+	devices, err := t.hostBlockDevices(ctx, cluster, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	replicas := map[string]int{}
+	if show != "unprovisioned" {
+		path, _ := rancher.ResourcePath(rancher.TypeLonghornNodes, longhornNamespace, "")
+		if nodes, err := t.listObjects(ctx, cluster, path, nil, "Longhorn nodes"); err == nil {
+			for _, n := range nodes {
+				for disk, st := range nestedMap(n, "status", "diskStatus") {
+					m, _ := st.(map[string]interface{})
+					replicas[disk] = len(nestedMap(m, "scheduledReplica"))
+				}
+			}
+		}
+	}
+	items := make([]map[string]interface{}, 0, len(devices))
+	for _, bd := range devices {
+		node := nestedMap(bd, "spec")["nodeName"]
+		if host != "" && node != host {
+			continue
+		}
+		provisioned := blockDeviceProvisioned(bd)
+		if (show == "unprovisioned" && provisioned) || (show == "provisioned" && !provisioned) {
+			continue
+		}
+		item := blockDeviceSummary(bd)
+		item["host"] = node
+		if provisioned {
+			item["longhorn_replicas"] = replicas[fmt.Sprint(item["name"])]
+		} else {
+			reasons, needsFormat := provisionBlockers(bd, devices)
+			item["can_provision"] = len(reasons) == 0
+			item["needs_force_format"] = needsFormat
+			if len(reasons) > 0 {
+				item["blockers"] = strings.Join(reasons, "; ")
+			}
+		}
+		items = append(items, item)
+	}
+	out, err := formatter.FormatListWithContinue(t.formatter, items, "", format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+func (t *Toolset) blockDeviceProvisionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := req.GetString("cluster", "")
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	action, err := req.RequireString("action")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !blockDeviceActions[action] {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid action %q; allowed: provision, unprovision, set_tags", action)), nil
+	}
+	forceFormat := req.GetBool("force_format", false)
+	if forceFormat {
+		if err := t.policy.CheckDestructive(); err != nil {
+			return mcp.NewToolResultError("force_format wipes the disk: " + err.Error()), nil
+		}
+	}
+	tags := splitList(req.GetString("tags", ""))
+
+	devices, err := t.hostBlockDevices(ctx, cluster, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var bd map[string]interface{}
+	for _, d := range devices {
+		if nestedMap(d, "metadata")["name"] == name {
+			bd = d
+		}
+	}
+	if bd == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("BlockDevice %q not found in namespace %s (see harvester_blockdevice_list)", name, longhornNamespace)), nil
+	}
+	spec := nestedMap(bd, "spec")
+	node, _ := spec["nodeName"].(string)
+	device := fmt.Sprintf("%v on host %s", spec["devPath"], node)
+	// node-disk-manager before v0.5 only knows spec.fileSystem.provisioned; keep it in step when present.
+	_, legacy := nestedMap(spec, "fileSystem")["provisioned"]
+
+	patchSpec := map[string]interface{}{}
+	var msg string
+	switch action {
+	case "provision":
+		reasons, needsFormat := provisionBlockers(bd, devices)
+		if len(reasons) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("BlockDevice %q (%s) cannot be provisioned: %s", name, device, strings.Join(reasons, "; "))), nil
+		}
+		if needsFormat && !forceFormat {
+			return mcp.NewToolResultError(fmt.Sprintf("BlockDevice %q (%s) has existing partitions or a filesystem; set force_format=true to wipe it", name, device)), nil
+		}
+		fs := map[string]interface{}{"forceFormatted": forceFormat}
+		if legacy {
+			fs["provisioned"] = true
+		}
+		patchSpec["provision"] = true
+		patchSpec["fileSystem"] = fs
+		if len(tags) > 0 {
+			patchSpec["tags"] = tags
+		}
+		msg = fmt.Sprintf("BlockDevice %q (%s) is being provisioned: node-disk-manager formats and mounts it and adds it to Longhorn as disk %q; provision_phase becomes Provisioned", name, device, name)
+	case "unprovision":
+		if !blockDeviceProvisioned(bd) {
+			return mcp.NewToolResultError(fmt.Sprintf("BlockDevice %q (%s) is not provisioned", name, device)), nil
+		}
+		replicas, err := t.longhornDiskReplicas(ctx, cluster, node, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("cannot check Longhorn disk %q for replicas: %v", name, err)), nil
+		}
+		if len(replicas) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Longhorn disk %q (%s) still holds %d replica(s): %s; disable scheduling on the disk and evict its replicas in Longhorn first", name, device, len(replicas), strings.Join(replicas, ", "))), nil
+		}
+		patchSpec["provision"] = false
+		if legacy {
+			patchSpec["fileSystem"] = map[string]interface{}{"provisioned": false}
+		}
+		msg = fmt.Sprintf("BlockDevice %q (%s) is being unprovisioned: node-disk-manager removes it from Longhorn and unmounts it; the data on it is kept", name, device)
+	default: // set_tags
+		patchSpec["tags"] = tags
+		msg = fmt.Sprintf("Tags of BlockDevice %q (%s) set to %s", name, device, listOrNone(tags))
+		if !blockDeviceProvisioned(bd) {
+			msg += "; they apply to the Longhorn disk once it is provisioned"
+		}
+	}
+	if err := t.mergePatch(ctx, cluster, rancher.TypeBlockDevices, longhornNamespace, name, map[string]interface{}{"spec": patchSpec}); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("harvester_blockdevice_provision %s: %v", action, err)), nil
+	}
+	return mcp.NewToolResultText(msg), nil
+}
+
+// provisionBlockers returns why a BlockDevice cannot become a Longhorn disk, and whether it holds partitions
+// or a filesystem that must be force-formatted. devices are all BlockDevices, to find provisioned parents
+// and partitions of the same disk.
+func provisionBlockers(bd map[string]interface{}, devices []map[string]interface{}) ([]string, bool) {
+	spec := nestedMap(bd, "spec")
+	status := nestedMap(bd, "status")
+	device := nestedMap(status, "deviceStatus")
+	var reasons []string
+	if blockDeviceProvisioned(bd) {
+		reasons = append(reasons, "already provisioned")
+	}
+	if state := status["state"]; state != "Active" {
+		reasons = append(reasons, fmt.Sprintf("state is %v (device missing or not usable)", state))
+	}
+	if mount, _ := nestedMap(device, "fileSystem")["mountPoint"].(string); mount != "" {
+		reasons = append(reasons, "mounted at "+mount)
+	}
+	parent, _ := device["parentDevice"].(string)
+	for _, other := range devices {
+		otherSpec := nestedMap(other, "spec")
+		if otherSpec["nodeName"] != spec["nodeName"] || !blockDeviceProvisioned(other) {
+			continue
+		}
+		switch {
+		case parent != "" && otherSpec["devPath"] == parent:
+			reasons = append(reasons, fmt.Sprintf("its disk %s is provisioned", parent))
+		case nestedMap(other, "status", "deviceStatus")["parentDevice"] == spec["devPath"]:
+			reasons = append(reasons, fmt.Sprintf("its partition %v is provisioned", otherSpec["devPath"]))
+		}
+	}
+	partitioned, _ := device["partitioned"].(bool)
+	fsType, _ := nestedMap(device, "fileSystem")["type"].(string)
+	return reasons, partitioned || fsType != ""
+}
+
+// longhornDiskReplicas returns the replicas Longhorn has scheduled on a disk of a node.
+func (t *Toolset) longhornDiskReplicas(ctx context.Context, cluster, node, disk string) ([]string, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeLonghornNodes, longhornNamespace, node)
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("Longhorn node %q: %d %s", node, code, apiMessage(body))
+	}
+	var lhNode map[string]interface{}
+	if err := json.Unmarshal(body, &lhNode); err != nil {
+		return nil, err
+	}
+	return mapKeysExcept(nestedMap(lhNode, "status", "diskStatus", disk, "scheduledReplica"), ""), nil
+}
@@ -0,0 +1,252 @@
+package harvester
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// cloudInitTemplateLabel marks Harvester cloud-init ConfigMaps ("user" or "network" templates) and,
+	// with value "harvester", the Secrets holding a VM's cloud-init data.
+	cloudInitTemplateLabel = "harvesterhci.io/cloud-init-template"
+	// cloudInitTemplateKey is the ConfigMap data key of a CloudTemplate.
+	cloudInitTemplateKey = "cloudInit"
+	// sshNamesAnnotation lists the KeyPairs (namespace/name) injected into a VM, as the Harvester UI records them.
+	sshNamesAnnotation = "harvesterhci.io/sshNames"
+	cloudConfigHeader  = "#cloud-config"
+	cloudInitDiskName  = "cloudinitdisk"
+)
+
+// cloudInitConfig is the resolved cloud-init data of a VM.
+type cloudInitConfig struct {
+	UserData    string
+	NetworkData string
+	SSHNames    []string
+}
+
+// cloudInitOptions are the tool arguments shared by the tools that build cloud-init data.
+func cloudInitOptions() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("user_data", mcp.Description("Cloud-init user data; must start with #cloud-config")),
+		mcp.WithString("user_data_template", mcp.Description("Harvester cloud-init user template (ConfigMap name, or namespace/name) to use instead of user_data")),
+		mcp.WithString("network_data", mcp.Description("Cloud-init network config (version 1 or 2 YAML)")),
+		mcp.WithString("network_data_template", mcp.Description("Harvester cloud-init network template (ConfigMap name, or namespace/name) to use instead of network_data")),
+		mcp.WithString("ssh_keys", mcp.Description("Comma-separated Harvester KeyPair names (or namespace/name) added to ssh_authorized_keys")),
+	}
+}
+
+// resolveCloudInit builds the cloud-init data from the request arguments, reading CloudTemplate ConfigMaps
+// and KeyPairs from namespace. It returns nil when no cloud-init arguments are set.
+func (t *Toolset) resolveCloudInit(ctx context.Context, cluster, namespace string, req mcp.CallToolRequest) (*cloudInitConfig, error) {
+	userData, err := t.cloudInitSource(ctx, cluster, namespace, req.GetString("user_data", ""), req.GetString("user_data_template", ""), "user")
+	if err != nil {
+		return nil, err
+	}
+	networkData, err := t.cloudInitSource(ctx, cluster, namespace, req.GetString("network_data", ""), req.GetString("network_data_template", ""), "network")
+	if err != nil {
+		return nil, err
+	}
+	var keys, names []string
+	for _, ref := range strings.Split(req.GetString("ssh_keys", ""), ",") {
+		if ref = strings.TrimSpace(ref); ref == "" {
+			continue
+		}
+		ns, name := splitRef(namespace, ref)
+		if err := t.policy.CheckNamespace(ns); err != nil {
+			return nil, err
+		}
+		kp, err := t.client.Get(ctx, cluster, rancher.TypeKeyPairs, ns, name)
+		if err != nil {
+			return nil, fmt.Errorf("KeyPair %s/%s not found: %v", ns, name, err)
+		}
+		spec, _ := kp.Spec.(map[string]interface{})
+		key, _ := spec["publicKey"].(string)
+		if key == "" {
+			return nil, fmt.Errorf("KeyPair %s/%s has no publicKey", ns, name)
+		}
+		keys = append(keys, strings.TrimSpace(key))
+		names = append(names, ns+"/"+name)
+	}
+	if userData == "" && networkData == "" && len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) > 0 {
+		if userData, err = addSSHKeys(userData, keys); err != nil {
+			return nil, err
+		}
+	}
+	if userData == "" {
+		// KubeVirt requires user data whenever a cloudInitNoCloud volume is attached.
+		userData = cloudConfigHeader + "\n"
+	}
+	if err := validateCloudConfig(userData); err != nil {
+		return nil, err
+	}
+	if networkData != "" {
+		if err := validateNetworkData(networkData); err != nil {
+			return nil, err
+		}
+	}
+	return &cloudInitConfig{UserData: userData, NetworkData: networkData, SSHNames: names}, nil
+}
+
+// cloudInitSource returns raw, or the content of the CloudTemplate ConfigMap named by template.
+func (t *Toolset) cloudInitSource(ctx context.Context, cluster, namespace, raw, template, templateType string) (string, error) {
+	if template == "" {
+		return raw, nil
+	}
+	if raw != "" {
+		return "", fmt.Errorf("set %s_data or %s_data_template, not both", templateType, templateType)
+	}
+	ns, name := splitRef(namespace, template)
+	if err := t.policy.CheckNamespace(ns); err != nil {
+		return "", err
+	}
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, fmt.Sprintf("/api/v1/namespaces/%s/configmaps/%s", ns, name), nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("cloud-init template %s/%s: %v", ns, name, err)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("cloud-init template %s/%s: %d %s", ns, name, status, string(body))
+	}
+	var cm struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &cm); err != nil {
+		return "", fmt.Errorf("cloud-init template %s/%s: %v", ns, name, err)
+	}
+	if kind := cm.Metadata.Labels[cloudInitTemplateLabel]; kind != templateType {
+		return "", fmt.Errorf("ConfigMap %s/%s is not a %s cloud-init template (%s=%q)", ns, name, templateType, cloudInitTemplateLabel, kind)
+	}
+	data, ok := cm.Data[cloudInitTemplateKey]
+	if !ok {
+		return "", fmt.Errorf("cloud-init template %s/%s has no %q key", ns, name, cloudInitTemplateKey)
+	}
+	return data, nil
+}
+
+// addSSHKeys appends keys to the ssh_authorized_keys of a #cloud-config document, creating one if empty.
+// The document is re-serialised, so comments other than the header are not preserved.
+func addSSHKeys(userData string, keys []string) (string, error) {
+	config := map[string]interface{}{}
+	if strings.TrimSpace(userData) != "" {
+		if err := validateCloudConfig(userData); err != nil {
+			return "", err
+		}
+		if err := yaml.Unmarshal([]byte(userData), &config); err != nil {
+			return "", fmt.Errorf("user data: %v", err)
+		}
+	}
+	existing, _ := config["ssh_authorized_keys"].([]interface{})
+	for _, k := range keys {
+		if !slices.Contains(existing, interface{}(k)) {
+			existing = append(existing, k)
+		}
+	}
+	config["ssh_authorized_keys"] = existing
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("user data: %v", err)
+	}
+	return cloudConfigHeader + "\n" + string(out), nil
+}
+
+// validateCloudConfig checks that user data starts with the #cloud-config header and is a YAML mapping.
+func validateCloudConfig(userData string) error {
+	first, _, _ := strings.Cut(userData, "\n")
+	if strings.TrimSpace(first) != cloudConfigHeader {
+		return fmt.Errorf("user data must start with a %q line", cloudConfigHeader)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal([]byte(userData), &config); err != nil {
+		return fmt.Errorf("user data is not valid cloud-config YAML: %v", err)
+	}
+	return nil
+}
+
+// validateNetworkData checks that network data is a cloud-init network config (version 1 or 2).
+func validateNetworkData(networkData string) error {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal([]byte(networkData), &config); err != nil {
+		return fmt.Errorf("network data is not valid YAML: %v", err)
+	}
+	if v, ok := config["network"].(map[string]interface{}); ok {
+		config = v
+	}
+	switch fmt.Sprint(config["version"]) {
+	case "1", "2":
+		return nil
+	}
+	return fmt.Errorf("network data must be a cloud-init network config with version 1 or 2")
+}
+
+// cloudInitSecret returns the Secret holding a VM's cloud-init data, labelled as the Harvester UI does.
+func cloudInitSecret(name, namespace string, ci *cloudInitConfig) map[string]interface{} {
+	data := map[string]string{"userdata": base64.StdEncoding.EncodeToString([]byte(ci.UserData))}
+	if ci.NetworkData != "" {
+		data["networkdata"] = base64.StdEncoding.EncodeToString([]byte(ci.NetworkData))
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]string{cloudInitTemplateLabel: "harvester"},
+		},
+		"type": "Opaque",
+		"data": data,
+	}
+}
+
+// cloudInitVolume returns the cloudInitNoCloud volume referencing secretName.
+func cloudInitVolume(secretName string, ci *cloudInitConfig) map[string]interface{} {
+	source := map[string]interface{}{"secretRef": map[string]interface{}{"name": secretName}}
+	if ci.NetworkData != "" {
+		source["networkDataSecretRef"] = map[string]interface{}{"name": secretName}
+	}
+	return map[string]interface{}{"name": cloudInitDiskName, "cloudInitNoCloud": source}
+}
+
+// setSecretOwner makes owner (a VM or template version) own its cloud-init Secret so the Secret is removed with it.
+func (t *Toolset) setSecretOwner(ctx context.Context, cluster, namespace, secretName, apiVersion, kind string, owner *rancher.SteveResource) error {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"ownerReferences": []map[string]interface{}{{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"name":       owner.ObjectMeta.Name,
+				"uid":        owner.ObjectMeta.UID,
+			}},
+		},
+	})
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, secretName), nil, patch, rancher.PatchTypeMerge)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("patch %d: %s", status, string(body))
+	}
+	return nil
+}
+
+// splitRef splits "namespace/name", defaulting the namespace.
+func splitRef(namespace, ref string) (string, string) {
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		return ns, name
+	}
+	return namespace, ref
+}
@@ -2,11 +2,16 @@ package harvester
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/internal/security"
@@ -107,3 +112,919 @@ func TestVMGetHandler_Success(t *testing.T) {
 		t.Errorf("output should contain VM name: %s", tc.Text)
 	}
 }
+
+func TestVMCreateHandler_CloudInit(t *testing.T) {
+	var secret map[string]interface{}
+	var vm map[string]interface{}
+	var ownerPatch string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineimages/ubuntu"):
+			json.NewEncoder(w).Encode(rancher.SteveResource{Status: map[string]interface{}{"storageClassName": "longhorn-ubuntu"}})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/keypairs/ops"):
+			json.NewEncoder(w).Encode(rancher.SteveResource{Spec: map[string]interface{}{"publicKey": "ssh-ed25519 AAAA ops@example"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/k8s/clusters/c-xxx/api/v1/namespaces/default/configmaps/net":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]string{"harvesterhci.io/cloud-init-template": "network"}},
+				"data":     map[string]string{"cloudInit": "version: 2\nethernets:\n  enp1s0:\n    dhcp4: true\n"},
+			})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/persistentvolumeclaims"):
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/secrets"):
+			json.NewDecoder(r.Body).Decode(&secret)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/virtualmachines"):
+			json.NewDecoder(r.Body).Decode(&vm)
+			w.Write([]byte(`{"metadata":{"name":"web","namespace":"default","uid":"vm-uid"}}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/k8s/clusters/c-xxx/api/v1/namespaces/default/secrets/web-cloudinit":
+			b, _ := io.ReadAll(r.Body)
+			ownerPatch = string(b)
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "web", "image": "ubuntu", "user_data": "packages: [nginx]"}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "harvester_vm_create", Arguments: args}}
+	result, _ := toolset.vmCreateHandler(context.Background(), req)
+	if !result.IsError || secret != nil {
+		t.Fatalf("expected user data without #cloud-config to be rejected before creating anything: %v", result.Content)
+	}
+
+	args["user_data"] = "#cloud-config\npackages: [nginx]\n"
+	args["network_data_template"] = "net"
+	args["ssh_keys"] = "ops"
+	result, err := toolset.vmCreateHandler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("vmCreateHandler: err=%v result=%v", err, result.Content)
+	}
+	data, _ := secret["data"].(map[string]interface{})
+	userData, _ := base64.StdEncoding.DecodeString(data["userdata"].(string))
+	if !strings.HasPrefix(string(userData), "#cloud-config\n") || !strings.Contains(string(userData), "ssh-ed25519 AAAA ops@example") || !strings.Contains(string(userData), "nginx") {
+		t.Errorf("userdata = %s", userData)
+	}
+	if data["networkdata"] == nil {
+		t.Error("secret has no networkdata")
+	}
+	vmJSON, _ := json.Marshal(vm)
+	for _, want := range []string{`"cloudInitNoCloud":{"networkDataSecretRef":{"name":"web-cloudinit"},"secretRef":{"name":"web-cloudinit"}}`, `"name":"cloudinitdisk"`, `"harvesterhci.io/sshNames":"[\"default/ops\"]"`} {
+		if !strings.Contains(string(vmJSON), want) {
+			t.Errorf("VM spec missing %s: %s", want, vmJSON)
+		}
+	}
+	if !strings.Contains(ownerPatch, `"uid":"vm-uid"`) {
+		t.Errorf("owner patch = %s", ownerPatch)
+	}
+}
+
+func TestVMCreateHandler_DisksAndNICs(t *testing.T) {
+	var claims, deleted []string
+	var vm map[string]interface{}
+	failClaim := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineimages/ubuntu"):
+			json.NewEncoder(w).Encode(rancher.SteveResource{Status: map[string]interface{}{"storageClassName": "longhorn-ubuntu"}})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineimages/ubuntu-iso"):
+			json.NewEncoder(w).Encode(rancher.SteveResource{Status: map[string]interface{}{"storageClassName": "longhorn-iso", "size": 2.5 * (1 << 30)}})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/persistentvolumeclaims"):
+			var pvc struct {
+				Metadata rancher.ObjectMeta `json:"metadata"`
+				Spec     struct {
+					StorageClassName string `json:"storageClassName"`
+					Resources        struct {
+						Requests map[string]string `json:"requests"`
+					} `json:"resources"`
+				} `json:"spec"`
+			}
+			json.NewDecoder(r.Body).Decode(&pvc)
+			if pvc.Metadata.Name == failClaim {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			claims = append(claims, pvc.Metadata.Name+":"+pvc.Spec.StorageClassName+":"+pvc.Spec.Resources.Requests["storage"])
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/virtualmachines"):
+			json.NewDecoder(r.Body).Decode(&vm)
+			w.Write([]byte(`{"metadata":{"name":"db"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{
+		"cluster": "c-xxx", "namespace": "default", "name": "db", "image": "ubuntu", "cpu": float64(2), "cpu_sockets": float64(2),
+		"disks":    `[{"size_gib":100},{"type":"cdrom","image":"ubuntu-iso"}]`,
+		"networks": `[{"network":"default"},{"network":"vlan100","interface_type":"bridge","mac":"52:54:00:12:34:56"}]`,
+		"efi":      true, "secure_boot": true, "tpm": true, "node_affinity": "zone=a", "reserved_memory": "256Mi",
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "harvester_vm_create", Arguments: args}}
+	result, err := toolset.vmCreateHandler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("vmCreateHandler: err=%v result=%v", err, result.Content)
+	}
+	want := []string{"db-disk-0:longhorn-ubuntu:20Gi", "db-disk-1:longhorn:100Gi", "db-cdrom-1:longhorn-iso:3Gi"}
+	if strings.Join(claims, " ") != strings.Join(want, " ") {
+		t.Errorf("claims = %v, want %v", claims, want)
+	}
+	vmJSON, _ := json.Marshal(vm)
+	for _, s := range []string{
+		`"cdrom":{"bus":"sata"}`, `"name":"disk-1"`,
+		`"macAddress":"52:54:00:12:34:56"`, `"multus":{"networkName":"default/vlan100"}`, `"pod":{}`,
+		`"cpu":{"cores":2,"sockets":2,"threads":1}`, `"limits":{"cpu":"4","memory":"4Gi"}`,
+		`"efi":{"secureBoot":true}`, `"smm":{"enabled":true}`, `"tpm":{}`,
+		`"hostname":"db"`, `"values":["a"]`, `"harvesterhci.io/reservedMemory":"256Mi"`,
+	} {
+		if !strings.Contains(string(vmJSON), s) {
+			t.Errorf("VM spec missing %s: %s", s, vmJSON)
+		}
+	}
+	if strings.Contains(string(vmJSON), `"requests"`) {
+		t.Errorf("VM spec should leave requests to Harvester: %s", vmJSON)
+	}
+
+	claims, failClaim = nil, "db-cdrom-1"
+	result, _ = toolset.vmCreateHandler(context.Background(), req)
+	if !result.IsError || strings.Join(deleted, " ") != "db-disk-0 db-disk-1" {
+		t.Errorf("expected created volumes to be rolled back, deleted=%v result=%v", deleted, result.Content)
+	}
+
+	args["disks"] = `[{"type":"cdrom","bus":"virtio","image":"ubuntu-iso"}]`
+	if result, _ = toolset.vmCreateHandler(context.Background(), req); !result.IsError {
+		t.Error("expected a virtio CD-ROM to be rejected")
+	}
+
+	toolset.policy.DeniedNamespaces = []string{"restricted"}
+	claims = nil
+	args["disks"] = `[{"image":"restricted/win-drivers","size_gib":10}]`
+	if result, _ = toolset.vmCreateHandler(context.Background(), req); !result.IsError || len(claims) > 0 || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "denied by security policy") {
+		t.Errorf("expected an image in a denied namespace to be rejected: claims=%v result=%v", claims, result.Content)
+	}
+}
+
+func TestVMTemplateVersionCreateHandler_RollsBackNewTemplate(t *testing.T) {
+	var created, deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachines/web"):
+			w.Write([]byte(`{"metadata":{"name":"web","namespace":"default"},"spec":{"template":{"spec":{"domain":{"devices":{}},"volumes":[]}}}}`))
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "virtualmachinetemplates"):
+			created = append(created, "template")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"metadata":{"name":"web-tmpl","namespace":"default"}}`))
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "virtualmachinetemplateversions"):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"kind":"Status","message":"admission webhook denied the request"}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "vm": "web", "template": "web-tmpl"}
+	result, err := toolset.vmTemplateVersionCreateHandler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected the version create to fail: err=%v result=%v", err, result)
+	}
+	if len(created) != 1 || strings.Join(deleted, " ") != "web-tmpl" {
+		t.Errorf("created = %v, deleted = %v; want the new template deleted again", created, deleted)
+	}
+}
+
+func TestVMCreateFromTemplateHandler(t *testing.T) {
+	var vm map[string]interface{}
+	var secrets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "virtualmachinetemplates/web"):
+			w.Write([]byte(`{"metadata":{"name":"web","namespace":"default"},"spec":{"defaultVersionId":"default/web-aaaaa"}}`))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "virtualmachinetemplateversions"):
+			w.Write([]byte(`{"data":[
+				{"metadata":{"name":"web-bbbbb","namespace":"default"},"spec":{"templateId":"default/web"},"status":{"version":2}},
+				{"metadata":{"name":"other-ccccc","namespace":"default"},"spec":{"templateId":"default/other"},"status":{"version":1}},
+				{"metadata":{"name":"web-aaaaa","namespace":"default"},"spec":{"templateId":"default/web","keyPairIds":["default/ops"],"vm":{
+					"metadata":{"annotations":{"harvesterhci.io/volumeClaimTemplates":"[{\"metadata\":{\"name\":\"tmpl-disk-0\",\"annotations\":{\"harvesterhci.io/imageId\":\"default/ubuntu\"}},\"spec\":{\"resources\":{\"requests\":{\"storage\":\"20Gi\"}}}}]"}},
+					"spec":{"runStrategy":"RerunOnFailure","template":{"spec":{
+						"domain":{"cpu":{"cores":1},"resources":{"limits":{"cpu":"1","memory":"2Gi"},"requests":{"memory":"1Gi"}},"devices":{"disks":[{"name":"disk-0"},{"name":"cloudinitdisk"}]}},
+						"volumes":[{"name":"disk-0","persistentVolumeClaim":{"claimName":"tmpl-disk-0"}},{"name":"cloudinitdisk","cloudInitNoCloud":{"secretRef":{"name":"web-aaaaa-cloudinit"}}}]}}}}},"status":{"version":1}}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/secrets/web-aaaaa-cloudinit"):
+			userData := base64.StdEncoding.EncodeToString([]byte("#cloud-config\npackages: [nginx]\n"))
+			w.Write([]byte(`{"data":{"userdata":"` + userData + `"}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/secrets"):
+			var s rancher.SteveResource
+			json.NewDecoder(r.Body).Decode(&s)
+			secrets = append(secrets, s.ObjectMeta.Name)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/virtualmachines"):
+			json.NewDecoder(r.Body).Decode(&vm)
+			w.Write([]byte(`{"metadata":{"name":"web-1","uid":"u1"}}`))
+		case r.Method == http.MethodPatch:
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "web-1", "template": "web", "cpu": float64(4), "memory": "8Gi"}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "harvester_vm_create_from_template", Arguments: args}}
+	result, err := toolset.vmCreateFromTemplateHandler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("vmCreateFromTemplateHandler: err=%v result=%v", err, result.Content)
+	}
+	if len(secrets) != 1 || secrets[0] != "web-1-cloudinit" {
+		t.Errorf("secrets = %v, want the template's cloud-init copied to web-1-cloudinit", secrets)
+	}
+	vmJSON, _ := json.Marshal(vm)
+	for _, s := range []string{
+		`"claimName":"web-1-disk-0"`, `\"name\":\"web-1-disk-0\"`, `"secretRef":{"name":"web-1-cloudinit"}`,
+		`"cores":4`, `"limits":{"cpu":"4","memory":"8Gi"}`, `"harvesterhci.io/vmName":"web-1"`,
+		`"runStrategy":"RerunOnFailure"`, `"harvesterhci.io/sshNames":"[\"default/ops\"]"`,
+	} {
+		if !strings.Contains(string(vmJSON), s) {
+			t.Errorf("VM missing %s: %s", s, vmJSON)
+		}
+	}
+	if strings.Contains(string(vmJSON), `"requests"`) || strings.Contains(string(vmJSON), "tmpl-disk-0") {
+		t.Errorf("VM should drop requests and template claim names: %s", vmJSON)
+	}
+
+	args["version"] = "3"
+	if result, _ = toolset.vmCreateFromTemplateHandler(context.Background(), req); !result.IsError {
+		t.Error("expected an unknown version to be rejected")
+	}
+}
+
+func TestVMUpdateHandler(t *testing.T) {
+	var put map[string]interface{}
+	var claims []string
+	var rootPatch string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/apis/kubevirt.io/v1/namespaces/default/virtualmachines/db"):
+			w.Write([]byte(`{"apiVersion":"kubevirt.io/v1","kind":"VirtualMachine","metadata":{"name":"db","resourceVersion":"7","labels":{"stale":"x"}},
+				"spec":{"runStrategy":"RerunOnFailure","template":{"spec":{
+					"domain":{"cpu":{"cores":1,"sockets":1,"threads":1,"maxSockets":4},"memory":{"guest":"1948Mi"},"resources":{"limits":{"cpu":"1","memory":"2Gi"},"requests":{"memory":"1Gi"}},
+						"devices":{"disks":[{"name":"disk-0","disk":{"bus":"virtio"},"bootOrder":1},{"name":"disk-1","disk":{"bus":"virtio"}}],"interfaces":[{"name":"default","masquerade":{}}]}},
+					"networks":[{"name":"default","pod":{}}],
+					"volumes":[{"name":"disk-0","persistentVolumeClaim":{"claimName":"db-disk-0"}},{"name":"disk-1","persistentVolumeClaim":{"claimName":"db-disk-1"}}]}}},
+				"status":{"printableStatus":"Running","conditions":[{"type":"RestartRequired","status":"True"}]}}`))
+		case r.Method == http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&put)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "persistentvolumeclaims/db-disk-0"):
+			w.Write([]byte(`{"metadata":{"name":"db-disk-0"},"spec":{"resources":{"requests":{"storage":"20Gi"}}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/persistentvolumeclaims"):
+			var pvc rancher.SteveResource
+			json.NewDecoder(r.Body).Decode(&pvc)
+			claims = append(claims, pvc.ObjectMeta.Name)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/persistentvolumeclaims/db-disk-0"):
+			b, _ := io.ReadAll(r.Body)
+			rootPatch = string(b)
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{
+		"cluster": "c-xxx", "namespace": "default", "name": "db", "cpu_sockets": float64(2), "memory": "4Gi",
+		"labels": "tier=db,stale-", "description": "primary", "remove_disks": "disk-1", "add_disks": `[{"size_gib":10}]`,
+		"add_networks": `[{"network":"vlan100"}]`, "root_volume_size": "40Gi",
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "harvester_vm_update", Arguments: args}}
+	result, err := toolset.vmUpdateHandler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("vmUpdateHandler: err=%v result=%v", err, result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, s := range []string{"cpu sockets 1 -> 2", "memory 2Gi -> 4Gi", "Detached volumes kept: db-disk-1", "RestartRequired condition is set"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("result missing %q: %s", s, text)
+		}
+	}
+	if len(claims) != 1 || claims[0] != "db-disk-2" {
+		t.Errorf("claims = %v, want [db-disk-2]", claims)
+	}
+	if !strings.Contains(rootPatch, `"storage":"40Gi"`) {
+		t.Errorf("root volume patch = %s", rootPatch)
+	}
+	vmJSON, _ := json.Marshal(put)
+	for _, s := range []string{
+		`"resourceVersion":"7"`, `"sockets":2`, `"limits":{"cpu":"2","memory":"4Gi"}`, `"labels":{"tier":"db"}`,
+		`"field.cattle.io/description":"primary"`, `"claimName":"db-disk-2"`, `"multus":{"networkName":"default/vlan100"}`,
+	} {
+		if !strings.Contains(string(vmJSON), s) {
+			t.Errorf("updated VM missing %s: %s", s, vmJSON)
+		}
+	}
+	for _, s := range []string{`"db-disk-1"`, `"name":"disk-1"`, `"guest"`, `"requests"`, `"status"`} {
+		if strings.Contains(string(vmJSON), s) {
+			t.Errorf("updated VM should not contain %s: %s", s, vmJSON)
+		}
+	}
+
+	args = map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "db", "root_volume_size": "10Gi"}
+	req.Params.Arguments = args
+	if result, _ = toolset.vmUpdateHandler(context.Background(), req); !result.IsError {
+		t.Error("expected shrinking the root volume to be rejected")
+	}
+
+	put = nil
+	args = map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "db", "remove_disks": "disk-0"}
+	req.Params.Arguments = args
+	if result, _ = toolset.vmUpdateHandler(context.Background(), req); !result.IsError || put != nil {
+		t.Errorf("expected removing the boot disk to be refused: %v", result.Content)
+	}
+	args["remove_boot_disk"] = true
+	if result, _ = toolset.vmUpdateHandler(context.Background(), req); result.IsError || put == nil {
+		t.Errorf("remove_boot_disk=true: %v", result.Content)
+	}
+}
+
+func TestVMDeleteHandler(t *testing.T) {
+	var deleted []string
+	var marked string
+	failVolumeSnapshots := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachines/db"):
+			w.Write([]byte(`{"metadata":{"name":"db"},"spec":{"template":{"spec":{"volumes":[
+				{"name":"disk-0","persistentVolumeClaim":{"claimName":"db-disk-0"}},
+				{"name":"disk-1","persistentVolumeClaim":{"claimName":"db-disk-1"}},
+				{"name":"cloudinitdisk","cloudInitNoCloud":{"secretRef":{"name":"db-cloudinit"}}}]}}}}`))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "virtualmachinebackups"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"nightly"},"spec":{"type":"backup","source":{"name":"db"}}},
+				{"metadata":{"name":"before-upgrade"},"spec":{"type":"snapshot","source":{"name":"db"}}},
+				{"metadata":{"name":"other"},"spec":{"type":"snapshot","source":{"name":"web"}}}]}`))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "volumesnapshots"):
+			if failVolumeSnapshots {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data":[{"metadata":{"name":"before-upgrade-disk-1"},"spec":{"source":{"persistentVolumeClaimName":"db-disk-1"}}}]}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"items":[]}`))
+		case r.Method == http.MethodPatch:
+			b, _ := io.ReadAll(r.Body)
+			marked = string(b)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "db", "remove_volumes": "all"}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "harvester_vm_delete", Arguments: args}}
+	result, err := toolset.vmDeleteHandler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("vmDeleteHandler: err=%v result=%v", err, result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(marked, `"harvesterhci.io/removedPersistentVolumeClaims":"db-disk-0"`) {
+		t.Errorf("volumes marked for removal = %s, want only db-disk-0 (db-disk-1 has a snapshot)", marked)
+	}
+	for _, s := range []string{"db-disk-1 (snapshots: before-upgrade-disk-1)", "VM snapshots kept: before-upgrade", "backup target are kept and can restore the VM: nightly"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("result missing %q: %s", s, text)
+		}
+	}
+	if strings.Join(deleted, " ") != "db" {
+		t.Errorf("deleted = %v, want only the VM", deleted)
+	}
+
+	deleted, marked = nil, ""
+	args["snapshots"] = "delete"
+	if result, _ = toolset.vmDeleteHandler(context.Background(), req); result.IsError {
+		t.Fatalf("vmDeleteHandler snapshots=delete: %v", result.Content)
+	}
+	if strings.Join(deleted, " ") != "before-upgrade before-upgrade-disk-1 db" || !strings.Contains(marked, "db-disk-0,db-disk-1") {
+		t.Errorf("deleted = %v, marked = %s", deleted, marked)
+	}
+
+	deleted, marked = nil, ""
+	args["dry_run"], args["remove_volumes"] = true, "disk-1"
+	if result, _ = toolset.vmDeleteHandler(context.Background(), req); result.IsError || len(deleted) > 0 || marked != "" {
+		t.Errorf("dry run changed something: deleted=%v marked=%s result=%v", deleted, marked, result.Content)
+	}
+
+	failVolumeSnapshots = true
+	args["dry_run"], args["remove_volumes"] = false, "all"
+	if result, _ = toolset.vmDeleteHandler(context.Background(), req); !result.IsError || len(deleted) > 0 || marked != "" {
+		t.Errorf("expected a failed volume snapshot list to refuse the delete: deleted=%v marked=%s result=%v", deleted, marked, result.Content)
+	}
+}
+
+func TestVMGuestInfoHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/virtualmachineinstances/vm1"):
+			w.Write([]byte(`{"status":{"phase":"Running","nodeName":"node-1","conditions":[{"type":"AgentConnected","status":"True"}],
+				"interfaces":[{"name":"default","interfaceName":"enp1s0","mac":"52:54:00:aa:bb:cc","ipAddresses":["10.0.0.5","fe80::1"],"infoSource":"domain, guest-agent"}]}}`))
+		case strings.HasSuffix(r.URL.Path, "/vm1/guestosinfo"):
+			w.Write([]byte(`{"name":"Ubuntu","versionId":"22.04"}`))
+		case strings.HasSuffix(r.URL.Path, "/vm1/filesystemlist"):
+			w.Write([]byte(`{"items":[{"mountPoint":"/","usedBytes":1024,"totalBytes":4096}]}`))
+		case strings.HasSuffix(r.URL.Path, "/vm1/userlist"):
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"kind":"Status","message":"guest agent timeout"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "vm1"}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "harvester_vm_guest_info", Arguments: args}}
+	result, err := toolset.vmGuestInfoHandler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("vmGuestInfoHandler: err=%v result=%v", err, result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, s := range []string{`"versionId": "22.04"`, `"mountPoint": "/"`, `"10.0.0.5"`, `"agent_connected": true`, `"error": "503 guest agent timeout"`} {
+		if !strings.Contains(text, s) {
+			t.Errorf("result missing %s: %s", s, text)
+		}
+	}
+
+	args["name"] = "stopped"
+	if result, _ = toolset.vmGuestInfoHandler(context.Background(), req); !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "no running instance") {
+		t.Errorf("expected a stopped VM to be reported, got %v", result.Content)
+	}
+}
+
+func TestCleanConsole(t *testing.T) {
+	got := cleanConsole([]byte("\x1b[2J\x1b[1;1HGRUB loading.\r\n\x1b]0;title\x07error: no such device\r\n\xe2\x94"))
+	if want := "GRUB loading.\nerror: no such device\n"; got != want {
+		t.Errorf("cleanConsole = %q, want %q", got, want)
+	}
+}
+
+func TestVMMigrateHandler(t *testing.T) {
+	migrationPollInterval = time.Millisecond
+	var created map[string]interface{}
+	var deleted []string
+	phase := "Running"
+	vmi := func(name, node string, extra string) string {
+		return `{"metadata":{"name":"` + name + `","namespace":"default"},"spec":{` + extra + `},"status":{"phase":"Running","nodeName":"` + node + `",
+			"conditions":[{"type":"LiveMigratable","status":"True"}],"migrationState":{"migrationUid":"m-uid","sourceNode":"node-1","targetNode":"node-2","startTimestamp":"2026-01-01T00:00:00Z"}}}`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineinstances/web"):
+			w.Write([]byte(vmi("web", "node-1", `"volumes":[{"name":"disk-0","persistentVolumeClaim":{"claimName":"web-disk-0"}}]`)))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineinstances"):
+			w.Write([]byte(`{"items":[` + vmi("web", "node-1", `"volumes":[{"name":"disk-0","persistentVolumeClaim":{"claimName":"web-disk-0"}}]`) + `,` +
+				vmi("gpu", "node-1", `"domain":{"devices":{"hostDevices":[{"name":"nic1"}],"disks":[{"name":"iso","cdrom":{"bus":"sata"}}]}},"volumes":[{"name":"disk-0","persistentVolumeClaim":{"claimName":"gpu-disk-0"}}]`) + `]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "persistentvolumeclaims/web-disk-0"):
+			w.Write([]byte(`{"metadata":{"name":"web-disk-0"},"spec":{"accessModes":["ReadWriteMany"]}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "persistentvolumeclaims"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"web-disk-0","namespace":"default"},"spec":{"accessModes":["ReadWriteMany"]}},
+				{"metadata":{"name":"gpu-disk-0","namespace":"default"},"spec":{"accessModes":["ReadWriteOnce"]}}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/nodes/node-2"):
+			w.Write([]byte(`{"metadata":{"name":"node-2"},"spec":{},"status":{"conditions":[{"type":"Ready","status":"True"}]}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/nodes/node-3"):
+			w.Write([]byte(`{"metadata":{"name":"node-3"},"spec":{"unschedulable":true}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/virtualmachineinstancemigrations"):
+			json.NewDecoder(r.Body).Decode(&created)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/virtualmachineinstancemigrations/"):
+			name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			w.Write([]byte(`{"metadata":{"name":"` + name + `","uid":"m-uid"},"spec":{"vmiName":"web"},"status":{"phase":"` + phase + `"}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineinstancemigrations"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"web-old","creationTimestamp":"2026-01-01T00:00:00Z"},"spec":{"vmiName":"web"},"status":{"phase":"` + phase + `"}}]}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}) (string, bool) {
+		args["cluster"], args["namespace"] = "c-xxx", "default"
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	if text, isErr := call(toolset.vmMigrateHandler, map[string]interface{}{"name": "web", "action": "start", "target_node": "node-2"}); !isErr || !strings.Contains(text, `"web-old" in progress`) {
+		t.Errorf("expected a running migration to block a new one: %s", text)
+	}
+	phase = "Succeeded"
+	if text, isErr := call(toolset.vmMigrateHandler, map[string]interface{}{"name": "web", "action": "start", "target_node": "node-3"}); !isErr || !strings.Contains(text, "cordoned") {
+		t.Errorf("expected a cordoned target to be rejected: %s", text)
+	}
+	text, isErr := call(toolset.vmMigrateHandler, map[string]interface{}{"name": "web", "action": "start", "target_node": "node-2", "wait": true})
+	if isErr || !strings.Contains(text, `"phase": "Succeeded"`) || !strings.Contains(text, `"source_node": "node-1"`) {
+		t.Errorf("start with wait: %s", text)
+	}
+	createdJSON, _ := json.Marshal(created)
+	if !strings.Contains(string(createdJSON), `"harvesterhci.io/migrationTargetNodeName":"node-2"`) || !strings.Contains(string(createdJSON), `"vmiName":"web"`) {
+		t.Errorf("created migration = %s", createdJSON)
+	}
+
+	phase = "Running"
+	toolset.policy.ReadOnly = true
+	for _, action := range []string{"start", "cancel"} {
+		if text, isErr := call(toolset.vmMigrateHandler, map[string]interface{}{"name": "web", "action": action}); !isErr || !strings.Contains(text, "read-only") {
+			t.Errorf("expected read-only to block %s: %s", action, text)
+		}
+	}
+	if text, isErr := call(toolset.vmMigrationStatusHandler, map[string]interface{}{"name": "web", "migration": "web-old"}); isErr || !strings.Contains(text, `"phase": "Running"`) {
+		t.Errorf("read-only status: %s", text)
+	}
+	if text, isErr := call(toolset.vmMigrateHandler, map[string]interface{}{"name": "web", "action": "status", "migration": "web-old"}); isErr || !strings.Contains(text, `"phase": "Running"`) {
+		t.Errorf("read-only action=status: %s", text)
+	}
+	toolset.policy.ReadOnly = false
+	if text, isErr := call(toolset.vmMigrateHandler, map[string]interface{}{"name": "web", "action": "cancel"}); isErr || strings.Join(deleted, " ") != "web-old" {
+		t.Errorf("cancel: deleted=%v %s", deleted, text)
+	}
+
+	text, _ = call(toolset.vmMigratableHandler, map[string]interface{}{})
+	var items []map[string]interface{}
+	json.Unmarshal([]byte(text), &items)
+	if len(items) != 2 || items[0]["name"] != "gpu" || items[0]["migratable"] != false || items[1]["migratable"] != true {
+		t.Fatalf("migratable = %s", text)
+	}
+	for _, s := range []string{"host device", `CD-ROM \"iso\"`, `\"gpu-disk-0\" is not ReadWriteMany`} {
+		if !strings.Contains(text, s) {
+			t.Errorf("reasons missing %s: %s", s, text)
+		}
+	}
+}
+
+func TestVMActionHandler_RestartWait(t *testing.T) {
+	vmiPollInterval = time.Millisecond
+	restarted, vmiFails := false, false
+	var after []string // VMI states served after the restart, in order; the last one repeats
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Query().Get("action") == "restart":
+			restarted = true
+			after = []string{"old Running", "", "new Scheduling", "new Running"}
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineinstances/web"):
+			if vmiFails {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			state := "old Running"
+			if restarted {
+				state = after[0]
+				if len(after) > 1 {
+					after = after[1:]
+				}
+			}
+			if state == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			uid, phase, _ := strings.Cut(state, " ")
+			w.Write([]byte(`{"metadata":{"name":"web","uid":"` + uid + `"},"status":{"phase":"` + phase + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "web", "action": "restart", "wait": true, "timeout_seconds": float64(5)}
+	result, err := toolset.vmActionHandler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+	if err != nil || result.IsError {
+		t.Fatalf("vmActionHandler: err=%v result=%v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "new instance Running") || len(after) != 1 {
+		t.Errorf("restart returned before the new instance ran: %s (states left %v)", text, after)
+	}
+
+	restarted, vmiFails = false, true
+	if result, _ = toolset.vmActionHandler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}); !result.IsError || restarted {
+		t.Errorf("expected a failed instance lookup to stop the restart: restarted=%v result=%v", restarted, result.Content)
+	}
+}
+
+func TestHostDrainPlanHandler(t *testing.T) {
+	migrationPollInterval = time.Millisecond
+	var patches, migrations []string
+	vmi := func(name, extra string) string {
+		namespace := "default"
+		if name == "secret" {
+			namespace = "restricted"
+		}
+		return `{"metadata":{"name":"` + name + `","namespace":"` + namespace + `","ownerReferences":[{"kind":"VirtualMachine","name":"` + name + `"}]},
+			"spec":{` + extra + `"domain":{"resources":{"requests":{"cpu":"1","memory":"20Gi"}}}},"status":{"phase":"Running","nodeName":"node-1"}}`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/api/v1/nodes"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"node-1"},"spec":{},"status":{"allocatable":{"cpu":"8","memory":"32Gi"}}},
+				{"metadata":{"name":"node-2"},"spec":{},"status":{"allocatable":{"cpu":"8","memory":"16Gi"},"conditions":[{"type":"Ready","status":"True"}]}},
+				{"metadata":{"name":"node-3"},"spec":{"unschedulable":true},"status":{"allocatable":{"cpu":"8","memory":"64Gi"}}}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/api/v1/pods"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"app","namespace":"default"},"spec":{"nodeName":"node-2","containers":[{"resources":{"requests":{"cpu":"2","memory":"4Gi"}}}]}},
+				{"metadata":{"name":"virt-launcher-web-x","namespace":"default","labels":{"vm.kubevirt.io/name":"web"}},"spec":{"nodeName":"node-1",
+					"containers":[{"resources":{"requests":{"cpu":"1","memory":"2Gi"}}}],"overhead":{"memory":"256Mi"}}}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineinstances"):
+			w.Write([]byte(`{"items":[` + vmi("web", "") + `,` + vmi("big", "") + `,` + vmi("gpu", `"nodeSelector":{"kubernetes.io/hostname":"node-1"},`) + `,` + vmi("secret", "") + `]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachines/gpu"):
+			w.Write([]byte(`{"metadata":{"name":"gpu"},"spec":{"running":true}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/persistentvolumeclaims"):
+			w.Write([]byte(`{"items":[]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/longhorn-system/replicas"):
+			w.Write([]byte(`{"items":[{"spec":{"volumeName":"pvc-a","nodeID":"node-1"},"status":{"currentState":"running"}},
+				{"spec":{"volumeName":"pvc-a","nodeID":"node-2"},"status":{"currentState":"running"}},
+				{"spec":{"volumeName":"pvc-b","nodeID":"node-1"},"status":{"currentState":"running"}},
+				{"spec":{"volumeName":"pvc-b","nodeID":"node-2","failedAt":"2026-01-01T00:00:00Z"},"status":{"currentState":"stopped"}}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/longhorn-system/volumes"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"pvc-a"},"spec":{"numberOfReplicas":2},"status":{"state":"attached","kubernetesStatus":{"namespace":"default","pvcName":"web-disk-0"}}},
+				{"metadata":{"name":"pvc-b"},"spec":{"numberOfReplicas":2},"status":{"state":"attached"}}]}`))
+		case r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patches = append(patches, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]+" "+string(body))
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/virtualmachineinstancemigrations"):
+			var m map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&m)
+			migrations = append(migrations, nestedMap(m, "spec")["vmiName"].(string))
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/virtualmachineinstancemigrations"):
+			w.Write([]byte(`{"items":[]}`))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/virtualmachineinstancemigrations/"):
+			name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			w.Write([]byte(`{"metadata":{"name":"` + name + `"},"spec":{"vmiName":"` + name[:strings.LastIndex(name, "-")] + `"},"status":{"phase":"Succeeded"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{DeniedNamespaces: []string{"restricted"}})
+	call := func(args map[string]interface{}) (string, bool) {
+		args["cluster"], args["host"] = "c-xxx", "node-1"
+		result, err := toolset.hostDrainPlanHandler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(map[string]interface{}{})
+	var plan struct {
+		VMs        []map[string]interface{} `json:"vms"`
+		CapacityOK bool                     `json:"capacity_ok"`
+		OtherHosts []map[string]interface{} `json:"other_hosts"`
+		Volumes    []map[string]interface{} `json:"longhorn_volumes"`
+		Warnings   []string                 `json:"warnings"`
+		Denied     int                      `json:"denied"`
+	}
+	if err := json.Unmarshal([]byte(text), &plan); isErr || err != nil {
+		t.Fatalf("plan: %v %s", err, text)
+	}
+	actions := map[string]string{}
+	for _, vm := range plan.VMs {
+		actions[vm["name"].(string)] = fmt.Sprint(vm["action"], " ", vm["fits_on"])
+	}
+	if len(actions) != 3 || actions["web"] != "migrate node-2" || actions["big"] != "migrate <nil>" || actions["gpu"] != "shutdown <nil>" {
+		t.Errorf("actions = %v", actions)
+	}
+	if plan.CapacityOK || len(plan.OtherHosts) != 1 || plan.OtherHosts[0]["free_memory_after"] != "9.8Gi" || plan.Denied != 1 {
+		t.Errorf("capacity: ok=%v hosts=%v", plan.CapacityOK, plan.OtherHosts)
+	}
+	if len(plan.Volumes) != 2 || plan.Volumes[0]["pvc"] != "default/web-disk-0" || plan.Volumes[0]["healthy_elsewhere"] != float64(1) || plan.Volumes[1]["healthy_elsewhere"] != float64(0) {
+		t.Errorf("volumes = %v", plan.Volumes)
+	}
+	if !strings.Contains(strings.Join(plan.Warnings, "\n"), "node-3 is cordoned") || !strings.Contains(strings.Join(plan.Warnings, "\n"), "only healthy replicas are on this host: pvc-b") {
+		t.Errorf("warnings = %v", plan.Warnings)
+	}
+
+	if text, isErr := call(map[string]interface{}{"execute": true, "shutdown_non_migratable": true, "force": true}); !isErr || !strings.Contains(text, "restricted/secret") {
+		t.Errorf("expected VMs in denied namespaces to block execute: %s", text)
+	}
+	toolset.policy.DeniedNamespaces = nil
+	if text, isErr := call(map[string]interface{}{"execute": true}); !isErr || !strings.Contains(text, "default/gpu") {
+		t.Errorf("expected non-migratable VMs to block execute: %s", text)
+	}
+	if text, isErr := call(map[string]interface{}{"execute": true, "shutdown_non_migratable": true}); !isErr || !strings.Contains(text, "no other host has room for default/big") || !strings.Contains(text, "pvc-b") {
+		t.Errorf("expected capacity and replicas to block execute: %s", text)
+	}
+	if len(patches) != 0 {
+		t.Fatalf("refused execute changed something: %v", patches)
+	}
+	text, isErr = call(map[string]interface{}{"execute": true, "shutdown_non_migratable": true, "force": true})
+	if isErr || !strings.Contains(text, "has no VMs left") || !strings.Contains(text, `"result": "stopped"`) || !strings.Contains(text, `"run_strategy": "Always"`) {
+		t.Errorf("execute: %s", text)
+	}
+	if len(patches) != 2 || patches[0] != `node-1 {"spec":{"unschedulable":true}}` || patches[1] != `gpu {"spec":{"runStrategy":"Halted","running":null}}` {
+		t.Errorf("patches = %v", patches)
+	}
+	if sort.Strings(migrations); strings.Join(migrations, " ") != "big secret web" {
+		t.Errorf("migrations = %v", migrations)
+	}
+}
+
+func TestHostInventoryHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/longhorn.io/v1beta2/namespaces/longhorn-system/nodes/node-1"):
+			w.Write([]byte(`{"spec":{"disks":{"default-disk":{"path":"/var/lib/harvester/defaultdisk","allowScheduling":true,"storageReserved":10737418240}}},
+				"status":{"diskStatus":{"default-disk":{"storageMaximum":107374182400,"storageAvailable":53687091200,"storageScheduled":48318382080,
+					"scheduledReplica":{"pvc-a-r-1":1073741824},"conditions":[{"type":"Ready","status":"True"},{"type":"Schedulable","status":"True"}]}}}}`))
+		case strings.HasSuffix(r.URL.Path, "/metrics.k8s.io/v1beta1/nodes/node-1"):
+			w.Write([]byte(`{"usage":{"cpu":"3","memory":"30Gi"}}`))
+		case strings.HasSuffix(r.URL.Path, "/nodes/node-1"):
+			w.Write([]byte(`{"metadata":{"name":"node-1","labels":{"host-model-cpu.node.kubevirt.io/Cascadelake-Server":"true","node-role.kubernetes.io/control-plane":"true"}},
+				"status":{"capacity":{"cpu":"16","memory":"64Gi"},"allocatable":{"cpu":"15","memory":"60Gi"},"addresses":[{"type":"InternalIP","address":"10.0.0.11"}]}}`))
+		case strings.HasSuffix(r.URL.Path, "/blockdevices"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"bd-1"},"spec":{"nodeName":"node-1","devPath":"/dev/sdb","provision":true,"tags":["ssd"]},
+					"status":{"provisionPhase":"Provisioned","state":"Active","deviceStatus":{"capacity":{"sizeBytes":2147483648},"details":{"deviceType":"disk","driveType":"SSD","model":"QEMU"}}}},
+				{"metadata":{"name":"bd-2"},"spec":{"nodeName":"node-2","devPath":"/dev/sdb"}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/clusternetworks"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"mgmt"},"status":{"conditions":[{"type":"ready","status":"True"}]}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/vlanconfigs"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"data-uplink"},"spec":{"clusterNetwork":"data","uplink":{"nics":["eno2","eno3"],"bondOptions":{"mode":"active-backup"},"linkAttributes":{"mtu":9000}}},
+				"status":{"matchedNodes":["node-1"]}},{"metadata":{"name":"other"},"spec":{"clusterNetwork":"storage"},"status":{"matchedNodes":["node-2"]}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/vlanstatuses"):
+			w.Write([]byte(`{"items":[{"status":{"node":"node-1","vlanConfig":"data-uplink","conditions":[{"type":"Ready","status":"True"}]}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/linkmonitors"):
+			w.Write([]byte(`{"items":[{"metadata":{"name":"nic"},"status":{"linkStatus":{"node-1":[{"name":"eno2","type":"device","state":"up"}],"node-2":[{"name":"eno1"}]}}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/api/v1/pods"):
+			if !strings.Contains(r.URL.Query().Get("fieldSelector"), "spec.nodeName=node-1") {
+				t.Errorf("pods not filtered by node: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"items":[{"metadata":{"labels":{"vm.kubevirt.io/name":"web"}},"spec":{"containers":[{"resources":{"requests":{"cpu":"1500m","memory":"6Gi"}}}]}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	call := func(args map[string]interface{}) (string, bool) {
+		args["cluster"], args["host"] = "c-xxx", "node-1"
+		result, err := toolset.hostInventoryHandler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(map[string]interface{}{})
+	if isErr {
+		t.Fatal(text)
+	}
+	var inv struct {
+		System map[string]interface{} `json:"system"`
+		Disks  struct {
+			BlockDevices  []map[string]interface{} `json:"block_devices"`
+			LonghornDisks []map[string]interface{} `json:"longhorn_disks"`
+		} `json:"disks"`
+		Network struct {
+			Uplinks []map[string]interface{}            `json:"uplinks"`
+			Links   map[string][]map[string]interface{} `json:"links"`
+		} `json:"network"`
+		PCI     map[string]interface{} `json:"pci"`
+		Metrics map[string]interface{} `json:"metrics"`
+	}
+	if err := json.Unmarshal([]byte(text), &inv); err != nil {
+		t.Fatalf("%v: %s", err, text)
+	}
+	if inv.System["cpu_model"] != "Cascadelake-Server" || inv.System["internal_ip"] != "10.0.0.11" || inv.System["memory"] != "64.0Gi" {
+		t.Errorf("system = %v", inv.System)
+	}
+	if len(inv.Disks.BlockDevices) != 1 || inv.Disks.BlockDevices[0]["size"] != "2.0Gi" || inv.Disks.BlockDevices[0]["provision_phase"] != "Provisioned" {
+		t.Errorf("block devices = %v", inv.Disks.BlockDevices)
+	}
+	if d := inv.Disks.LonghornDisks; len(d) != 1 || d[0]["scheduled_percent"] != float64(50) || d[0]["replicas"] != float64(1) || d[0]["ready"] != true {
+		t.Errorf("longhorn disks = %v", d)
+	}
+	if u := inv.Network.Uplinks; len(u) != 1 || u[0]["bond_mode"] != "active-backup" || u[0]["ready"] != true || len(inv.Network.Links["nic"]) != 1 {
+		t.Errorf("network = %+v", inv.Network)
+	}
+	if !strings.Contains(fmt.Sprint(inv.PCI["error"]), "pcidevices-controller") {
+		t.Errorf("pci = %v", inv.PCI)
+	}
+	if m := inv.Metrics; m["vms"] != float64(1) || m["cpu_requested_percent"] != float64(10) || m["memory_used_percent"] != float64(50) {
+		t.Errorf("metrics = %v", m)
+	}
+
+	if text, isErr := call(map[string]interface{}{"sections": "gpu"}); !isErr || !strings.Contains(text, "invalid section") {
+		t.Errorf("expected invalid section error: %s", text)
+	}
+}
+
+func TestBlockDeviceHandlers(t *testing.T) {
+	var patches []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/longhorn-system/blockdevices"):
+			w.Write([]byte(`{"items":[
+				{"metadata":{"name":"bd-new"},"spec":{"nodeName":"node-1","devPath":"/dev/sdb"},"status":{"state":"Active","deviceStatus":{"capacity":{"sizeBytes":1073741824}}}},
+				{"metadata":{"name":"bd-old"},"spec":{"nodeName":"node-1","devPath":"/dev/sdc","fileSystem":{"provisioned":false}},"status":{"state":"Active","deviceStatus":{"partitioned":true}}},
+				{"metadata":{"name":"bd-os"},"spec":{"nodeName":"node-1","devPath":"/dev/sda1"},"status":{"state":"Active","deviceStatus":{"fileSystem":{"type":"ext4","mountPoint":"/oem"}}}},
+				{"metadata":{"name":"bd-used"},"spec":{"nodeName":"node-1","devPath":"/dev/sdd","provision":true},"status":{"state":"Active","provisionPhase":"Provisioned"}},
+				{"metadata":{"name":"bd-empty"},"spec":{"nodeName":"node-2","devPath":"/dev/sdd","provision":true},"status":{"state":"Active","provisionPhase":"Provisioned"}}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/longhorn-system/nodes/node-1"):
+			w.Write([]byte(`{"status":{"diskStatus":{"bd-used":{"scheduledReplica":{"pvc-a-r-1":1073741824}}}}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/longhorn-system/nodes/node-2"):
+			w.Write([]byte(`{"status":{"diskStatus":{"bd-empty":{}}}}`))
+		case r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patches = append(patches, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]+" "+string(body))
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}) (string, bool) {
+		args["cluster"] = "c-xxx"
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, _ := call(toolset.blockDeviceListHandler, map[string]interface{}{"host": "node-1"})
+	var items []map[string]interface{}
+	json.Unmarshal([]byte(text), &items)
+	if len(items) != 3 {
+		t.Fatalf("unprovisioned = %s", text)
+	}
+	byName := map[string]map[string]interface{}{}
+	for _, it := range items {
+		byName[it["name"].(string)] = it
+	}
+	if byName["bd-new"]["can_provision"] != true || byName["bd-new"]["needs_force_format"] != false ||
+		byName["bd-old"]["needs_force_format"] != true || byName["bd-os"]["can_provision"] != false || byName["bd-os"]["blockers"] != "mounted at /oem" {
+		t.Errorf("list = %s", text)
+	}
+
+	if text, isErr := call(toolset.blockDeviceProvisionHandler, map[string]interface{}{"name": "bd-os", "action": "provision"}); !isErr || !strings.Contains(text, "mounted at /oem") {
+		t.Errorf("expected a mounted disk to be refused: %s", text)
+	}
+	if text, isErr := call(toolset.blockDeviceProvisionHandler, map[string]interface{}{"name": "bd-old", "action": "provision"}); !isErr || !strings.Contains(text, "force_format=true") {
+		t.Errorf("expected a partitioned disk to need force_format: %s", text)
+	}
+	if text, isErr := call(toolset.blockDeviceProvisionHandler, map[string]interface{}{"name": "bd-used", "action": "unprovision"}); !isErr || !strings.Contains(text, "pvc-a-r-1") {
+		t.Errorf("expected a disk with replicas to be refused: %s", text)
+	}
+	if len(patches) != 0 {
+		t.Fatalf("refused actions patched: %v", patches)
+	}
+	if _, isErr := call(toolset.blockDeviceProvisionHandler, map[string]interface{}{"name": "bd-old", "action": "provision", "force_format": true, "tags": "ssd, fast"}); isErr {
+		t.Error("provision failed")
+	}
+	if _, isErr := call(toolset.blockDeviceProvisionHandler, map[string]interface{}{"name": "bd-empty", "action": "unprovision"}); isErr {
+		t.Error("unprovision failed")
+	}
+	want := []string{
+		`bd-old {"spec":{"fileSystem":{"forceFormatted":true,"provisioned":true},"provision":true,"tags":["ssd","fast"]}}`,
+		`bd-empty {"spec":{"provision":false}}`,
+	}
+	if strings.Join(patches, "\n") != strings.Join(want, "\n") {
+		t.Errorf("patches = %v", patches)
+	}
+
+	noDestructive := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{DisableDestructive: true})
+	if text, isErr := call(noDestructive.blockDeviceProvisionHandler, map[string]interface{}{"name": "bd-old", "action": "provision", "force_format": true}); !isErr || !strings.Contains(text, "force_format wipes") {
+		t.Errorf("expected force_format to need destructive operations: %s", text)
+	}
+}
@@ -11,7 +11,7 @@ import (
 func (t *Toolset) hostActionTool() mcp.Tool {
 	return mcp.NewTool(
 		"harvester_host_action",
-		mcp.WithDescription("Enable or disable maintenance mode on a Harvester host (cordon/uncordon). Requires read_only=false."),
+		mcp.WithDescription("Enable or disable maintenance mode on a Harvester host (cordon/uncordon); VMs are not moved, use harvester_host_drain_plan to evacuate them first. Requires read_only=false."),
 		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
 		mcp.WithString("host", mcp.Required(), mcp.Description("Host (node) name")),
 		mcp.WithString("action", mcp.Required(), mcp.Description("Action: enable_maintenance (cordon) or disable_maintenance (uncordon)")),
@@ -0,0 +1,563 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// longhornNamespace is where Longhorn keeps its volumes, replicas and nodes.
+const longhornNamespace = "longhorn-system"
+
+// drainVM is a VM running on the host being drained, with what its virt-launcher pod requests.
+type drainVM struct {
+	Name      string
+	Namespace string
+	Reasons   []string // why it cannot be live-migrated; empty when it can
+	Owned     bool     // backed by a VirtualMachine, so it can be stopped via its run strategy
+	Denied    bool     // in a namespace the policy does not allow; left out of the plan and blocks execute
+	CPU       int64    // milli-CPU
+	Memory    int64    // bytes
+	Target    string   // host with room for it in the capacity estimate
+}
+
+// drainHost is another host's free capacity: allocatable minus the requests of the pods on it.
+type drainHost struct {
+	Name        string
+	CPU, Memory int64
+	FreeCPU     int64
+	FreeMemory  int64
+}
+
+// drainPlan is what draining a host would do.
+type drainPlan struct {
+	Host        string
+	VMs         []*drainVM
+	Hosts       []*drainHost
+	Unplaced    []string // migratable VMs that no other host has room for
+	Volumes     []map[string]interface{}
+	Unavailable []string // Longhorn volumes whose only healthy replicas are on the host
+	Warnings    []string
+}
+
+func (t *Toolset) hostDrainPlanTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_host_drain_plan",
+		mcp.WithDescription("Plan evacuating a Harvester host before maintenance mode: the VMs on it, which will live-migrate and which must be shut down (not migratable, with reasons), whether the other hosts have the CPU and memory to absorb them, and which Longhorn volumes lose a replica or their only healthy replica. execute=true carries the plan out (requires read_only=false): cordons the host, stops the non-migratable VMs (needs shutdown_non_migratable=true), live-migrates the rest and reports progress, with the run strategy each stopped VM had. VMs in namespaces the security policy does not allow are left out, and execute refuses while any are on the host."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("host", mcp.Required(), mcp.Description("Host (node) name")),
+		mcp.WithBoolean("execute", mcp.Description("Carry out the plan instead of only reporting it (default: false)")),
+		mcp.WithBoolean("shutdown_non_migratable", mcp.Description("execute: stop the VMs that cannot be live-migrated; without it execute refuses while there are any (default: false)")),
+		mcp.WithBoolean("force", mcp.Description("execute: proceed even if the other hosts lack capacity or Longhorn volumes would lose their only healthy replica (default: false)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("execute: max seconds to wait for the migrations and shutdowns (default: 1800, max: 1800)")),
+	)
+}
+
+func (t *Toolset) hostDrainPlanHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	host, err := req.RequireString("host")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	execute := req.GetBool("execute", false)
+	if execute {
+		if err := t.policy.CheckWrite(); err != nil {
+			return mcp.NewToolResultError("execute drains the host: " + err.Error()), nil
+		}
+	}
+
+	plan, err := t.drainPlan(ctx, cluster, host)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	data := plan.summary()
+	if !execute {
+		return t.formatObject(data)
+	}
+
+	var denied, shutdown []string
+	for _, vm := range plan.VMs {
+		if vm.Denied {
+			denied = append(denied, vm.Namespace+"/"+vm.Name)
+		}
+	}
+	if len(denied) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("not draining host %q: %d VM(s) on it are in namespaces the security policy does not allow: %s; they cannot be migrated or stopped through this server", host, len(denied), strings.Join(denied, ", "))), nil
+	}
+	for _, vm := range plan.VMs {
+		if len(vm.Reasons) > 0 {
+			if !vm.Owned {
+				return mcp.NewToolResultError(fmt.Sprintf("VM instance %s/%s cannot be live-migrated and has no VirtualMachine to stop it through; delete it or handle it first", vm.Namespace, vm.Name)), nil
+			}
+			shutdown = append(shutdown, vm.Namespace+"/"+vm.Name)
+		}
+	}
+	if len(shutdown) > 0 && !req.GetBool("shutdown_non_migratable", false) {
+		return mcp.NewToolResultError(fmt.Sprintf("%d VM(s) cannot be live-migrated and would have to be shut down: %s; set shutdown_non_migratable=true to stop them, or handle them first (see the plan with execute=false)", len(shutdown), strings.Join(shutdown, ", "))), nil
+	}
+	if !req.GetBool("force", false) {
+		var blockers []string
+		if len(plan.Unplaced) > 0 {
+			blockers = append(blockers, "no other host has room for "+strings.Join(plan.Unplaced, ", "))
+		}
+		if len(plan.Unavailable) > 0 {
+			blockers = append(blockers, "Longhorn volumes have no healthy replica on other hosts: "+strings.Join(plan.Unavailable, ", "))
+		}
+		if len(blockers) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("not draining host %q: %s; set force=true to proceed anyway", host, strings.Join(blockers, "; "))), nil
+		}
+	}
+	timeout := time.Duration(min(req.GetInt("timeout_seconds", 1800), maxWaitTimeoutSeconds)) * time.Second
+	if timeout <= 0 {
+		timeout = 1800 * time.Second
+	}
+
+	// Cordon first so that neither the migrations nor new VMs land on the host.
+	if err := t.mergePatch(ctx, cluster, rancher.TypeNodes, "", host, map[string]interface{}{"spec": map[string]interface{}{"unschedulable": true}}); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("harvester_host_drain_plan: cordoning host %q: %v", host, err)), nil
+	}
+	results, done := t.drainExecute(ctx, cluster, plan, timeout, wait.Progress(ctx, req, timeout))
+	data["execution"] = results
+	if done {
+		data["result"] = fmt.Sprintf("Host %s is cordoned and has no VMs left; it can be taken down for maintenance", host)
+	} else {
+		data["result"] = fmt.Sprintf("Host %s is cordoned but not all VMs left it; see execution for the ones that failed or timed out", host)
+	}
+	if len(shutdown) > 0 {
+		data["result"] = fmt.Sprint(data["result"], "; after maintenance, restore the stopped VMs to the run_strategy recorded in execution (harvester_vm_update)")
+	}
+	return t.formatObject(data)
+}
+
+// drainPlan gathers the VMs on host, the room on the other hosts and the Longhorn replicas on host.
+func (t *Toolset) drainPlan(ctx context.Context, cluster, host string) (*drainPlan, error) {
+	nodes, err := t.client.List(ctx, cluster, rancher.TypeNodes, rancher.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %v", err)
+	}
+	plan := &drainPlan{Host: host}
+	found := false
+	for i := range nodes.Data {
+		n := &nodes.Data[i]
+		if n.ObjectMeta.Name == host {
+			found = true
+			continue
+		}
+		if reason := vmNodeUnavailable(n); reason != "" {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("host %s %s and cannot take VMs", n.ObjectMeta.Name, reason))
+			continue
+		}
+		status, _ := n.Status.(map[string]interface{})
+		allocatable := nestedMap(status, "allocatable")
+		plan.Hosts = append(plan.Hosts, &drainHost{
+			Name:   n.ObjectMeta.Name,
+			CPU:    quantityValue(allocatable["cpu"], true),
+			Memory: quantityValue(allocatable["memory"], false),
+		})
+	}
+	if !found {
+		return nil, fmt.Errorf("host %q not found", host)
+	}
+
+	pods, err := t.listObjects(ctx, cluster, "/api/v1/pods", url.Values{"fieldSelector": {"status.phase!=Succeeded,status.phase!=Failed"}}, "pods")
+	if err != nil {
+		return nil, err
+	}
+	hosts := map[string]*drainHost{}
+	for _, h := range plan.Hosts {
+		h.FreeCPU, h.FreeMemory = h.CPU, h.Memory
+		hosts[h.Name] = h
+	}
+	launchers := map[string]map[string]interface{}{}
+	for _, pod := range pods {
+		node, _ := nestedMap(pod, "spec")["nodeName"].(string)
+		cpu, memory := podRequests(pod)
+		if h := hosts[node]; h != nil {
+			h.FreeCPU -= cpu
+			h.FreeMemory -= memory
+		}
+		meta := nestedMap(pod, "metadata")
+		if vm, _ := nestedMap(meta, "labels")["vm.kubevirt.io/name"].(string); vm != "" && node == host {
+			launchers[fmt.Sprint(meta["namespace"], "/", vm)] = pod
+		}
+	}
+
+	vmis, err := t.listVMIs(ctx, cluster, "")
+	if err != nil {
+		return nil, err
+	}
+	claims := t.claimAccessModes(ctx, cluster, "")
+	for _, vmi := range vmis {
+		if nestedMap(vmi, "status")["nodeName"] != host {
+			continue
+		}
+		meta := nestedMap(vmi, "metadata")
+		vm := &drainVM{Reasons: t.migrationBlockers(ctx, cluster, vmi, claims)}
+		vm.Name, _ = meta["name"].(string)
+		vm.Namespace, _ = meta["namespace"].(string)
+		vm.Denied = t.policy.CheckNamespace(vm.Namespace) != nil
+		owners, _ := meta["ownerReferences"].([]interface{})
+		for _, o := range owners {
+			if m, _ := o.(map[string]interface{}); m["kind"] == "VirtualMachine" {
+				vm.Owned = true
+			}
+		}
+		if pod := launchers[vm.Namespace+"/"+vm.Name]; pod != nil {
+			vm.CPU, vm.Memory = podRequests(pod)
+		} else {
+			requests := nestedMap(vmi, "spec", "domain", "resources", "requests")
+			vm.CPU, vm.Memory = quantityValue(requests["cpu"], true), quantityValue(requests["memory"], false)
+		}
+		plan.VMs = append(plan.VMs, vm)
+	}
+	sort.SliceStable(plan.VMs, func(i, j int) bool {
+		return plan.VMs[i].Namespace+"/"+plan.VMs[i].Name < plan.VMs[j].Namespace+"/"+plan.VMs[j].Name
+	})
+	plan.place()
+	if err := t.longhornImpact(ctx, cluster, plan); err != nil {
+		plan.Warnings = append(plan.Warnings, "Longhorn replica impact unknown: "+err.Error())
+	}
+	return plan, nil
+}
+
+// place assigns the migratable VMs, largest memory first, to the host with the most free memory that
+// fits them. The scheduler makes the real choice; this only estimates whether the room exists.
+func (p *drainPlan) place() {
+	var migrate []*drainVM
+	for _, vm := range p.VMs {
+		if len(vm.Reasons) == 0 {
+			migrate = append(migrate, vm)
+		}
+	}
+	sort.SliceStable(migrate, func(i, j int) bool { return migrate[i].Memory > migrate[j].Memory })
+	for _, vm := range migrate {
+		var best *drainHost
+		for _, h := range p.Hosts {
+			if h.FreeCPU >= vm.CPU && h.FreeMemory >= vm.Memory && (best == nil || h.FreeMemory > best.FreeMemory) {
+				best = h
+			}
+		}
+		if best == nil {
+			p.Unplaced = append(p.Unplaced, vm.Namespace+"/"+vm.Name)
+			continue
+		}
+		vm.Target = best.Name
+		best.FreeCPU -= vm.CPU
+		best.FreeMemory -= vm.Memory
+	}
+	sort.Strings(p.Unplaced)
+}
+
+// longhornImpact reports the Longhorn volumes with a replica on the host and how many healthy replicas
+// they keep elsewhere.
+func (t *Toolset) longhornImpact(ctx context.Context, cluster string, p *drainPlan) error {
+	path, _ := rancher.ResourcePath(rancher.TypeLonghornReplicas, longhornNamespace, "")
+	replicas, err := t.listObjects(ctx, cluster, path, nil, "Longhorn replicas")
+	if err != nil {
+		return err
+	}
+	path, _ = rancher.ResourcePath(rancher.TypeLonghornVolumes, longhornNamespace, "")
+	volumes, err := t.listObjects(ctx, cluster, path, nil, "Longhorn volumes")
+	if err != nil {
+		return err
+	}
+	onHost := map[string]int{}
+	healthyElsewhere := map[string]int{}
+	for _, r := range replicas {
+		spec := nestedMap(r, "spec")
+		volume, _ := spec["volumeName"].(string)
+		if spec["nodeID"] == p.Host {
+			onHost[volume]++
+			continue
+		}
+		if nestedMap(r, "status")["currentState"] == "running" && (spec["failedAt"] == nil || spec["failedAt"] == "") {
+			healthyElsewhere[volume]++
+		}
+	}
+	for _, v := range volumes {
+		name, _ := nestedMap(v, "metadata")["name"].(string)
+		if onHost[name] == 0 {
+			continue
+		}
+		status := nestedMap(v, "status")
+		k8s := nestedMap(status, "kubernetesStatus")
+		entry := map[string]interface{}{
+			"volume":            name,
+			"state":             status["state"],
+			"robustness":        status["robustness"],
+			"replicas":          nestedMap(v, "spec")["numberOfReplicas"],
+			"replicas_on_host":  onHost[name],
+			"healthy_elsewhere": healthyElsewhere[name],
+		}
+		if pvc, _ := k8s["pvcName"].(string); pvc != "" {
+			entry["pvc"] = fmt.Sprint(k8s["namespace"], "/", pvc)
+		}
+		if healthyElsewhere[name] == 0 {
+			entry["impact"] = "no healthy replica on other hosts: unavailable while the host is down"
+			p.Unavailable = append(p.Unavailable, name)
+		} else {
+			entry["impact"] = "degraded until Longhorn rebuilds the replica on another host"
+		}
+		p.Volumes = append(p.Volumes, entry)
+	}
+	sort.SliceStable(p.Volumes, func(i, j int) bool {
+		return fmt.Sprint(p.Volumes[i]["volume"]) < fmt.Sprint(p.Volumes[j]["volume"])
+	})
+	return nil
+}
+
+func (p *drainPlan) summary() map[string]interface{} {
+	var vms []map[string]interface{}
+	migrate, shutdown, denied := 0, 0, 0
+	for _, vm := range p.VMs {
+		if vm.Denied {
+			denied++
+			continue
+		}
+		item := map[string]interface{}{
+			"name":      vm.Name,
+			"namespace": vm.Namespace,
+			"cpu":       formatCPU(vm.CPU),
+			"memory":    formatMemory(vm.Memory),
+		}
+		if len(vm.Reasons) == 0 {
+			migrate++
+			item["action"] = "migrate"
+			if vm.Target != "" {
+				item["fits_on"] = vm.Target
+			}
+		} else {
+			shutdown++
+			item["action"] = "shutdown"
+			item["reasons"] = strings.Join(vm.Reasons, "; ")
+		}
+		vms = append(vms, item)
+	}
+	var hosts []map[string]interface{}
+	for _, h := range p.Hosts {
+		hosts = append(hosts, map[string]interface{}{
+			"host":               h.Name,
+			"allocatable_cpu":    formatCPU(h.CPU),
+			"allocatable_memory": formatMemory(h.Memory),
+			"free_cpu_after":     formatCPU(h.FreeCPU),
+			"free_memory_after":  formatMemory(h.FreeMemory),
+		})
+	}
+	warnings := p.Warnings
+	if denied > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d VM(s) on this host are in namespaces the security policy does not allow; they are left out of vms and execute refuses while they are on the host", denied))
+	}
+	if len(p.Hosts) == 0 && migrate > 0 {
+		warnings = append(warnings, "no other host can take VMs")
+	}
+	if len(p.Unplaced) > 0 {
+		warnings = append(warnings, "not enough CPU or memory on other hosts for "+strings.Join(p.Unplaced, ", "))
+	}
+	if len(p.Unavailable) > 0 {
+		warnings = append(warnings, "Longhorn volumes whose only healthy replicas are on this host: "+strings.Join(p.Unavailable, ", "))
+	}
+	return map[string]interface{}{
+		"host":             p.Host,
+		"vms":              vms,
+		"migrate":          migrate,
+		"shutdown":         shutdown,
+		"denied":           denied,
+		"capacity_ok":      len(p.Unplaced) == 0 && (migrate == 0 || len(p.Hosts) > 0),
+		"other_hosts":      hosts,
+		"longhorn_volumes": p.Volumes,
+		"warnings":         warnings,
+	}
+}
+
+// drainExecute stops the non-migratable VMs, starts a migration for each other VM and waits until they
+// have all left the host. It reports the outcome per VM and whether every VM left.
+func (t *Toolset) drainExecute(ctx context.Context, cluster string, p *drainPlan, timeout time.Duration, progress wait.ProgressFunc) ([]map[string]interface{}, bool) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	results := make([]map[string]interface{}, len(p.VMs))
+	pending := map[int]string{} // index in p.VMs -> migration name, "" for a shutdown
+	for i, vm := range p.VMs {
+		results[i] = map[string]interface{}{"name": vm.Name, "namespace": vm.Namespace}
+		if len(vm.Reasons) > 0 {
+			results[i]["action"] = "shutdown"
+			strategy, running, err := t.vmRunStrategy(ctx, cluster, vm.Namespace, vm.Name)
+			if err != nil {
+				results[i]["result"] = "stop failed: " + err.Error()
+				continue
+			}
+			results[i]["run_strategy"] = strategy
+			spec := map[string]interface{}{"runStrategy": "Halted"}
+			if running {
+				spec["running"] = nil // runStrategy and the legacy running field are mutually exclusive
+			}
+			patch := map[string]interface{}{"spec": spec}
+			if err := t.mergePatch(ctx, cluster, rancher.TypeVirtualMachines, vm.Namespace, vm.Name, patch); err != nil {
+				results[i]["result"] = "stop failed: " + err.Error()
+				continue
+			}
+			pending[i] = ""
+			continue
+		}
+		results[i]["action"] = "migrate"
+		migration := t.activeMigration(ctx, cluster, vm.Namespace, vm.Name)
+		if migration == "" {
+			var err error
+			if migration, err = t.startMigration(ctx, cluster, vm.Namespace, vm.Name, ""); err != nil {
+				results[i]["result"] = "migration not started: " + err.Error()
+				continue
+			}
+		}
+		results[i]["migration"] = migration
+		pending[i] = migration
+	}
+
+	total := len(pending)
+	for len(pending) > 0 {
+		for i, migration := range pending {
+			vm := p.VMs[i]
+			if migration == "" {
+				if t.vmiGone(ctx, cluster, vm.Namespace, vm.Name) {
+					results[i]["result"] = "stopped"
+					delete(pending, i)
+				}
+				continue
+			}
+			info, err := t.migrationStatus(ctx, cluster, vm.Namespace, migration)
+			if err != nil {
+				continue
+			}
+			switch info["phase"] {
+			case "Succeeded":
+				results[i]["result"] = fmt.Sprintf("migrated to %v", info["target_node"])
+				delete(pending, i)
+			case "Failed":
+				results[i]["result"] = fmt.Sprintf("migration failed: %v", info["failure_reason"])
+				delete(pending, i)
+			default:
+				results[i]["result"] = fmt.Sprintf("in progress (phase %v)", info["phase"])
+			}
+		}
+		if progress != nil {
+			progress(time.Since(start), fmt.Sprintf("%d of %d VMs off host %s", total-len(pending), total, p.Host))
+		}
+		if len(pending) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			for i := range pending {
+				results[i]["result"] = fmt.Sprintf("timed out after %s: %v", timeout, results[i]["result"])
+			}
+			return results, false
+		case <-time.After(migrationPollInterval):
+		}
+	}
+	for _, r := range results {
+		if res, _ := r["result"].(string); res != "stopped" && !strings.HasPrefix(res, "migrated") {
+			return results, false
+		}
+	}
+	return results, true
+}
+
+// vmRunStrategy returns a VM's run strategy, and whether it is set through the legacy spec.running
+// field instead (true maps to Always, false to Halted).
+func (t *Toolset) vmRunStrategy(ctx context.Context, cluster, namespace, name string) (string, bool, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachines, namespace, name)
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return "", false, err
+	}
+	if code != http.StatusOK {
+		return "", false, fmt.Errorf("VM %q: %d %s", name, code, apiMessage(body))
+	}
+	var vm map[string]interface{}
+	if err := json.Unmarshal(body, &vm); err != nil {
+		return "", false, err
+	}
+	spec := nestedMap(vm, "spec")
+	if strategy, _ := spec["runStrategy"].(string); strategy != "" {
+		return strategy, false, nil
+	}
+	if running, ok := spec["running"].(bool); ok {
+		if running {
+			return "Always", true, nil
+		}
+		return "Halted", true, nil
+	}
+	return "Halted", false, nil
+}
+
+// vmiGone reports whether a VM's instance no longer exists, i.e. the VM has stopped.
+func (t *Toolset) vmiGone(ctx context.Context, cluster, namespace, name string) bool {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachineInstances, namespace, name)
+	_, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	return err == nil && code == http.StatusNotFound
+}
+
+// mergePatch applies a JSON merge patch through the native API; unlike SteveClient.Patch it does not
+// rewrite the whole object, so it cannot clobber concurrent changes by controllers.
+func (t *Toolset) mergePatch(ctx context.Context, cluster, resourceType, namespace, name string, patch map[string]interface{}) error {
+	data, _ := json.Marshal(patch)
+	path, err := rancher.ResourcePath(resourceType, namespace, name)
+	if err != nil {
+		return err
+	}
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, data, rancher.PatchTypeMerge)
+	if err != nil {
+		return err
+	}
+	if code < 200 || code >= 300 {
+		return fmt.Errorf("%d %s", code, apiMessage(body))
+	}
+	return nil
+}
+
+// podRequests sums the CPU (milli) and memory (bytes) requests of a pod's containers and its overhead.
+func podRequests(pod map[string]interface{}) (cpu, memory int64) {
+	spec := nestedMap(pod, "spec")
+	containers, _ := spec["containers"].([]interface{})
+	for _, c := range containers {
+		m, _ := c.(map[string]interface{})
+		requests := nestedMap(m, "resources", "requests")
+		cpu += quantityValue(requests["cpu"], true)
+		memory += quantityValue(requests["memory"], false)
+	}
+	overhead := nestedMap(spec, "overhead")
+	return cpu + quantityValue(overhead["cpu"], true), memory + quantityValue(overhead["memory"], false)
+}
+
+// quantityValue parses a Kubernetes quantity, in milli-units when milli is set; invalid or missing is 0.
+func quantityValue(v interface{}, milli bool) int64 {
+	s, _ := v.(string)
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0
+	}
+	if milli {
+		return q.MilliValue()
+	}
+	return q.Value()
+}
+
+func formatCPU(milli int64) string {
+	return fmt.Sprintf("%.2f cores", float64(milli)/1000)
+}
+
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%.1fGi", float64(bytes)/(1<<30))
+}
@@ -0,0 +1,471 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+// inventorySections are the parts of harvester_host_inventory, in output order.
+var inventorySections = []string{"system", "disks", "network", "pci", "metrics"}
+
+// KubeVirt node labels naming the host CPU.
+const (
+	hostModelCPULabel = "host-model-cpu.node.kubevirt.io/"
+	cpuVendorLabel    = "cpu-vendor.node.kubevirt.io/"
+)
+
+func (t *Toolset) hostInventoryTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_host_inventory",
+		mcp.WithDescription("Hardware and capacity details of a Harvester host: CPU model, cores and memory (system); node-disk-manager BlockDevices and Longhorn disk allocation (disks); cluster network uplinks, VLAN configs and link state (network); PCI devices and passthrough claims (pci); requested vs used CPU and memory (metrics)"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("host", mcp.Required(), mcp.Description("Host (node) name")),
+		mcp.WithString("sections", mcp.Description("Comma-separated sections: system, disks, network, pci, metrics (default: all)")),
+	)
+}
+
+func (t *Toolset) hostInventoryHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	host, err := req.RequireString("host")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sections := inventorySections
+	if s := req.GetString("sections", ""); s != "" {
+		sections = splitList(s)
+		for _, sec := range sections {
+			if !slices.Contains(inventorySections, sec) {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid section %q; allowed: %s", sec, strings.Join(inventorySections, ", "))), nil
+			}
+		}
+	}
+
+	node, err := t.client.Get(ctx, cluster, rancher.TypeNodes, "", host)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("host %q: %v", host, err)), nil
+	}
+	status, _ := node.Status.(map[string]interface{})
+	data := map[string]interface{}{"host": host}
+	for _, sec := range sections {
+		var value interface{}
+		var err error
+		switch sec {
+		case "system":
+			value = hostSystem(node, status)
+		case "disks":
+			value, err = t.hostDisks(ctx, cluster, host)
+		case "network":
+			value, err = t.hostNetwork(ctx, cluster, host)
+		case "pci":
+			value, err = t.hostPCIDevices(ctx, cluster, host)
+		case "metrics":
+			value, err = t.hostMetrics(ctx, cluster, host, status)
+		}
+		if err != nil {
+			value = map[string]interface{}{"error": err.Error()}
+		}
+		data[sec] = value
+	}
+	return t.formatObject(data)
+}
+
+// hostSystem describes the host from its Node object and KubeVirt's CPU labels.
+func hostSystem(node *rancher.SteveResource, status map[string]interface{}) map[string]interface{} {
+	info := nestedMap(status, "nodeInfo")
+	capacity := nestedMap(status, "capacity")
+	allocatable := nestedMap(status, "allocatable")
+	system := map[string]interface{}{
+		"architecture":       info["architecture"],
+		"os_image":           info["osImage"],
+		"kernel":             info["kernelVersion"],
+		"kubelet":            info["kubeletVersion"],
+		"container_runtime":  info["containerRuntimeVersion"],
+		"cpu_cores":          capacity["cpu"],
+		"cpu_allocatable":    allocatable["cpu"],
+		"memory":             formatMemory(quantityValue(capacity["memory"], false)),
+		"memory_allocatable": formatMemory(quantityValue(allocatable["memory"], false)),
+		"pods_allocatable":   allocatable["pods"],
+	}
+	for k := range node.ObjectMeta.Labels {
+		if model, ok := strings.CutPrefix(k, hostModelCPULabel); ok {
+			system["cpu_model"] = model
+		}
+		if vendor, ok := strings.CutPrefix(k, cpuVendorLabel); ok {
+			system["cpu_vendor"] = vendor
+		}
+	}
+	var roles []string
+	for k := range node.ObjectMeta.Labels {
+		if role, ok := strings.CutPrefix(k, "node-role.kubernetes.io/"); ok {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	system["roles"] = roles
+	addresses, _ := status["addresses"].([]interface{})
+	for _, a := range addresses {
+		if m, _ := a.(map[string]interface{}); m["type"] == "InternalIP" {
+			system["internal_ip"] = m["address"]
+		}
+	}
+	return system
+}
+
+// hostDisks lists the host's node-disk-manager BlockDevices and its Longhorn disks with their allocation.
+func (t *Toolset) hostDisks(ctx context.Context, cluster, host string) (map[string]interface{}, error) {
+	devices, err := t.hostBlockDevices(ctx, cluster, host)
+	if err != nil {
+		return nil, err
+	}
+	var blockDevices []map[string]interface{}
+	for _, bd := range devices {
+		blockDevices = append(blockDevices, blockDeviceSummary(bd))
+	}
+	disks := map[string]interface{}{"block_devices": blockDevices}
+
+	path, _ := rancher.ResourcePath(rancher.TypeLonghornNodes, longhornNamespace, host)
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	var lhNode map[string]interface{}
+	switch {
+	case err != nil:
+		disks["longhorn_disks"] = map[string]interface{}{"error": err.Error()}
+	case code != http.StatusOK:
+		disks["longhorn_disks"] = map[string]interface{}{"error": fmt.Sprintf("Longhorn node %q: %d %s", host, code, apiMessage(body))}
+	case json.Unmarshal(body, &lhNode) != nil:
+		disks["longhorn_disks"] = map[string]interface{}{"error": "cannot decode Longhorn node"}
+	default:
+		disks["longhorn_disks"] = longhornDisks(lhNode)
+	}
+	return disks, nil
+}
+
+// hostBlockDevices returns the BlockDevices node-disk-manager found on host (all hosts when empty).
+func (t *Toolset) hostBlockDevices(ctx context.Context, cluster, host string) ([]map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeBlockDevices, longhornNamespace, "")
+	all, err := t.listObjects(ctx, cluster, path, nil, "block devices")
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]interface{}
+	for _, bd := range all {
+		if host == "" || nestedMap(bd, "spec")["nodeName"] == host {
+			out = append(out, bd)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		si, sj := nestedMap(out[i], "spec"), nestedMap(out[j], "spec")
+		return fmt.Sprint(si["nodeName"], " ", si["devPath"]) < fmt.Sprint(sj["nodeName"], " ", sj["devPath"])
+	})
+	return out, nil
+}
+
+func blockDeviceSummary(bd map[string]interface{}) map[string]interface{} {
+	spec := nestedMap(bd, "spec")
+	status := nestedMap(bd, "status")
+	device := nestedMap(status, "deviceStatus")
+	details := nestedMap(device, "details")
+	fs := nestedMap(device, "fileSystem")
+	size := int64(numberValue(nestedMap(device, "capacity")["sizeBytes"]))
+	return map[string]interface{}{
+		"name":            nestedMap(bd, "metadata")["name"],
+		"dev_path":        spec["devPath"],
+		"type":            details["deviceType"],
+		"drive_type":      details["driveType"],
+		"model":           details["model"],
+		"vendor":          details["vendor"],
+		"serial":          details["serialNumber"],
+		"wwn":             details["wwn"],
+		"size":            formatMemory(size),
+		"size_bytes":      size,
+		"filesystem":      fs["type"],
+		"mount_point":     fs["mountPoint"],
+		"partitioned":     device["partitioned"],
+		"provision":       blockDeviceProvisioned(bd),
+		"provision_phase": status["provisionPhase"],
+		"state":           status["state"],
+		"tags":            spec["tags"],
+	}
+}
+
+// blockDeviceProvisioned reports whether a BlockDevice is (to be) a Longhorn disk; node-disk-manager before
+// v0.5 used spec.fileSystem.provisioned instead of spec.provision.
+func blockDeviceProvisioned(bd map[string]interface{}) bool {
+	spec := nestedMap(bd, "spec")
+	provisioned, _ := spec["provision"].(bool)
+	legacy, _ := nestedMap(spec, "fileSystem")["provisioned"].(bool)
+	return provisioned || legacy
+}
+
+// longhornDisks summarises the disks of a Longhorn Node: size, what is reserved, available and promised
+// to replicas (storage_scheduled may exceed the size with over-provisioning).
+func longhornDisks(node map[string]interface{}) []map[string]interface{} {
+	specDisks := nestedMap(node, "spec", "disks")
+	diskStatus := nestedMap(node, "status", "diskStatus")
+	var out []map[string]interface{}
+	for name, d := range specDisks {
+		spec, _ := d.(map[string]interface{})
+		st := nestedMap(diskStatus, name)
+		maximum := int64(numberValue(st["storageMaximum"]))
+		reserved := int64(numberValue(spec["storageReserved"]))
+		scheduled := int64(numberValue(st["storageScheduled"]))
+		disk := map[string]interface{}{
+			"name":              name,
+			"path":              spec["path"],
+			"disk_type":         spec["diskType"],
+			"allow_scheduling":  spec["allowScheduling"],
+			"eviction":          spec["evictionRequested"],
+			"tags":              spec["tags"],
+			"storage_maximum":   formatMemory(maximum),
+			"storage_available": formatMemory(int64(numberValue(st["storageAvailable"]))),
+			"storage_reserved":  formatMemory(reserved),
+			"storage_scheduled": formatMemory(scheduled),
+			"replicas":          len(nestedMap(st, "scheduledReplica")),
+		}
+		if maximum > reserved {
+			disk["scheduled_percent"] = scheduled * 100 / (maximum - reserved)
+		}
+		conds, _ := st["conditions"].([]interface{})
+		for _, c := range conds {
+			m, _ := c.(map[string]interface{})
+			switch m["type"] {
+			case "Ready":
+				disk["ready"] = m["status"] == "True"
+			case "Schedulable":
+				disk["schedulable"] = m["status"] == "True"
+				if m["status"] != "True" && m["message"] != nil {
+					disk["message"] = m["message"]
+				}
+			}
+		}
+		out = append(out, disk)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return fmt.Sprint(out[i]["name"]) < fmt.Sprint(out[j]["name"]) })
+	return out
+}
+
+// hostNetwork reports the cluster networks, the VLAN configs whose uplinks include the host with their
+// per-host state, and the host's links as seen by the LinkMonitors.
+func (t *Toolset) hostNetwork(ctx context.Context, cluster, host string) (map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeClusterNetworks, "", "")
+	clusterNetworks, err := t.listObjects(ctx, cluster, path, nil, "cluster networks")
+	if err != nil {
+		return nil, err
+	}
+	var networks []map[string]interface{}
+	for _, cn := range clusterNetworks {
+		item := map[string]interface{}{"name": nestedMap(cn, "metadata")["name"]}
+		conds, _ := nestedMap(cn, "status")["conditions"].([]interface{})
+		for _, c := range conds {
+			if m, _ := c.(map[string]interface{}); m["type"] == "ready" || m["type"] == "Ready" {
+				item["ready"] = m["status"] == "True"
+			}
+		}
+		networks = append(networks, item)
+	}
+	out := map[string]interface{}{"cluster_networks": networks}
+
+	path, _ = rancher.ResourcePath(rancher.TypeVlanStatuses, "", "")
+	statuses, _ := t.listObjects(ctx, cluster, path, nil, "VLAN statuses")
+	vlanState := map[string]map[string]interface{}{}
+	for _, vs := range statuses {
+		st := nestedMap(vs, "status")
+		if st["node"] != host {
+			continue
+		}
+		state := map[string]interface{}{}
+		conds, _ := st["conditions"].([]interface{})
+		for _, c := range conds {
+			m, _ := c.(map[string]interface{})
+			state[strings.ToLower(fmt.Sprint(m["type"]))] = m["status"] == "True"
+			if m["status"] != "True" && m["message"] != nil {
+				state["message"] = m["message"]
+			}
+		}
+		vlanState[fmt.Sprint(st["vlanConfig"])] = state
+	}
+
+	path, _ = rancher.ResourcePath(rancher.TypeVlanConfigs, "", "")
+	configs, err := t.listObjects(ctx, cluster, path, nil, "VLAN configs")
+	if err != nil {
+		out["uplinks"] = map[string]interface{}{"error": err.Error()}
+	} else {
+		var uplinks []map[string]interface{}
+		for _, vc := range configs {
+			name, _ := nestedMap(vc, "metadata")["name"].(string)
+			matched, _ := nestedMap(vc, "status")["matchedNodes"].([]interface{})
+			if !slices.Contains(matched, interface{}(host)) {
+				continue
+			}
+			spec := nestedMap(vc, "spec")
+			uplink := nestedMap(spec, "uplink")
+			item := map[string]interface{}{
+				"vlan_config":     name,
+				"cluster_network": spec["clusterNetwork"],
+				"nics":            uplink["nics"],
+				"bond_mode":       nestedMap(uplink, "bondOptions")["mode"],
+				"mtu":             nestedMap(uplink, "linkAttributes")["mtu"],
+			}
+			for k, v := range vlanState[name] {
+				item[k] = v
+			}
+			uplinks = append(uplinks, item)
+		}
+		out["uplinks"] = uplinks
+	}
+
+	path, _ = rancher.ResourcePath(rancher.TypeLinkMonitors, "", "")
+	monitors, err := t.listObjects(ctx, cluster, path, nil, "link monitors")
+	if err != nil {
+		out["links"] = map[string]interface{}{"error": err.Error()}
+		return out, nil
+	}
+	links := map[string]interface{}{}
+	for _, lm := range monitors {
+		name, _ := nestedMap(lm, "metadata")["name"].(string)
+		raw, _ := nestedMap(lm, "status", "linkStatus")[host].([]interface{})
+		var items []map[string]interface{}
+		for _, l := range raw {
+			m, _ := l.(map[string]interface{})
+			items = append(items, map[string]interface{}{
+				"name":  m["name"],
+				"type":  m["type"],
+				"mac":   m["mac"],
+				"state": m["state"],
+			})
+		}
+		if len(items) > 0 {
+			links[name] = items
+		}
+	}
+	out["links"] = links
+	return out, nil
+}
+
+// hostPCIDevices lists the PCI devices the pcidevices-controller addon found on the host and which are
+// claimed for passthrough.
+func (t *Toolset) hostPCIDevices(ctx context.Context, cluster, host string) ([]map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypePCIDevices, "", "")
+	devices, err := t.listObjects(ctx, cluster, path, nil, "PCI devices")
+	if err != nil {
+		return nil, fmt.Errorf("%v (PCI devices require the pcidevices-controller addon)", err)
+	}
+	claims := map[string]string{}
+	path, _ = rancher.ResourcePath(rancher.TypePCIDeviceClaims, "", "")
+	if list, err := t.listObjects(ctx, cluster, path, nil, "PCI device claims"); err == nil {
+		for _, c := range list {
+			name, _ := nestedMap(c, "metadata")["name"].(string)
+			claims[name] = fmt.Sprint(nestedMap(c, "spec")["userName"])
+		}
+	}
+	var out []map[string]interface{}
+	for _, d := range devices {
+		status := nestedMap(d, "status")
+		if status["nodeName"] != host {
+			continue
+		}
+		name, _ := nestedMap(d, "metadata")["name"].(string)
+		item := map[string]interface{}{
+			"name":          name,
+			"address":       status["address"],
+			"vendor_id":     status["vendorId"],
+			"device_id":     status["deviceId"],
+			"class_id":      status["classId"],
+			"description":   status["description"],
+			"driver":        status["kernelDriverInUse"],
+			"iommu_group":   status["iommuGroup"],
+			"resource_name": status["resourceName"],
+		}
+		if user, ok := claims[name]; ok {
+			item["claimed_by"] = user
+		}
+		out = append(out, item)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return fmt.Sprint(out[i]["address"]) < fmt.Sprint(out[j]["address"]) })
+	return out, nil
+}
+
+// hostMetrics compares what the pods on the host request with its allocatable capacity and, when
+// metrics-server is installed, its actual usage.
+func (t *Toolset) hostMetrics(ctx context.Context, cluster, host string, status map[string]interface{}) (map[string]interface{}, error) {
+	pods, err := t.listObjects(ctx, cluster, "/api/v1/pods", url.Values{"fieldSelector": {"spec.nodeName=" + host + ",status.phase!=Succeeded,status.phase!=Failed"}}, "pods")
+	if err != nil {
+		return nil, err
+	}
+	var cpu, memory int64
+	vms := 0
+	for _, pod := range pods {
+		c, m := podRequests(pod)
+		cpu += c
+		memory += m
+		if nestedMap(pod, "metadata", "labels")["vm.kubevirt.io/name"] != nil {
+			vms++
+		}
+	}
+	allocatable := nestedMap(status, "allocatable")
+	allocCPU := quantityValue(allocatable["cpu"], true)
+	allocMemory := quantityValue(allocatable["memory"], false)
+	out := map[string]interface{}{
+		"pods":               len(pods),
+		"vms":                vms,
+		"cpu_allocatable":    formatCPU(allocCPU),
+		"cpu_requested":      formatCPU(cpu),
+		"memory_allocatable": formatMemory(allocMemory),
+		"memory_requested":   formatMemory(memory),
+	}
+	if allocCPU > 0 {
+		out["cpu_requested_percent"] = cpu * 100 / allocCPU
+	}
+	if allocMemory > 0 {
+		out["memory_requested_percent"] = memory * 100 / allocMemory
+	}
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, "/apis/metrics.k8s.io/v1beta1/nodes/"+host, nil, nil, "")
+	var usage struct {
+		Usage map[string]interface{} `json:"usage"`
+	}
+	switch {
+	case err != nil:
+		out["usage"] = "unavailable: " + err.Error()
+	case code != http.StatusOK:
+		out["usage"] = fmt.Sprintf("unavailable: metrics API returned %d (install metrics-server for live usage)", code)
+	case json.Unmarshal(body, &usage) != nil:
+		out["usage"] = "unavailable: cannot decode node metrics"
+	default:
+		usedCPU := quantityValue(usage.Usage["cpu"], true)
+		usedMemory := quantityValue(usage.Usage["memory"], false)
+		out["cpu_used"] = formatCPU(usedCPU)
+		out["memory_used"] = formatMemory(usedMemory)
+		if allocCPU > 0 {
+			out["cpu_used_percent"] = usedCPU * 100 / allocCPU
+		}
+		if allocMemory > 0 {
+			out["memory_used_percent"] = usedMemory * 100 / allocMemory
+		}
+	}
+	return out, nil
+}
+
+// numberValue reads a JSON number; Longhorn and node-disk-manager report sizes as numbers, and some
+// versions as strings.
+func numberValue(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		var f float64
+		fmt.Sscan(n, &f)
+		return f
+	}
+	return 0
+}
@@ -15,7 +15,7 @@ const typeNodes = "node"
 func (t *Toolset) hostListTool() mcp.Tool {
 	return mcp.NewTool(
 		"harvester_host_list",
-		mcp.WithDescription("List Harvester hosts (nodes) with maintenance mode and disk status"),
+		mcp.WithDescription("List Harvester hosts (nodes) with maintenance mode and disk status; harvester_host_inventory has per-host hardware, disk and network details"),
 		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
 		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
 		mcp.WithNumber("limit", mcp.Description("Max items (default: 100)")),
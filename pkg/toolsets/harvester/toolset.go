@@ -27,23 +27,36 @@ func NewToolset(client *rancher.SteveClient, policy *security.Policy) *Toolset {
 func (t *Toolset) Register(s *server.MCPServer) {
 	s.AddTool(t.vmListTool(), t.vmListHandler)
 	s.AddTool(t.vmGetTool(), t.vmGetHandler)
+	s.AddTool(t.vmGuestInfoTool(), t.vmGuestInfoHandler)
+	s.AddTool(t.vmConsoleTool(), t.vmConsoleHandler)
+	s.AddTool(t.vmMigratableTool(), t.vmMigratableHandler)
+	s.AddTool(t.vmMigrationStatusTool(), t.vmMigrationStatusHandler)
 	s.AddTool(t.imageListTool(), t.imageListHandler)
 	s.AddTool(t.volumeListTool(), t.volumeListHandler)
 	s.AddTool(t.networkListTool(), t.networkListHandler)
 	s.AddTool(t.hostListTool(), t.hostListHandler)
+	s.AddTool(t.hostInventoryTool(), t.hostInventoryHandler)
+	s.AddTool(t.hostDrainPlanTool(), t.hostDrainPlanHandler)
+	s.AddTool(t.blockDeviceListTool(), t.blockDeviceListHandler)
 	s.AddTool(t.settingsTool(), t.settingsHandler)
 	s.AddTool(t.addonListTool(), t.addonListHandler)
 	s.AddTool(t.vpcListTool(), t.vpcListHandler)
 	s.AddTool(t.subnetListTool(), t.subnetListHandler)
+	s.AddTool(t.vmTemplateListTool(), t.vmTemplateListHandler)
 	if t.policy.CanWrite() {
 		s.AddTool(t.vmActionTool(), t.vmActionHandler)
+		s.AddTool(t.vmMigrateTool(), t.vmMigrateHandler)
 		s.AddTool(t.vmCreateTool(), t.vmCreateHandler)
+		s.AddTool(t.vmUpdateTool(), t.vmUpdateHandler)
+		s.AddTool(t.vmTemplateVersionCreateTool(), t.vmTemplateVersionCreateHandler)
+		s.AddTool(t.vmCreateFromTemplateTool(), t.vmCreateFromTemplateHandler)
 		s.AddTool(t.vmSnapshotTool(), t.vmSnapshotHandler)
 		s.AddTool(t.vmBackupTool(), t.vmBackupHandler)
 		s.AddTool(t.imageCreateTool(), t.imageCreateHandler)
 		s.AddTool(t.volumeCreateTool(), t.volumeCreateHandler)
 		s.AddTool(t.addonSwitchTool(), t.addonSwitchHandler)
 		s.AddTool(t.hostActionTool(), t.hostActionHandler)
+		s.AddTool(t.blockDeviceProvisionTool(), t.blockDeviceProvisionHandler)
 		s.AddTool(t.vpcCreateTool(), t.vpcCreateHandler)
 		s.AddTool(t.vpcUpdateTool(), t.vpcUpdateHandler)
 		s.AddTool(t.networkCreateTool(), t.networkCreateHandler)
@@ -52,6 +65,7 @@ func (t *Toolset) Register(s *server.MCPServer) {
 		s.AddTool(t.subnetUpdateTool(), t.subnetUpdateHandler)
 	}
 	if t.policy.CanDelete() {
+		s.AddTool(t.vmDeleteTool(), t.vmDeleteHandler)
 		s.AddTool(t.vpcDeleteTool(), t.vpcDeleteHandler)
 		s.AddTool(t.networkDeleteTool(), t.networkDeleteHandler)
 		s.AddTool(t.subnetDeleteTool(), t.subnetDeleteHandler)
@@ -2,22 +2,39 @@ package harvester
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
 )
 
 var vmActions = map[string]bool{"start": true, "stop": true, "restart": true, "pause": true, "unpause": true, "migrate": true}
 
+// vmActionWaitFor maps an action to the VM state to wait for when wait=true. restart waits for a new
+// VM instance instead, since the VM reports Running before the old instance is gone.
+var vmActionWaitFor = map[string]string{
+	"start":   "jsonpath={.status.printableStatus}=Running",
+	"unpause": "jsonpath={.status.printableStatus}=Running",
+	"stop":    "jsonpath={.status.printableStatus}=Stopped",
+	"pause":   "condition=Paused",
+}
+
+// vmiPollInterval is how often the VM instance is re-read while waiting for a restart.
+var vmiPollInterval = 2 * time.Second
+
 func (t *Toolset) vmActionTool() mcp.Tool {
 	return mcp.NewTool(
 		"harvester_vm_action",
-		mcp.WithDescription("Run lifecycle action on a VM: start, stop, restart, pause, unpause, migrate"),
+		mcp.WithDescription("Run lifecycle action on a VM: start, stop, restart, pause, unpause, migrate (use harvester_vm_migrate to pick a target node and track the migration)"),
 		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
 		mcp.WithString("action", mcp.Required(), mcp.Description("Action: start, stop, restart, pause, unpause, migrate")),
+		mcp.WithBoolean("wait", mcp.Description("Wait until the VM reaches the resulting state (Running, Stopped, Paused; not supported for migrate) (default: false)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Max seconds to wait when wait=true (default: 300, max: 1800)")),
 	)
 }
 
@@ -42,6 +59,18 @@ func (t *Toolset) vmActionHandler(ctx context.Context, req mcp.CallToolRequest)
 		return mcp.NewToolResultError(fmt.Sprintf("invalid action %q; allowed: start, stop, restart, pause, unpause, migrate", action)), nil
 	}
 
+	// Remember the running instance so that waiting for a restart can tell the new one apart.
+	var oldUID interface{}
+	if action == "restart" && req.GetBool("wait", false) {
+		vmi, err := t.getVMI(ctx, cluster, namespace, name)
+		switch {
+		case err == nil:
+			oldUID = nestedMap(vmi, "metadata")["uid"]
+		case !errors.Is(err, errNoVMI):
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
 	switch action {
 	case "stop":
 		// Patch runStrategy to Halted. Using the Steve ?action=stop on a VM with
@@ -87,5 +116,59 @@ func (t *Toolset) vmActionHandler(ctx context.Context, req mcp.CallToolRequest)
 		}
 	}
 
+	if action == "restart" && req.GetBool("wait", false) {
+		timeout := time.Duration(min(req.GetInt("timeout_seconds", 300), maxWaitTimeoutSeconds)) * time.Second
+		if timeout <= 0 {
+			timeout = 300 * time.Second
+		}
+		start := time.Now()
+		observed, err := t.waitRestarted(ctx, cluster, namespace, name, oldUID, timeout, wait.Progress(ctx, req, timeout))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q action %q submitted but: %v", name, action, err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("VM %q action %q completed (%s after %ds)", name, action, observed, int(time.Since(start).Seconds()))), nil
+	}
+	if forStr, ok := vmActionWaitFor[action]; ok && req.GetBool("wait", false) {
+		cond, _ := wait.ParseCondition(forStr)
+		timeout := time.Duration(min(req.GetInt("timeout_seconds", 300), maxWaitTimeoutSeconds)) * time.Second
+		if timeout <= 0 {
+			timeout = 300 * time.Second
+		}
+		target := wait.Target{Cluster: cluster, ResourceType: rancher.TypeVirtualMachines, Namespace: namespace, Name: name}
+		res, err := wait.Until(ctx, t.client, target, cond, timeout, wait.Progress(ctx, req, timeout))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q action %q submitted but: %v", name, action, err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("VM %q action %q completed (%s after %ds)", name, action, res.Observed, int(res.Elapsed.Seconds()))), nil
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("VM %q action %q completed", name, action)), nil
 }
+
+// waitRestarted waits until a VM runs a new instance: one whose UID differs from oldUID (nil when the VM
+// had no instance) and that is in phase Running.
+func (t *Toolset) waitRestarted(ctx context.Context, cluster, namespace, name string, oldUID interface{}, timeout time.Duration, progress wait.ProgressFunc) (string, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var observed string
+	for {
+		state := "no instance"
+		if vmi, err := t.getVMI(ctx, cluster, namespace, name); err == nil {
+			phase := nestedMap(vmi, "status")["phase"]
+			if nestedMap(vmi, "metadata")["uid"] == oldUID {
+				state = fmt.Sprintf("previous instance %v", phase)
+			} else if state = fmt.Sprintf("new instance %v", phase); phase == "Running" {
+				return state, nil
+			}
+		}
+		if state != observed && progress != nil {
+			progress(time.Since(start), state)
+		}
+		observed = state
+		select {
+		case <-ctx.Done():
+			return observed, fmt.Errorf("timed out after %s waiting for a new running instance (last observed: %s)", timeout, observed)
+		case <-time.After(vmiPollInterval):
+		}
+	}
+}
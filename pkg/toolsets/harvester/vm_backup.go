@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
 )
 
 func (t *Toolset) vmBackupTool() mcp.Tool {
@@ -20,6 +22,8 @@ func (t *Toolset) vmBackupTool() mcp.Tool {
 		mcp.WithString("backup_name", mcp.Description("Backup name (required for restore, optional for create)")),
 		mcp.WithString("format", mcp.Description("Output format for list: json, table (default: json)")),
 		mcp.WithNumber("limit", mcp.Description("Max items for list (default: 100)")),
+		mcp.WithBoolean("wait", mcp.Description("For create: wait until the backup is readyToUse (default: false)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Max seconds to wait when wait=true (default: 600, max: 1800)")),
 	)
 }
 
@@ -74,6 +78,19 @@ func (t *Toolset) vmBackupHandler(ctx context.Context, req mcp.CallToolRequest)
 			}
 			return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_backup create: %v", err)), nil
 		}
+		if req.GetBool("wait", false) {
+			cond, _ := wait.ParseCondition("jsonpath={.status.readyToUse}=true")
+			timeout := time.Duration(min(req.GetInt("timeout_seconds", 600), maxWaitTimeoutSeconds)) * time.Second
+			if timeout <= 0 {
+				timeout = 600 * time.Second
+			}
+			target := wait.Target{Cluster: cluster, ResourceType: rancher.TypeVirtualMachineBackups, Namespace: namespace, Name: name}
+			res, err := wait.Until(ctx, t.client, target, cond, timeout, wait.Progress(ctx, req, timeout))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Backup %q created for VM %q but: %v", name, vmName, err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Backup %q for VM %q in namespace %q is ready to use (after %ds)", name, vmName, namespace, int(res.Elapsed.Seconds()))), nil
+		}
 		return mcp.NewToolResultText(fmt.Sprintf("Backup %q created for VM %q in namespace %q", name, vmName, namespace)), nil
 
 	case "list":
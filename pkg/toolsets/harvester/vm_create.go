@@ -2,28 +2,47 @@ package harvester
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+// reservedMemoryAnnotation is the memory Harvester keeps back from the guest for VM overhead; the guest
+// memory is the limit minus this amount.
+const reservedMemoryAnnotation = "harvesterhci.io/reservedMemory"
+
 func (t *Toolset) vmCreateTool() mcp.Tool {
-	return mcp.NewTool(
-		"harvester_vm_create",
-		mcp.WithDescription("Create a Harvester VM. For KubeOVN VPC with external internet: use network (NAD name), interface_type=managedtap, and ensure the subnet has nat_outgoing=true."),
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Create a Harvester VM. For KubeOVN VPC with external internet: use network (NAD name), interface_type=managedtap, and ensure the subnet has nat_outgoing=true. Additional data disks (blank, existing volume or from image), CD-ROM ISOs and multiple NICs are given as JSON arrays. Only CPU/memory limits are set; Harvester derives requests from its overcommit settings, as with VMs created in the UI. Cloud-init user/network data (raw or from Harvester cloud-init templates) and KeyPair SSH keys are stored in a Secret owned by the VM, as the Harvester UI does."),
 		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace for the VM")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
 		mcp.WithNumber("cpu", mcp.Description("CPU cores (default: 2)")),
+		mcp.WithNumber("cpu_sockets", mcp.Description("CPU sockets (default: 1); vCPUs = cpu x cpu_sockets x cpu_threads")),
+		mcp.WithNumber("cpu_threads", mcp.Description("Threads per core (default: 1)")),
 		mcp.WithString("memory", mcp.Description("Memory size e.g. 2Gi, 4096Mi (default: 4Gi)")),
+		mcp.WithString("reserved_memory", mcp.Description("Memory reserved for VM overhead and not given to the guest, e.g. 256Mi (default: Harvester's default)")),
 		mcp.WithString("image", mcp.Required(), mcp.Description("VirtualMachineImage name (use harvester_image_list to list)")),
 		mcp.WithString("network", mcp.Description("Network name - NAD for KubeOVN overlay (use harvester_network_list; default: default pod network)")),
 		mcp.WithString("interface_type", mcp.Description("Interface: managedtap (KubeOVN, recommended), bridge, or masquerade (default: masquerade for pod, managedtap for custom network)")),
+		mcp.WithString("networks", mcp.Description(`Instead of network/interface_type: JSON array of NICs, first is primary, e.g. [{"network":"default"},{"network":"vlan100","interface_type":"bridge","mac":"52:54:00:12:34:56","model":"virtio"}]; network is a NAD name or namespace/name`)),
 		mcp.WithString("subnet", mcp.Description("KubeOVN logical_switch (subnet name) for IP assignment; optional, provider from network is used if unset")),
 		mcp.WithNumber("disk_size_gib", mcp.Description("Root disk size in GiB (default: 20)")),
+		mcp.WithString("disks", mcp.Description(`Additional disks as a JSON array: blank {"size_gib":100,"storage_class":"longhorn"}, from image {"image":"win-drivers","size_gib":10}, existing {"volume":"data-pvc"}, CD-ROM {"type":"cdrom","image":"ubuntu-iso"}; optional name, bus (virtio, sata, scsi), boot_order`)),
+		mcp.WithString("machine_type", mcp.Description("Machine type: q35 or pc (default: q35)")),
+		mcp.WithBoolean("efi", mcp.Description("Boot with UEFI firmware instead of BIOS (default: false)")),
+		mcp.WithBoolean("secure_boot", mcp.Description("Enable UEFI secure boot; requires efi (default: false)")),
+		mcp.WithBoolean("tpm", mcp.Description("Attach an emulated TPM 2.0 device (default: false)")),
+		mcp.WithString("hostname", mcp.Description("Guest hostname (default: VM name)")),
+		mcp.WithString("node_affinity", mcp.Description("Only schedule on nodes with these labels, key=value comma-separated (e.g. topology.kubernetes.io/zone=zone-a)")),
 		mcp.WithString("run_strategy", mcp.Description("RunStrategy: Always, RerunOnFailure, Halted (default: RerunOnFailure)")),
-	)
+	}
+	return mcp.NewTool("harvester_vm_create", append(opts, cloudInitOptions()...)...)
 }
 
 func (t *Toolset) vmCreateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -50,76 +69,138 @@ func (t *Toolset) vmCreateHandler(ctx context.Context, req mcp.CallToolRequest)
 	if cpu < 1 {
 		cpu = 1
 	}
+	sockets := max(req.GetInt("cpu_sockets", 1), 1)
+	threads := max(req.GetInt("cpu_threads", 1), 1)
 	memory := req.GetString("memory", "4Gi")
 	if memory == "" {
 		memory = "4Gi"
 	}
-	network := req.GetString("network", "default")
-	interfaceType := req.GetString("interface_type", "")
+	memQty, err := resource.ParseQuantity(memory)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid memory %q: %v", memory, err)), nil
+	}
+	reservedMemory := req.GetString("reserved_memory", "")
+	if reservedMemory != "" {
+		reserved, err := resource.ParseQuantity(reservedMemory)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid reserved_memory %q: %v", reservedMemory, err)), nil
+		}
+		if reserved.Cmp(memQty) >= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("reserved_memory %s must be less than memory %s", reservedMemory, memory)), nil
+		}
+	}
 	subnet := req.GetString("subnet", "")
 	diskSizeGiB := req.GetInt("disk_size_gib", 20)
 	if diskSizeGiB < 1 {
 		diskSizeGiB = 20
 	}
 	runStrategy := req.GetString("run_strategy", "RerunOnFailure")
-
-	// Look up the VirtualMachineImage to get the storageClassName assigned by Harvester.
-	// Harvester creates a per-image StorageClass (e.g. "longhorn-image-<id>") that the PVC
-	// must reference so Longhorn can clone the backing image into the new volume.
-	imgRes, err := t.client.Get(ctx, cluster, rancher.TypeVirtualMachineImages, namespace, image)
+	machineType := req.GetString("machine_type", "q35")
+	if !machineTypes[machineType] {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid machine_type %q; allowed: q35, pc", machineType)), nil
+	}
+	efi := req.GetBool("efi", false)
+	secureBoot := req.GetBool("secure_boot", false)
+	if secureBoot && !efi {
+		return mcp.NewToolResultError("secure_boot requires efi=true"), nil
+	}
+	hostname := req.GetString("hostname", "")
+	if hostname != "" {
+		if errs := validation.IsDNS1123Label(hostname); len(errs) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid hostname %q: %s", hostname, strings.Join(errs, "; "))), nil
+		}
+	} else if len(validation.IsDNS1123Label(name)) == 0 {
+		hostname = name
+	}
+	affinity, err := nodeAffinity(req.GetString("node_affinity", ""))
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("image %q not found in namespace %q: %v", image, namespace, err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	storageClassName := "longhorn" // safe fallback
-	if imgRes.Status != nil {
-		if statusMap, ok := imgRes.Status.(map[string]interface{}); ok {
-			if sc, ok := statusMap["storageClassName"].(string); ok && sc != "" {
-				storageClassName = sc
-			}
+	extraDisks, err := parseDiskSpecs(req.GetString("disks", ""), nil)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var nics []vmNICSpec
+	if raw := req.GetString("networks", ""); raw != "" {
+		if req.GetString("network", "") != "" || req.GetString("interface_type", "") != "" {
+			return mcp.NewToolResultError("set networks or network/interface_type, not both"), nil
+		}
+		if nics, err = parseNICSpecs(raw, nil); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		nics = []vmNICSpec{{Name: "default", Network: req.GetString("network", "default"), InterfaceType: req.GetString("interface_type", ""), Model: "virtio"}}
+		if nics[0].Network == "" {
+			nics[0].Network = "default"
 		}
 	}
 
-	// Create root disk PVC from the Harvester image.
-	// Harvester provisions volumes via a per-image Longhorn StorageClass (longhorn-image-<id>).
-	// The annotation harvesterhci.io/imageId tells the Harvester controller which image to clone;
-	// no dataSourceRef/VolumePopulator is needed or supported.
+	// Look up every image and existing volume, and resolve cloud-init, before creating anything.
+	storageClassName, _, err := t.vmImage(ctx, cluster, namespace, image)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	pvcName := name + "-disk-0"
-	pvc := map[string]interface{}{
-		"apiVersion": "v1",
-		"kind":       "PersistentVolumeClaim",
-		"metadata": map[string]interface{}{
-			"name":      pvcName,
-			"namespace": namespace,
-			"annotations": map[string]string{
-				"harvesterhci.io/imageId": fmt.Sprintf("%s/%s", namespace, image),
-			},
-		},
-		"spec": map[string]interface{}{
-			"accessModes": []string{"ReadWriteMany"},
-			"resources": map[string]interface{}{
-				"requests": map[string]interface{}{
-					"storage": fmt.Sprintf("%dGi", diskSizeGiB),
-				},
+	claims := []map[string]interface{}{vmVolumeClaim(pvcName, namespace, fmt.Sprintf("%s/%s", namespace, image), storageClassName, diskSizeGiB)}
+	disks := []map[string]interface{}{vmDisk("disk-0", "disk", "virtio", 1)}
+	volumes := []map[string]interface{}{
+		{
+			"name": "disk-0",
+			"persistentVolumeClaim": map[string]interface{}{
+				"claimName": pvcName,
 			},
-			"storageClassName": storageClassName,
-			"volumeMode":       "Block",
 		},
 	}
-	_, err = t.client.Create(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, pvc)
+	for _, d := range extraDisks {
+		claimName, claim, err := t.diskVolume(ctx, cluster, namespace, name, d)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if claim != nil {
+			claims = append(claims, claim)
+		}
+		disks = append(disks, vmDisk(d.Name, d.Type, d.Bus, d.BootOrder))
+		volumes = append(volumes, map[string]interface{}{
+			"name":                  d.Name,
+			"persistentVolumeClaim": map[string]interface{}{"claimName": claimName},
+		})
+	}
+	cloudInit, err := t.resolveCloudInit(ctx, cluster, namespace, req)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create root disk PVC from image %q: %v", image, err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Build interface and network based on network type.
-	// For KubeOVN VPC: use multus + managedtap; subnet needs nat_outgoing=true for external internet.
-	useKubeOVN := network != "" && network != "default"
-	if interfaceType == "" {
-		if useKubeOVN {
-			interfaceType = "managedtap"
-		} else {
-			interfaceType = "masquerade"
+	// Objects created so far are removed again if a later step fails.
+	var createdClaims []string
+	secretName := name + "-cloudinit"
+	secretCreated := false
+	rollback := func() {
+		for _, c := range createdClaims {
+			_ = t.client.Delete(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, c)
+		}
+		if secretCreated {
+			_ = t.client.Delete(ctx, cluster, rancher.TypeSecrets, namespace, secretName)
+		}
+	}
+	for _, pvc := range claims {
+		claimName := pvc["metadata"].(map[string]interface{})["name"].(string)
+		if _, err := t.client.Create(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, pvc); err != nil {
+			rollback()
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create volume %q: %v", claimName, err)), nil
 		}
+		createdClaims = append(createdClaims, claimName)
+	}
+
+	// Build interfaces and networks based on network type.
+	// For KubeOVN VPC: use multus + managedtap; subnet needs nat_outgoing=true for external internet.
+	var interfaces, networks []map[string]interface{}
+	for i := range nics {
+		iface, net := vmNIC(&nics[i], namespace, i == 0)
+		interfaces = append(interfaces, iface)
+		networks = append(networks, net)
 	}
+	useKubeOVN := nics[0].Network != "default"
+	interfaceType := nics[0].InterfaceType
 
 	templateMetadata := map[string]interface{}{
 		"labels": map[string]string{"harvesterhci.io/vmName": name},
@@ -140,72 +221,119 @@ func (t *Toolset) vmCreateHandler(ctx context.Context, req mcp.CallToolRequest)
 		}
 	}
 
-	iface := buildVMInterface("default", interfaceType)
-	net := buildVMNetwork("default", network, namespace)
+	vmAnnotations := map[string]string{}
+	if reservedMemory != "" {
+		vmAnnotations[reservedMemoryAnnotation] = reservedMemory
+	}
+	if cloudInit != nil {
+		disks = append(disks, vmDisk(cloudInitDiskName, "disk", "virtio", 0))
+		volumes = append(volumes, cloudInitVolume(secretName, cloudInit))
+		if len(cloudInit.SSHNames) > 0 {
+			sshNames, _ := json.Marshal(cloudInit.SSHNames)
+			vmAnnotations[sshNamesAnnotation] = string(sshNames)
+		}
+		// The Secret must exist before the VM starts; ownership is set once the VM has a UID.
+		if _, err := t.client.Create(ctx, cluster, rancher.TypeSecrets, namespace, cloudInitSecret(secretName, namespace, cloudInit)); err != nil {
+			rollback()
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create cloud-init secret %q: %v", secretName, err)), nil
+		}
+		secretCreated = true
+	}
+	vmMetadata := map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+	}
+	if len(vmAnnotations) > 0 {
+		vmMetadata["annotations"] = vmAnnotations
+	}
+
+	devices := map[string]interface{}{
+		"disks":      disks,
+		"interfaces": interfaces,
+	}
+	if req.GetBool("tpm", false) {
+		devices["tpm"] = map[string]interface{}{}
+	}
+	vcpus := cpu * sockets * threads
+	domain := map[string]interface{}{
+		"cpu": map[string]interface{}{
+			"cores":   uint32(cpu),
+			"sockets": uint32(sockets),
+			"threads": uint32(threads),
+		},
+		"devices": devices,
+		"machine": map[string]interface{}{"type": machineType},
+		// Requests and the guest memory are derived from the limits by Harvester's
+		// overcommit-config setting and reserved memory, as for VMs created in the UI.
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{
+				"memory": memory,
+				"cpu":    fmt.Sprintf("%d", vcpus),
+			},
+		},
+	}
+	if efi {
+		domain["firmware"] = map[string]interface{}{
+			"bootloader": map[string]interface{}{"efi": map[string]interface{}{"secureBoot": secureBoot}},
+		}
+		if secureBoot {
+			domain["features"] = map[string]interface{}{"smm": map[string]interface{}{"enabled": true}}
+		}
+	}
+	templateSpec := map[string]interface{}{
+		"domain":   domain,
+		"volumes":  volumes,
+		"networks": networks,
+	}
+	if hostname != "" {
+		templateSpec["hostname"] = hostname
+	}
+	if affinity != nil {
+		templateSpec["affinity"] = affinity
+	}
 
 	spec := map[string]interface{}{
 		"runStrategy": runStrategy,
 		"template": map[string]interface{}{
 			"metadata": templateMetadata,
-			"spec": map[string]interface{}{
-				"domain": map[string]interface{}{
-					"cpu": map[string]interface{}{
-						"cores": uint32(cpu),
-					},
-					"memory": map[string]interface{}{
-						"guest": memory,
-					},
-					"devices": map[string]interface{}{
-						"disks": []map[string]interface{}{
-							{
-								"name":      "disk-0",
-								"disk":      map[string]interface{}{"bus": "virtio"},
-								"bootOrder": 1,
-							},
-						},
-						"interfaces": []map[string]interface{}{iface},
-					},
-					"machine": map[string]interface{}{"type": "q35"},
-					"resources": map[string]interface{}{
-						"requests": map[string]interface{}{
-							"memory": memory,
-							"cpu":    fmt.Sprintf("%d", cpu),
-						},
-						"limits": map[string]interface{}{
-							"memory": memory,
-							"cpu":    fmt.Sprintf("%d", cpu),
-						},
-					},
-				},
-				"volumes": []map[string]interface{}{
-					{
-						"name": "disk-0",
-						"persistentVolumeClaim": map[string]interface{}{
-							"claimName": pvcName,
-						},
-					},
-				},
-				"networks": []map[string]interface{}{net},
-			},
+			"spec":     templateSpec,
 		},
 	}
 
 	vm := map[string]interface{}{
 		"apiVersion": "kubevirt.io/v1",
 		"kind":       "VirtualMachine",
-		"metadata": map[string]interface{}{
-			"name":      name,
-			"namespace": namespace,
-		},
-		"spec": spec,
+		"metadata":   vmMetadata,
+		"spec":       spec,
 	}
 
-	_, err = t.client.Create(ctx, cluster, rancher.TypeVirtualMachines, namespace, vm)
+	created, err := t.client.Create(ctx, cluster, rancher.TypeVirtualMachines, namespace, vm)
 	if err != nil {
+		rollback()
 		return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_create: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("VM %q created in namespace %q with root disk %q from image %q (%dGi)", name, namespace, pvcName, image, diskSizeGiB)), nil
+	msg := fmt.Sprintf("VM %q created in namespace %q with root disk %q from image %q (%dGi)", name, namespace, pvcName, image, diskSizeGiB)
+	if len(extraDisks) > 0 {
+		names := make([]string, 0, len(extraDisks))
+		for _, d := range extraDisks {
+			names = append(names, d.Name)
+		}
+		msg += fmt.Sprintf(", additional disks %s", strings.Join(names, ", "))
+	}
+	if len(nics) > 1 {
+		msg += fmt.Sprintf(", %d NICs", len(nics))
+	}
+	if cloudInit != nil {
+		msg += fmt.Sprintf(", cloud-init secret %q", secretName)
+		if len(cloudInit.SSHNames) > 0 {
+			msg += fmt.Sprintf(" (SSH keys: %s)", strings.Join(cloudInit.SSHNames, ", "))
+		}
+		if err := t.setSecretOwner(ctx, cluster, namespace, secretName, "kubevirt.io/v1", "VirtualMachine", created); err != nil {
+			msg += fmt.Sprintf("; warning: could not set the VM as owner of the secret, delete it with the VM: %v", err)
+		}
+	}
+	return mcp.NewToolResultText(msg), nil
 }
 
 // buildVMInterface returns the interface spec for the given type.
@@ -226,6 +354,7 @@ func buildVMInterface(name, ifaceType string) map[string]interface{} {
 }
 
 // buildVMNetwork returns the network spec. For custom networks, uses multus with default=true.
+// network may be a NAD name in namespace or namespace/name.
 func buildVMNetwork(name, network, namespace string) map[string]interface{} {
 	if network == "" || network == "default" {
 		return map[string]interface{}{
@@ -234,6 +363,9 @@ func buildVMNetwork(name, network, namespace string) map[string]interface{} {
 		}
 	}
 	networkName := fmt.Sprintf("%s/%s", namespace, network)
+	if strings.Contains(network, "/") {
+		networkName = network
+	}
 	return map[string]interface{}{
 		"name": name,
 		"multus": map[string]interface{}{
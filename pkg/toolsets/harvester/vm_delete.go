@@ -0,0 +1,245 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+// removedPVCsAnnotation lists the PVCs Harvester's VM controller deletes once the VM is gone; the Harvester
+// UI sets it from the volumes ticked in the delete dialog (the removedDisks action parameter).
+const removedPVCsAnnotation = "harvesterhci.io/removedPersistentVolumeClaims"
+
+// vmVolume is a volume-backed disk of a VM.
+type vmVolume struct {
+	Disk  string
+	Claim string
+}
+
+func (t *Toolset) vmDeleteTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_delete",
+		mcp.WithDescription("Delete a Harvester VM and the chosen volumes, as the Harvester UI does. Volumes that in-cluster snapshots depend on are kept unless snapshots=delete; backups on the backup target are never deleted. Use dry_run to see the volumes, snapshots and backups first."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithString("remove_volumes", mcp.Required(), mcp.Description("Volumes to delete with the VM: all, none, or comma-separated disk or volume names (others are kept)")),
+		mcp.WithString("snapshots", mcp.Description("keep: keep volumes that VM snapshots or volume snapshots depend on; delete: delete the VM's snapshots and the volume snapshots of removed volumes too (default: keep)")),
+		mcp.WithBoolean("dry_run", mcp.Description("Only report what would be deleted (default: false)")),
+	)
+}
+
+func (t *Toolset) vmDeleteHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckDestructive(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	removeArg, err := req.RequireString("remove_volumes")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	snapshots := req.GetString("snapshots", "keep")
+	if snapshots != "keep" && snapshots != "delete" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid snapshots %q; allowed: keep, delete", snapshots)), nil
+	}
+	dryRun := req.GetBool("dry_run", false)
+
+	vm, err := t.getVMObject(ctx, cluster, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	attached := vmVolumes(vm)
+	var remove, keep []vmVolume
+	switch removeArg {
+	case "all":
+		remove = attached
+	case "none":
+		keep = attached
+	default:
+		wanted := splitList(removeArg)
+		for _, w := range wanted {
+			if !slices.ContainsFunc(attached, func(v vmVolume) bool { return v.Disk == w || v.Claim == w }) {
+				return mcp.NewToolResultError(fmt.Sprintf("VM %q has no volume or disk %q; attached: %s", name, w, formatVolumes(attached))), nil
+			}
+		}
+		for _, v := range attached {
+			if slices.Contains(wanted, v.Disk) || slices.Contains(wanted, v.Claim) {
+				remove = append(remove, v)
+			} else {
+				keep = append(keep, v)
+			}
+		}
+	}
+
+	// Find what depends on the VM and its volumes. Without a complete picture nothing is deleted.
+	var backups, snapshotBackups, vmSnapshots []string
+	col, err := t.client.List(ctx, cluster, rancher.TypeVirtualMachineBackups, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list VM backups (needed to find what depends on VM %q): %v", name, err)), nil
+	}
+	for _, r := range col.Data {
+		spec, _ := r.Spec.(map[string]interface{})
+		if nestedMap(spec, "source")["name"] != name {
+			continue
+		}
+		if spec["type"] == "snapshot" {
+			snapshotBackups = append(snapshotBackups, r.ObjectMeta.Name)
+		} else {
+			backups = append(backups, r.ObjectMeta.Name)
+		}
+	}
+	col, err = t.client.List(ctx, cluster, rancher.TypeVirtualMachineSnapshots, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list VM snapshots (needed to find what depends on VM %q): %v", name, err)), nil
+	}
+	for _, r := range col.Data {
+		spec, _ := r.Spec.(map[string]interface{})
+		if nestedMap(spec, "source")["name"] == name {
+			vmSnapshots = append(vmSnapshots, r.ObjectMeta.Name)
+		}
+	}
+	volumeSnapshots := map[string][]string{}
+	col, err = t.client.List(ctx, cluster, rancher.TypeVolumeSnapshots, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list volume snapshots (needed to find what depends on VM %q's volumes): %v", name, err)), nil
+	}
+	for _, r := range col.Data {
+		spec, _ := r.Spec.(map[string]interface{})
+		if claim, _ := nestedMap(spec, "source")["persistentVolumeClaimName"].(string); claim != "" {
+			volumeSnapshots[claim] = append(volumeSnapshots[claim], r.ObjectMeta.Name)
+		}
+	}
+
+	var b strings.Builder
+	var keptForSnapshots []string
+	if snapshots == "keep" {
+		remove = slices.DeleteFunc(remove, func(v vmVolume) bool {
+			if snaps := volumeSnapshots[v.Claim]; len(snaps) > 0 {
+				keptForSnapshots = append(keptForSnapshots, fmt.Sprintf("%s (snapshots: %s)", v.Claim, strings.Join(snaps, ", ")))
+				keep = append(keep, v)
+				return true
+			}
+			return false
+		})
+	}
+	var deleteVolumeSnapshots []string
+	if snapshots == "delete" {
+		for _, v := range remove {
+			deleteVolumeSnapshots = append(deleteVolumeSnapshots, volumeSnapshots[v.Claim]...)
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(&b, "Dry run: nothing was deleted. Deleting VM %q would:\n", name)
+	} else {
+		if snapshots == "delete" {
+			for _, s := range snapshotBackups {
+				if err := t.client.Delete(ctx, cluster, rancher.TypeVirtualMachineBackups, namespace, s); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_delete: deleting snapshot %q: %v (the VM was not deleted)", s, err)), nil
+				}
+			}
+			for _, s := range vmSnapshots {
+				if err := t.client.Delete(ctx, cluster, rancher.TypeVirtualMachineSnapshots, namespace, s); err != nil && !strings.Contains(err.Error(), "404") {
+					return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_delete: deleting VM snapshot %q: %v (the VM was not deleted)", s, err)), nil
+				}
+			}
+			// Volume snapshots owned by the VM snapshots above may already be gone.
+			for _, s := range deleteVolumeSnapshots {
+				if err := t.client.Delete(ctx, cluster, rancher.TypeVolumeSnapshots, namespace, s); err != nil && !strings.Contains(err.Error(), "404") {
+					return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_delete: deleting volume snapshot %q: %v (the VM was not deleted)", s, err)), nil
+				}
+			}
+		}
+		// Harvester removes the listed volumes after the VM and its pod are gone.
+		claims := make([]string, 0, len(remove))
+		for _, v := range remove {
+			claims = append(claims, v.Claim)
+		}
+		if len(claims) > 0 {
+			patch, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{removedPVCsAnnotation: strings.Join(claims, ",")}}})
+			path, _ := rancher.ResourcePath(rancher.TypeVirtualMachines, namespace, name)
+			body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeMerge)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_delete: %v", err)), nil
+			}
+			if status < 200 || status >= 300 {
+				return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_delete: marking volumes for removal: %d %s", status, apiMessage(body))), nil
+			}
+		}
+		if err := t.client.Delete(ctx, cluster, rancher.TypeVirtualMachines, namespace, name); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_delete: %v", err)), nil
+		}
+		fmt.Fprintf(&b, "VM %q deleted from namespace %q\n", name, namespace)
+	}
+
+	verb := func(dry, done string) string {
+		if dryRun {
+			return dry
+		}
+		return done
+	}
+	fmt.Fprintf(&b, "%s: %s\n", verb("Remove volumes", "Volumes removed once the VM is gone"), formatVolumes(remove))
+	fmt.Fprintf(&b, "%s: %s\n", verb("Keep volumes", "Volumes kept"), formatVolumes(keep))
+	if len(keptForSnapshots) > 0 {
+		fmt.Fprintf(&b, "Kept because snapshots depend on them (use snapshots=delete to remove): %s\n", strings.Join(keptForSnapshots, "; "))
+	}
+	if snapshots == "delete" {
+		fmt.Fprintf(&b, "%s: %s\n", verb("Delete snapshots", "Snapshots deleted"), listOrNone(slices.Concat(snapshotBackups, vmSnapshots)))
+		fmt.Fprintf(&b, "%s: %s\n", verb("Delete volume snapshots", "Volume snapshots deleted"), listOrNone(deleteVolumeSnapshots))
+	} else if len(snapshotBackups)+len(vmSnapshots) > 0 {
+		fmt.Fprintf(&b, "VM snapshots kept: %s\n", strings.Join(slices.Concat(snapshotBackups, vmSnapshots), ", "))
+	}
+	if len(backups) > 0 {
+		fmt.Fprintf(&b, "Backups on the backup target are kept and can restore the VM: %s\n", strings.Join(backups, ", "))
+	}
+	return mcp.NewToolResultText(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// vmVolumes returns the volume-backed disks of a VirtualMachine object.
+func vmVolumes(vm map[string]interface{}) []vmVolume {
+	volumes, _ := nestedMap(vm, "spec", "template", "spec")["volumes"].([]interface{})
+	var out []vmVolume
+	for _, v := range volumes {
+		vol, _ := v.(map[string]interface{})
+		claim, _ := nestedMap(vol, "persistentVolumeClaim")["claimName"].(string)
+		if claim == "" {
+			claim, _ = nestedMap(vol, "dataVolume")["name"].(string)
+		}
+		if claim != "" {
+			out = append(out, vmVolume{Disk: entryName(vol), Claim: claim})
+		}
+	}
+	return out
+}
+
+func formatVolumes(vols []vmVolume) string {
+	names := make([]string, 0, len(vols))
+	for _, v := range vols {
+		names = append(names, fmt.Sprintf("%s (disk %s)", v.Claim, v.Disk))
+	}
+	return listOrNone(names)
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
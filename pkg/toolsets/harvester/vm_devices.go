@@ -0,0 +1,328 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	diskBuses    = map[string]bool{"virtio": true, "sata": true, "scsi": true}
+	nicModels    = map[string]bool{"virtio": true, "e1000": true, "e1000e": true, "rtl8139": true}
+	nicBindings  = map[string]bool{"managedtap": true, "bridge": true, "masquerade": true}
+	machineTypes = map[string]bool{"q35": true, "pc": true}
+)
+
+// vmDiskSpec is one entry of the disks argument: a new blank volume (size_gib), a volume cloned from an
+// image (image), an existing PVC (volume), or a CD-ROM (type=cdrom with image or volume).
+type vmDiskSpec struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	SizeGiB      int    `json:"size_gib"`
+	Image        string `json:"image"`
+	Volume       string `json:"volume"`
+	StorageClass string `json:"storage_class"`
+	Bus          string `json:"bus"`
+	BootOrder    int    `json:"boot_order"`
+}
+
+// vmNICSpec is one entry of the networks argument.
+type vmNICSpec struct {
+	Name          string `json:"name"`
+	Network       string `json:"network"`
+	InterfaceType string `json:"interface_type"`
+	MAC           string `json:"mac"`
+	Model         string `json:"model"`
+}
+
+// parseDiskSpecs decodes and validates the disks argument. Unnamed disks are named disk-1, disk-2, ...
+// (disk-0 is the root disk) and CD-ROMs cdrom-1, cdrom-2, ..., skipping the names in existing.
+func parseDiskSpecs(raw string, existing map[string]bool) ([]vmDiskSpec, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var disks []vmDiskSpec
+	if err := json.Unmarshal([]byte(raw), &disks); err != nil {
+		return nil, fmt.Errorf("disks must be a JSON array of disk objects: %v", err)
+	}
+	seen := map[string]bool{"disk-0": true, cloudInitDiskName: true}
+	for n := range existing {
+		seen[n] = true
+	}
+	diskN, cdromN := 0, 0
+	for i := range disks {
+		d := &disks[i]
+		switch d.Type {
+		case "", "disk":
+			d.Type = "disk"
+			if d.Name == "" {
+				d.Name = nextName("disk", &diskN, seen)
+			}
+			if d.Bus == "" {
+				d.Bus = "virtio"
+			}
+		case "cdrom":
+			if d.Name == "" {
+				d.Name = nextName("cdrom", &cdromN, seen)
+			}
+			if d.Bus == "" {
+				d.Bus = "sata"
+			}
+			if d.Bus == "virtio" {
+				return nil, fmt.Errorf("disk %q: CD-ROMs cannot use the virtio bus; use sata or scsi", d.Name)
+			}
+			if d.Image == "" && d.Volume == "" {
+				return nil, fmt.Errorf("disk %q: a CD-ROM needs an image or volume", d.Name)
+			}
+		default:
+			return nil, fmt.Errorf("disk %d: invalid type %q; allowed: disk, cdrom", i, d.Type)
+		}
+		if errs := validation.IsDNS1123Label(d.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("disk name %q: %s", d.Name, strings.Join(errs, "; "))
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("duplicate or reserved disk name %q", d.Name)
+		}
+		seen[d.Name] = true
+		if !diskBuses[d.Bus] {
+			return nil, fmt.Errorf("disk %q: invalid bus %q; allowed: virtio, sata, scsi", d.Name, d.Bus)
+		}
+		sources := 0
+		for _, set := range []bool{d.Image != "", d.Volume != ""} {
+			if set {
+				sources++
+			}
+		}
+		switch {
+		case sources > 1:
+			return nil, fmt.Errorf("disk %q: set image or volume, not both", d.Name)
+		case sources == 0 && d.SizeGiB < 1:
+			return nil, fmt.Errorf("disk %q: a new blank disk needs size_gib >= 1", d.Name)
+		case d.Volume != "" && (d.SizeGiB != 0 || d.StorageClass != ""):
+			return nil, fmt.Errorf("disk %q: size_gib and storage_class do not apply to an existing volume", d.Name)
+		case d.Image != "" && d.StorageClass != "":
+			return nil, fmt.Errorf("disk %q: volumes from an image use the image's storage class", d.Name)
+		}
+		if d.BootOrder < 0 {
+			return nil, fmt.Errorf("disk %q: boot_order must be >= 1", d.Name)
+		}
+	}
+	return disks, nil
+}
+
+// nextName returns the first prefix-N after *n that is not in seen, and advances *n to it.
+func nextName(prefix string, n *int, seen map[string]bool) string {
+	for {
+		*n++
+		if name := fmt.Sprintf("%s-%d", prefix, *n); !seen[name] {
+			return name
+		}
+	}
+}
+
+// parseNICSpecs decodes and validates the networks argument. The first NIC is named default, the others nic-1, nic-2, ...
+// When existing (the names of a VM's current NICs) is set, all entries are secondary NICs added to it.
+func parseNICSpecs(raw string, existing map[string]bool) ([]vmNICSpec, error) {
+	var nics []vmNICSpec
+	if err := json.Unmarshal([]byte(raw), &nics); err != nil {
+		return nil, fmt.Errorf("networks must be a JSON array of network objects: %v", err)
+	}
+	if len(nics) == 0 {
+		return nil, fmt.Errorf("networks must contain at least one network")
+	}
+	seen := map[string]bool{}
+	for n := range existing {
+		seen[n] = true
+	}
+	nicN := 0
+	for i := range nics {
+		n := &nics[i]
+		primary := i == 0 && len(existing) == 0
+		if n.Name == "" {
+			n.Name = "default"
+			if !primary {
+				n.Name = nextName("nic", &nicN, seen)
+			}
+		}
+		if n.Network == "" {
+			n.Network = "default"
+		}
+		if seen[n.Name] {
+			return nil, fmt.Errorf("duplicate network name %q", n.Name)
+		}
+		seen[n.Name] = true
+		if n.Network == "default" && !primary {
+			return nil, fmt.Errorf("network %q: only the first NIC can use the default pod network", n.Name)
+		}
+		if n.InterfaceType != "" && !nicBindings[n.InterfaceType] {
+			return nil, fmt.Errorf("network %q: invalid interface_type %q; allowed: managedtap, bridge, masquerade", n.Name, n.InterfaceType)
+		}
+		if n.Model == "" {
+			n.Model = "virtio"
+		}
+		if !nicModels[n.Model] {
+			return nil, fmt.Errorf("network %q: invalid model %q; allowed: virtio, e1000, e1000e, rtl8139", n.Name, n.Model)
+		}
+		if n.MAC != "" {
+			if _, err := net.ParseMAC(n.MAC); err != nil {
+				return nil, fmt.Errorf("network %q: invalid mac %q", n.Name, n.MAC)
+			}
+		}
+	}
+	return nics, nil
+}
+
+// vmImage looks up a VirtualMachineImage and returns the per-image StorageClass Harvester assigned to it
+// and its size in GiB (rounded up; 0 when not yet known).
+// Harvester creates a per-image StorageClass (e.g. "longhorn-image-<id>") that the PVC
+// must reference so Longhorn can clone the backing image into the new volume.
+func (t *Toolset) vmImage(ctx context.Context, cluster, namespace, image string) (string, int, error) {
+	imgRes, err := t.client.Get(ctx, cluster, rancher.TypeVirtualMachineImages, namespace, image)
+	if err != nil {
+		return "", 0, fmt.Errorf("image %q not found in namespace %q: %v", image, namespace, err)
+	}
+	storageClassName := "longhorn" // safe fallback
+	sizeGiB := 0
+	if statusMap, ok := imgRes.Status.(map[string]interface{}); ok {
+		if sc, ok := statusMap["storageClassName"].(string); ok && sc != "" {
+			storageClassName = sc
+		}
+		for _, key := range []string{"virtualSize", "size"} {
+			if bytes, ok := statusMap[key].(float64); ok && bytes > 0 {
+				sizeGiB = int((int64(bytes) + 1<<30 - 1) >> 30)
+				break
+			}
+		}
+	}
+	return storageClassName, sizeGiB, nil
+}
+
+// diskVolume resolves a disk of VM vmName to its volume claim name and, unless it uses an existing
+// volume, the PVC to create (<vm>-<disk name>).
+func (t *Toolset) diskVolume(ctx context.Context, cluster, namespace, vmName string, d vmDiskSpec) (string, map[string]interface{}, error) {
+	switch {
+	case d.Volume != "":
+		if _, err := t.client.Get(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, d.Volume); err != nil {
+			return "", nil, fmt.Errorf("disk %q: volume %q not found: %v", d.Name, d.Volume, err)
+		}
+		return d.Volume, nil, nil
+	case d.Image != "":
+		imgNamespace, imgName := splitRef(namespace, d.Image)
+		if err := t.policy.CheckNamespace(imgNamespace); err != nil {
+			return "", nil, fmt.Errorf("disk %q: %v", d.Name, err)
+		}
+		sc, imageSize, err := t.vmImage(ctx, cluster, imgNamespace, imgName)
+		if err != nil {
+			return "", nil, fmt.Errorf("disk %q: %v", d.Name, err)
+		}
+		size := d.SizeGiB
+		if size < 1 {
+			if size = imageSize; size < 1 {
+				return "", nil, fmt.Errorf("disk %q: size of image %q is not known yet; set size_gib", d.Name, d.Image)
+			}
+		}
+		claimName := vmName + "-" + d.Name
+		return claimName, vmVolumeClaim(claimName, namespace, imgNamespace+"/"+imgName, sc, size), nil
+	default:
+		sc := d.StorageClass
+		if sc == "" {
+			sc = "longhorn"
+		}
+		claimName := vmName + "-" + d.Name
+		return claimName, vmVolumeClaim(claimName, namespace, "", sc, d.SizeGiB), nil
+	}
+}
+
+// vmNIC returns the interface and network entries of a NIC, defaulting its interface type to masquerade on
+// the pod network and managedtap on a custom network. Only the primary NIC may replace the pod network.
+func vmNIC(n *vmNICSpec, namespace string, primary bool) (map[string]interface{}, map[string]interface{}) {
+	if n.InterfaceType == "" {
+		if n.Network != "default" {
+			n.InterfaceType = "managedtap"
+		} else {
+			n.InterfaceType = "masquerade"
+		}
+	}
+	iface := buildVMInterface(n.Name, n.InterfaceType)
+	iface["model"] = n.Model
+	if n.MAC != "" {
+		iface["macAddress"] = n.MAC
+	}
+	net := buildVMNetwork(n.Name, n.Network, namespace)
+	if multus, ok := net["multus"].(map[string]interface{}); ok && !primary {
+		delete(multus, "default")
+	}
+	return iface, net
+}
+
+// vmVolumeClaim returns a PVC for a VM disk: a clone of imageID (namespace/name) when set, otherwise a blank
+// volume. The annotation harvesterhci.io/imageId tells the Harvester controller which image to clone;
+// no dataSourceRef/VolumePopulator is needed or supported. Both are RWX block volumes so the VM stays live-migratable.
+func vmVolumeClaim(name, namespace, imageID, storageClass string, sizeGiB int) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+	}
+	if imageID != "" {
+		metadata["annotations"] = map[string]string{"harvesterhci.io/imageId": imageID}
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolumeClaim",
+		"metadata":   metadata,
+		"spec": map[string]interface{}{
+			"accessModes": []string{"ReadWriteMany"},
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{
+					"storage": fmt.Sprintf("%dGi", sizeGiB),
+				},
+			},
+			"storageClassName": storageClass,
+			"volumeMode":       "Block",
+		},
+	}
+}
+
+// vmDisk returns the domain device entry for a disk or CD-ROM.
+func vmDisk(name, diskType, bus string, bootOrder int) map[string]interface{} {
+	out := map[string]interface{}{"name": name}
+	if diskType == "cdrom" {
+		out["cdrom"] = map[string]interface{}{"bus": bus}
+	} else {
+		out["disk"] = map[string]interface{}{"bus": bus}
+	}
+	if bootOrder > 0 {
+		out["bootOrder"] = bootOrder
+	}
+	return out
+}
+
+// nodeAffinity builds a required node affinity from "key=value,key2=value2" (all must match).
+func nodeAffinity(selector string) (map[string]interface{}, error) {
+	var exprs []map[string]interface{}
+	for _, part := range strings.Split(selector, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("node_affinity %q is not key=value", part)
+		}
+		exprs = append(exprs, map[string]interface{}{"key": key, "operator": "In", "values": []string{value}})
+	}
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"nodeAffinity": map[string]interface{}{
+			"requiredDuringSchedulingIgnoredDuringExecution": map[string]interface{}{
+				"nodeSelectorTerms": []map[string]interface{}{{"matchExpressions": exprs}},
+			},
+		},
+	}, nil
+}
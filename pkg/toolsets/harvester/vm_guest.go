@@ -0,0 +1,235 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+const (
+	consoleDefaultSeconds = 5
+	consoleMaxSeconds     = 60
+	consoleDefaultBytes   = 16 << 10
+	consoleMaxBytes       = 256 << 10
+)
+
+// guestAgentSubresources are the KubeVirt subresources answered by the QEMU guest agent.
+var guestAgentSubresources = []string{"guestosinfo", "filesystemlist", "userlist"}
+
+// ansiEscape matches terminal control sequences (CSI and OSC) in console output.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-_]`)
+
+func (t *Toolset) vmGuestInfoTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_guest_info",
+		mcp.WithDescription("Guest-level information of a running VM from the QEMU guest agent: OS info, filesystems, logged-in users, and all interface IPs. Requires the qemu-guest-agent in the guest."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) vmConsoleTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_console",
+		mcp.WithDescription("Capture a running VM's serial console for a few seconds and return the last max_bytes of output (terminal escape sequences removed), e.g. to see boot errors of a VM that won't boot. Only output produced while connected is captured."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithNumber("duration_seconds", mcp.Description(fmt.Sprintf("How long to capture (default: %d, max: %d)", consoleDefaultSeconds, consoleMaxSeconds))),
+		mcp.WithNumber("max_bytes", mcp.Description(fmt.Sprintf("Return at most the last N bytes (default: %d, max: %d)", consoleDefaultBytes, consoleMaxBytes))),
+		mcp.WithBoolean("send_enter", mcp.Description("Send Enter after connecting to make the guest print a prompt; requires write access (default: false)")),
+	)
+}
+
+func (t *Toolset) vmGuestInfoHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	format := req.GetString("format", "json")
+
+	vmi, err := t.getVMI(ctx, cluster, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	status := nestedMap(vmi, "status")
+	var interfaces []map[string]interface{}
+	ifaces, _ := status["interfaces"].([]interface{})
+	for _, i := range ifaces {
+		m, _ := i.(map[string]interface{})
+		ips := m["ipAddresses"]
+		if ips == nil && m["ipAddress"] != nil {
+			ips = []interface{}{m["ipAddress"]}
+		}
+		interfaces = append(interfaces, map[string]interface{}{
+			"name":           m["name"],
+			"interface_name": m["interfaceName"],
+			"mac":            m["mac"],
+			"ip_addresses":   ips,
+			"source":         m["infoSource"],
+		})
+	}
+	data := map[string]interface{}{
+		"name":            name,
+		"namespace":       namespace,
+		"phase":           status["phase"],
+		"node":            status["nodeName"],
+		"agent_connected": hasCondition(vmi, "AgentConnected"),
+		"interfaces":      interfaces,
+	}
+	if !hasCondition(vmi, "AgentConnected") {
+		data["message"] = "The QEMU guest agent is not connected; install and start qemu-guest-agent in the guest. Interface IPs may be incomplete."
+	} else {
+		for _, sub := range guestAgentSubresources {
+			path := fmt.Sprintf("/apis/subresources.kubevirt.io/v1/namespaces/%s/virtualmachineinstances/%s/%s", namespace, name, sub)
+			body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+			var value interface{}
+			switch {
+			case err != nil:
+				value = map[string]interface{}{"error": err.Error()}
+			case code != http.StatusOK:
+				value = map[string]interface{}{"error": fmt.Sprintf("%d %s", code, apiMessage(body))}
+			default:
+				if err := json.Unmarshal(body, &value); err != nil {
+					value = map[string]interface{}{"error": err.Error()}
+				}
+			}
+			data[guestInfoKey(sub)] = value
+		}
+	}
+	out, err := t.formatter.Format(data, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+func (t *Toolset) vmConsoleHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sendEnter := req.GetBool("send_enter", false)
+	if sendEnter {
+		if err := t.policy.CheckWrite(); err != nil {
+			return mcp.NewToolResultError("send_enter types into the console: " + err.Error()), nil
+		}
+	}
+	seconds := req.GetInt("duration_seconds", consoleDefaultSeconds)
+	if seconds <= 0 {
+		seconds = consoleDefaultSeconds
+	}
+	seconds = min(seconds, consoleMaxSeconds)
+	maxBytes := req.GetInt("max_bytes", consoleDefaultBytes)
+	if maxBytes <= 0 {
+		maxBytes = consoleDefaultBytes
+	}
+	maxBytes = min(maxBytes, consoleMaxBytes)
+
+	vmi, err := t.getVMI(ctx, cluster, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if phase := nestedMap(vmi, "status")["phase"]; phase != "Running" {
+		return mcp.NewToolResultError(fmt.Sprintf("VM %q is %v, not Running; the serial console is only available while it runs", name, phase)), nil
+	}
+	res, err := t.client.SerialConsole(ctx, cluster, namespace, name, rancher.ConsoleOptions{
+		Duration:  time.Duration(seconds) * time.Second,
+		MaxBytes:  maxBytes,
+		SendEnter: sendEnter,
+	})
+	if err != nil && res == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_console: %v", err)), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Serial console of VM %q, %d bytes in %ds", name, res.Bytes, seconds)
+	if res.Truncated {
+		fmt.Fprintf(&b, " (showing the last %d bytes)", maxBytes)
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "; connection ended early: %v", err)
+	}
+	b.WriteString(":\n")
+	if res.Bytes == 0 {
+		b.WriteString("(no output; the guest printed nothing while connected. Try send_enter=true, a longer duration_seconds, or restart the VM and capture during boot)")
+	} else {
+		b.WriteString(cleanConsole(res.Output))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// getVMI reads the VirtualMachineInstance of a VM; it only exists while the VM is scheduled or running.
+// errNoVMI is returned by getVMI when the VM has no VirtualMachineInstance.
+var errNoVMI = errors.New("has no running instance (stopped or does not exist)")
+
+func (t *Toolset) getVMI(ctx context.Context, cluster, namespace, name string) (map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachineInstances, namespace, name)
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("VM %q: %v", name, err)
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("VM %q in namespace %q %w", name, namespace, errNoVMI)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("VM %q: %d %s", name, status, apiMessage(body))
+	}
+	var vmi map[string]interface{}
+	if err := json.Unmarshal(body, &vmi); err != nil {
+		return nil, fmt.Errorf("VM %q: %v", name, err)
+	}
+	return vmi, nil
+}
+
+func guestInfoKey(sub string) string {
+	switch sub {
+	case "guestosinfo":
+		return "os"
+	case "filesystemlist":
+		return "filesystems"
+	case "userlist":
+		return "users"
+	}
+	return sub
+}
+
+// cleanConsole removes terminal escape sequences, carriage returns and other control characters from console
+// output, and drops invalid UTF-8 (e.g. a multi-byte character cut by truncation).
+func cleanConsole(out []byte) string {
+	s := ansiEscape.ReplaceAllString(strings.ToValidUTF8(string(out), ""), "")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r < 0x20 || r == 0x7f:
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,549 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// migrationTargetAnnotation on a VirtualMachineInstanceMigration makes Harvester schedule the target
+// virt-launcher pod on that node.
+const migrationTargetAnnotation = "harvesterhci.io/migrationTargetNodeName"
+
+// migrationPollInterval is how often a migration is re-read while waiting for it to finish.
+var migrationPollInterval = 2 * time.Second
+
+// maxWaitTimeoutSeconds caps how long a migration, drain, backup or VM action wait may block a tool call.
+const maxWaitTimeoutSeconds = 1800
+
+var migrationActions = map[string]bool{"start": true, "status": true, "cancel": true}
+
+func (t *Toolset) vmMigrateTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_migrate",
+		mcp.WithDescription("Live-migrate a running VM and track it. start creates a VirtualMachineInstanceMigration (optionally to target_node) and can wait for it to finish; status reports phase, source/target nodes and phase timeline of the latest (or named) migration; cancel aborts a migration in progress, as Harvester's abortMigration does."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action: start, status, cancel")),
+		mcp.WithString("target_node", mcp.Description("start: node to migrate to (default: chosen by the scheduler)")),
+		mcp.WithString("migration", mcp.Description("status/cancel: VirtualMachineInstanceMigration name (default: the VM's latest migration)")),
+		mcp.WithBoolean("wait", mcp.Description("start: wait until the migration succeeds or fails (default: false)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Max seconds to wait when wait=true (default: 600, max: 1800)")),
+	)
+}
+
+func (t *Toolset) vmMigrationStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_migration_status",
+		mcp.WithDescription("Report the phase, source/target nodes, phase timeline and elapsed time of a VM's latest (or named) live migration"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithString("migration", mcp.Description("VirtualMachineInstanceMigration name (default: the VM's latest migration)")),
+	)
+}
+
+func (t *Toolset) vmMigratableTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_migratable",
+		mcp.WithDescription("List running VMs and whether they can be live-migrated, with the reasons others cannot (KubeVirt LiveMigratable condition, host devices/GPUs, non-RWX volumes, CD-ROMs, node pinning)"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Description("Namespace (empty = all namespaces)")),
+		mcp.WithString("node", mcp.Description("Only VMs running on this node")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) vmMigrateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	action, err := req.RequireString("action")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !migrationActions[action] {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid action %q; allowed: start, status, cancel", action)), nil
+	}
+	if action != "status" {
+		if err := t.policy.CheckWrite(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	switch action {
+	case "start":
+		vmi, err := t.getVMI(ctx, cluster, namespace, name)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		status := nestedMap(vmi, "status")
+		if status["phase"] != "Running" {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q is %v; only running VMs can be live-migrated", name, status["phase"])), nil
+		}
+		if reasons := t.migrationBlockers(ctx, cluster, vmi, nil); len(reasons) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q cannot be live-migrated: %s", name, strings.Join(reasons, "; "))), nil
+		}
+		if active := t.activeMigration(ctx, cluster, namespace, name); active != "" {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q already has migration %q in progress (action=status or action=cancel)", name, active)), nil
+		}
+		source, _ := status["nodeName"].(string)
+		targetNode := req.GetString("target_node", "")
+		if targetNode != "" {
+			if targetNode == source {
+				return mcp.NewToolResultError(fmt.Sprintf("VM %q already runs on node %q", name, targetNode)), nil
+			}
+			if err := t.checkMigrationTarget(ctx, cluster, targetNode); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		migrationName, err := t.startMigration(ctx, cluster, namespace, name, targetNode)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_migrate start: %v", err)), nil
+		}
+		target := targetNode
+		if target == "" {
+			target = "a node chosen by the scheduler"
+		}
+		msg := fmt.Sprintf("Migration %q of VM %q started from %s to %s", migrationName, name, source, target)
+		if !req.GetBool("wait", false) {
+			return mcp.NewToolResultText(msg + "; follow it with action=status"), nil
+		}
+		timeout := time.Duration(min(req.GetInt("timeout_seconds", 600), maxWaitTimeoutSeconds)) * time.Second
+		if timeout <= 0 {
+			timeout = 600 * time.Second
+		}
+		info, err := t.waitMigration(ctx, cluster, namespace, migrationName, timeout, wait.Progress(ctx, req, timeout))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s but: %v", msg, err)), nil
+		}
+		return t.formatObject(info)
+
+	case "status":
+		return t.vmMigrationStatus(ctx, cluster, namespace, name, req.GetString("migration", ""))
+
+	default: // cancel
+		migrationName := req.GetString("migration", "")
+		if migrationName == "" {
+			if migrationName = t.activeMigration(ctx, cluster, namespace, name); migrationName == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("VM %q has no migration in progress", name)), nil
+			}
+		}
+		info, err := t.migrationStatus(ctx, cluster, namespace, migrationName)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if phase := info["phase"]; phase == "Succeeded" || phase == "Failed" {
+			return mcp.NewToolResultError(fmt.Sprintf("migration %q already finished (%v)", migrationName, phase)), nil
+		}
+		// Deleting an unfinished migration object makes KubeVirt abort it.
+		if err := t.client.Delete(ctx, cluster, rancher.TypeVirtualMachineInstanceMigrations, namespace, migrationName); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_migrate cancel: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Migration %q of VM %q aborted (was %v); the VM keeps running on %v", migrationName, name, info["phase"], info["source_node"])), nil
+	}
+}
+
+func (t *Toolset) vmMigrationStatusHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return t.vmMigrationStatus(ctx, cluster, namespace, name, req.GetString("migration", ""))
+}
+
+// vmMigrationStatus reports a migration of a VM; with migrationName empty, the VM's latest one.
+func (t *Toolset) vmMigrationStatus(ctx context.Context, cluster, namespace, name, migrationName string) (*mcp.CallToolResult, error) {
+	if migrationName == "" {
+		if migrationName = t.latestMigration(ctx, cluster, namespace, name); migrationName == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q has no VirtualMachineInstanceMigration", name)), nil
+		}
+	}
+	info, err := t.migrationStatus(ctx, cluster, namespace, migrationName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return t.formatObject(info)
+}
+
+func (t *Toolset) vmMigratableHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	namespace := req.GetString("namespace", "")
+	if namespace != "" {
+		if err := t.policy.CheckNamespace(namespace); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	node := req.GetString("node", "")
+	format := req.GetString("format", "json")
+
+	vmis, err := t.listVMIs(ctx, cluster, namespace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	claims := t.claimAccessModes(ctx, cluster, namespace)
+	var items []map[string]interface{}
+	for _, vmi := range vmis {
+		status := nestedMap(vmi, "status")
+		if node != "" && status["nodeName"] != node {
+			continue
+		}
+		meta := nestedMap(vmi, "metadata")
+		reasons := t.migrationBlockers(ctx, cluster, vmi, claims)
+		items = append(items, map[string]interface{}{
+			"name":       meta["name"],
+			"namespace":  meta["namespace"],
+			"node":       status["nodeName"],
+			"phase":      status["phase"],
+			"migratable": len(reasons) == 0,
+			"reasons":    strings.Join(reasons, "; "),
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return fmt.Sprint(items[i]["namespace"], "/", items[i]["name"]) < fmt.Sprint(items[j]["namespace"], "/", items[j]["name"])
+	})
+	items = t.policy.FilterListByNamespace(items)
+	out, err := formatter.FormatListWithContinue(t.formatter, items, "", format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// migrationBlockers returns why a VMI cannot be live-migrated: KubeVirt's LiveMigratable condition and the
+// spec features that prevent migration. claims maps namespace/name to access modes; nil looks PVCs up one by one.
+func (t *Toolset) migrationBlockers(ctx context.Context, cluster string, vmi map[string]interface{}, claims map[string][]string) []string {
+	var reasons []string
+	if status := nestedMap(vmi, "status"); status["phase"] != "Running" {
+		reasons = append(reasons, fmt.Sprintf("not running (%v)", status["phase"]))
+	}
+	conds, _ := nestedMap(vmi, "status")["conditions"].([]interface{})
+	for _, c := range conds {
+		m, _ := c.(map[string]interface{})
+		if m["type"] == "LiveMigratable" && m["status"] == "False" {
+			reasons = append(reasons, fmt.Sprintf("LiveMigratable=False: %v %v", m["reason"], m["message"]))
+		}
+	}
+	spec := nestedMap(vmi, "spec")
+	devices := nestedMap(spec, "domain", "devices")
+	if hd, _ := devices["hostDevices"].([]interface{}); len(hd) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d host device(s) passed through", len(hd)))
+	}
+	if gpus, _ := devices["gpus"].([]interface{}); len(gpus) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d GPU(s)/vGPU(s) attached", len(gpus)))
+	}
+	disks, _ := devices["disks"].([]interface{})
+	for _, d := range disks {
+		if m, _ := d.(map[string]interface{}); m["cdrom"] != nil {
+			reasons = append(reasons, fmt.Sprintf("CD-ROM %q attached (eject it first)", entryName(m)))
+		}
+	}
+	namespace, _ := nestedMap(vmi, "metadata")["namespace"].(string)
+	volumes, _ := spec["volumes"].([]interface{})
+	for _, v := range volumes {
+		vol, _ := v.(map[string]interface{})
+		claim, _ := nestedMap(vol, "persistentVolumeClaim")["claimName"].(string)
+		if claim == "" {
+			if vol["containerDisk"] != nil || vol["hostDisk"] != nil {
+				reasons = append(reasons, fmt.Sprintf("volume %q is node-local (%s)", entryName(vol), strings.Join(mapKeysExcept(vol, "name"), ",")))
+			}
+			continue
+		}
+		modes, ok := claims[namespace+"/"+claim]
+		if claims == nil {
+			if pvc, err := t.client.Get(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, claim); err == nil {
+				modes, ok = accessModes(pvc.Spec), true
+			}
+		}
+		if ok && !slices.Contains(modes, "ReadWriteMany") {
+			reasons = append(reasons, fmt.Sprintf("volume %q is not ReadWriteMany (%s)", claim, strings.Join(modes, ",")))
+		}
+	}
+	if host := nestedMap(spec, "nodeSelector")["kubernetes.io/hostname"]; host != nil {
+		reasons = append(reasons, fmt.Sprintf("pinned to node %v by nodeSelector", host))
+	}
+	return reasons
+}
+
+// checkMigrationTarget verifies that a node exists, is Ready and can run VMs.
+func (t *Toolset) checkMigrationTarget(ctx context.Context, cluster, node string) error {
+	n, err := t.client.Get(ctx, cluster, rancher.TypeNodes, "", node)
+	if err != nil {
+		return fmt.Errorf("target node %q not found: %v", node, err)
+	}
+	if reason := vmNodeUnavailable(n); reason != "" {
+		return fmt.Errorf("target node %q %s", node, reason)
+	}
+	return nil
+}
+
+// vmNodeUnavailable returns why VMs cannot be scheduled to a node, or "" if they can.
+func vmNodeUnavailable(n *rancher.SteveResource) string {
+	spec, _ := n.Spec.(map[string]interface{})
+	if spec["unschedulable"] == true {
+		return "is cordoned or in maintenance mode"
+	}
+	if n.ObjectMeta.Labels["kubevirt.io/schedulable"] == "false" {
+		return "is not schedulable for VMs (kubevirt.io/schedulable=false)"
+	}
+	for _, c := range wait.Conditions(n.Status) {
+		if c["type"] == "Ready" && c["status"] != "True" {
+			return "is not Ready"
+		}
+	}
+	return ""
+}
+
+// startMigration creates a VirtualMachineInstanceMigration for a VMI, pinned to targetNode when set,
+// and returns its name.
+func (t *Toolset) startMigration(ctx context.Context, cluster, namespace, vmi, targetNode string) (string, error) {
+	name := vmi + "-" + utilrand.String(5)
+	metadata := map[string]interface{}{"name": name, "namespace": namespace}
+	if targetNode != "" {
+		metadata["annotations"] = map[string]string{migrationTargetAnnotation: targetNode}
+	}
+	body := map[string]interface{}{
+		"apiVersion": "kubevirt.io/v1",
+		"kind":       "VirtualMachineInstanceMigration",
+		"metadata":   metadata,
+		"spec":       map[string]interface{}{"vmiName": vmi},
+	}
+	if _, err := t.client.Create(ctx, cluster, rancher.TypeVirtualMachineInstanceMigrations, namespace, body); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// migrationStatus summarises a VirtualMachineInstanceMigration together with the VMI's migration state.
+func (t *Toolset) migrationStatus(ctx context.Context, cluster, namespace, name string) (map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachineInstanceMigrations, namespace, name)
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("migration %q: %v", name, err)
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("migration %q not found in namespace %q: %d %s", name, namespace, code, apiMessage(body))
+	}
+	var mig map[string]interface{}
+	if err := json.Unmarshal(body, &mig); err != nil {
+		return nil, fmt.Errorf("migration %q: %v", name, err)
+	}
+	meta := nestedMap(mig, "metadata")
+	status := nestedMap(mig, "status")
+	vmiName, _ := nestedMap(mig, "spec")["vmiName"].(string)
+	info := map[string]interface{}{
+		"migration":   name,
+		"namespace":   namespace,
+		"vm":          vmiName,
+		"phase":       status["phase"],
+		"created":     meta["creationTimestamp"],
+		"target_node": nestedMap(meta, "annotations")[migrationTargetAnnotation],
+	}
+	var timeline []string
+	transitions, _ := status["phaseTransitionTimestamps"].([]interface{})
+	for _, tr := range transitions {
+		m, _ := tr.(map[string]interface{})
+		timeline = append(timeline, fmt.Sprintf("%v %v", m["phaseTransitionTimestamp"], m["phase"]))
+	}
+	if len(timeline) > 0 {
+		info["timeline"] = timeline
+	}
+	// The VMI's migrationState describes the most recent migration; use it only if it is this one.
+	if vmi, err := t.getVMI(ctx, cluster, namespace, vmiName); err == nil {
+		state := nestedMap(vmi, "status", "migrationState")
+		if state != nil && state["migrationUid"] == meta["uid"] {
+			for key, field := range map[string]string{
+				"source_node": "sourceNode", "target_node": "targetNode", "target_pod": "targetPod",
+				"started": "startTimestamp", "finished": "endTimestamp", "mode": "mode",
+				"completed": "completed", "failed": "failed", "abort_status": "abortStatus",
+			} {
+				if v, ok := state[field]; ok {
+					info[key] = v
+				}
+			}
+			if reason, ok := state["failureReason"]; ok {
+				info["failure_reason"] = reason
+			}
+		}
+		if info["source_node"] == nil && info["phase"] != "Succeeded" {
+			info["source_node"] = nestedMap(vmi, "status")["nodeName"]
+		}
+	}
+	if started, ok := info["started"].(string); ok {
+		if ts, err := time.Parse(time.RFC3339, started); err == nil {
+			end := time.Now()
+			if finished, ok := info["finished"].(string); ok {
+				if te, err := time.Parse(time.RFC3339, finished); err == nil {
+					end = te
+				}
+			}
+			info["elapsed_seconds"] = int(end.Sub(ts).Seconds())
+		}
+	}
+	return info, nil
+}
+
+// waitMigration polls a migration until it succeeds or fails.
+func (t *Toolset) waitMigration(ctx context.Context, cluster, namespace, name string, timeout time.Duration, progress wait.ProgressFunc) (map[string]interface{}, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	lastPhase := ""
+	for {
+		info, err := t.migrationStatus(ctx, cluster, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		phase := fmt.Sprint(info["phase"])
+		if phase != lastPhase && progress != nil {
+			progress(time.Since(start), "phase "+phase)
+		}
+		lastPhase = phase
+		switch phase {
+		case "Succeeded":
+			return info, nil
+		case "Failed":
+			return nil, fmt.Errorf("migration %q failed: %v", name, info["failure_reason"])
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out after %s waiting for migration %q (last phase: %s)", timeout, name, phase)
+		case <-time.After(migrationPollInterval):
+		}
+	}
+}
+
+// latestMigration returns the newest migration of a VMI; activeMigration the unfinished one, if any.
+func (t *Toolset) latestMigration(ctx context.Context, cluster, namespace, vmi string) string {
+	migrations := t.vmiMigrations(ctx, cluster, namespace, vmi)
+	if len(migrations) == 0 {
+		return ""
+	}
+	return migrations[len(migrations)-1].ObjectMeta.Name
+}
+
+func (t *Toolset) activeMigration(ctx context.Context, cluster, namespace, vmi string) string {
+	for _, m := range t.vmiMigrations(ctx, cluster, namespace, vmi) {
+		status, _ := m.Status.(map[string]interface{})
+		if phase := status["phase"]; phase != "Succeeded" && phase != "Failed" {
+			return m.ObjectMeta.Name
+		}
+	}
+	return ""
+}
+
+// vmiMigrations lists the migrations of a VMI, oldest first.
+func (t *Toolset) vmiMigrations(ctx context.Context, cluster, namespace, vmi string) []rancher.SteveResource {
+	col, err := t.client.List(ctx, cluster, rancher.TypeVirtualMachineInstanceMigrations, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return nil
+	}
+	var out []rancher.SteveResource
+	for _, m := range col.Data {
+		spec, _ := m.Spec.(map[string]interface{})
+		if spec["vmiName"] == vmi {
+			out = append(out, m)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].ObjectMeta.CreationTimestamp < out[j].ObjectMeta.CreationTimestamp
+	})
+	return out
+}
+
+// listVMIs returns the VirtualMachineInstances in namespace (all namespaces when empty) as full objects.
+func (t *Toolset) listVMIs(ctx context.Context, cluster, namespace string) ([]map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachineInstances, namespace, "")
+	return t.listObjects(ctx, cluster, path, nil, "VM instances")
+}
+
+// listObjects lists full objects from a native API collection path; what names them in errors.
+func (t *Toolset) listObjects(ctx context.Context, cluster, path string, query url.Values, what string) ([]map[string]interface{}, error) {
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, query, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", what, err)
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("failed to list %s: %d %s", what, code, apiMessage(body))
+	}
+	var list struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", what, err)
+	}
+	return list.Items, nil
+}
+
+// claimAccessModes maps namespace/name of the PVCs in namespace (all when empty) to their access modes.
+func (t *Toolset) claimAccessModes(ctx context.Context, cluster, namespace string) map[string][]string {
+	out := map[string][]string{}
+	col, err := t.client.List(ctx, cluster, rancher.TypePersistentVolumeClaims, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return out
+	}
+	for _, pvc := range col.Data {
+		out[pvc.ObjectMeta.Namespace+"/"+pvc.ObjectMeta.Name] = accessModes(pvc.Spec)
+	}
+	return out
+}
+
+func accessModes(spec interface{}) []string {
+	m, _ := spec.(map[string]interface{})
+	raw, _ := m["accessModes"].([]interface{})
+	var modes []string
+	for _, a := range raw {
+		modes = append(modes, fmt.Sprint(a))
+	}
+	return modes
+}
+
+func mapKeysExcept(m map[string]interface{}, except string) []string {
+	var keys []string
+	for k := range m {
+		if k != except {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatObject renders a nested result as JSON; tables cannot show nested fields.
+func (t *Toolset) formatObject(info map[string]interface{}) (*mcp.CallToolResult, error) {
+	out, err := t.formatter.Format(info, "json")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
@@ -0,0 +1,586 @@
+package harvester
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// volumeClaimTemplatesAnnotation holds the PVCs (JSON list) Harvester's VM controller creates for a VM;
+// template versions use it to describe their disks.
+const volumeClaimTemplatesAnnotation = "harvesterhci.io/volumeClaimTemplates"
+
+func (t *Toolset) vmTemplateListTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_template_list",
+		mcp.WithDescription("List Harvester VM templates (VirtualMachineTemplate) with their default and latest version, or the versions of one template with CPU, memory, image, disks and key pairs"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Description("Namespace (empty = all namespaces; required with template)")),
+		mcp.WithString("template", mcp.Description("Template name; lists its versions instead of the templates")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) vmTemplateVersionCreateTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_template_version_create",
+		mcp.WithDescription("Create a VM template version from an existing VM (the template is created if it does not exist). Disks are recorded as volume claim templates (image-backed disks re-clone the image; data is not copied), MAC addresses and the hostname are dropped, and cloud-init data is copied into a Secret owned by the version."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the VM and template")),
+		mcp.WithString("vm", mcp.Required(), mcp.Description("Source VM name")),
+		mcp.WithString("template", mcp.Required(), mcp.Description("Template name")),
+		mcp.WithString("description", mcp.Description("Version description (also used for a new template)")),
+		mcp.WithBoolean("set_default", mcp.Description("Make this the template's default version (always true for a new template) (default: false)")),
+	)
+}
+
+func (t *Toolset) vmCreateFromTemplateTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Create a VM from a Harvester template version with optional overrides. Harvester creates the VM's volumes from the version's volume claim templates, named <vm>-<disk>. Cloud-init arguments replace the template's cloud-init."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the template and the new VM")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithString("template", mcp.Required(), mcp.Description("Template name")),
+		mcp.WithString("version", mcp.Description("Version number or version name (default: the template's default version)")),
+		mcp.WithNumber("cpu", mcp.Description("Override CPU cores")),
+		mcp.WithString("memory", mcp.Description("Override memory, e.g. 8Gi")),
+		mcp.WithString("run_strategy", mcp.Description("Override RunStrategy: Always, RerunOnFailure, Halted")),
+	}
+	return mcp.NewTool("harvester_vm_create_from_template", append(opts, cloudInitOptions()...)...)
+}
+
+func (t *Toolset) vmTemplateListHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster := req.GetString("cluster", "")
+	namespace := req.GetString("namespace", "")
+	template := req.GetString("template", "")
+	format := req.GetString("format", "json")
+	if namespace != "" {
+		if err := t.policy.CheckNamespace(namespace); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if template != "" && namespace == "" {
+		return mcp.NewToolResultError("namespace is required with template"), nil
+	}
+
+	var items []map[string]interface{}
+	if template == "" {
+		col, err := t.client.List(ctx, cluster, rancher.TypeVMTemplates, rancher.ListOpts{Namespace: namespace})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list VM templates: %v", err)), nil
+		}
+		for _, r := range col.Data {
+			spec, _ := r.Spec.(map[string]interface{})
+			status, _ := r.Status.(map[string]interface{})
+			items = append(items, map[string]interface{}{
+				"name":               r.ObjectMeta.Name,
+				"namespace":          r.ObjectMeta.Namespace,
+				"description":        spec["description"],
+				"default_version_id": spec["defaultVersionId"],
+				"default_version":    status["defaultVersion"],
+				"latest_version":     status["latestVersion"],
+			})
+		}
+	} else {
+		versions, defaultID, err := t.templateVersions(ctx, cluster, namespace, template)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, v := range versions {
+			items = append(items, templateVersionSummary(v, defaultID))
+		}
+	}
+	items = t.policy.FilterListByNamespace(items)
+	out, err := formatter.FormatListWithContinue(t.formatter, items, "", format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// templateVersions returns the versions of a template ordered by version number, and the template's default version ID.
+func (t *Toolset) templateVersions(ctx context.Context, cluster, namespace, template string) ([]rancher.SteveResource, string, error) {
+	tpl, err := t.client.Get(ctx, cluster, rancher.TypeVMTemplates, namespace, template)
+	if err != nil {
+		return nil, "", fmt.Errorf("template %q not found in namespace %q: %v", template, namespace, err)
+	}
+	tplSpec, _ := tpl.Spec.(map[string]interface{})
+	defaultID, _ := tplSpec["defaultVersionId"].(string)
+	col, err := t.client.List(ctx, cluster, rancher.TypeVMTemplateVersions, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list template versions: %v", err)
+	}
+	var versions []rancher.SteveResource
+	for _, r := range col.Data {
+		spec, _ := r.Spec.(map[string]interface{})
+		if spec["templateId"] == namespace+"/"+template {
+			versions = append(versions, r)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versionNumber(versions[i]) < versionNumber(versions[j]) })
+	return versions, defaultID, nil
+}
+
+func versionNumber(r rancher.SteveResource) int {
+	status, _ := r.Status.(map[string]interface{})
+	n, _ := status["version"].(float64)
+	return int(n)
+}
+
+// templateVersionSummary extracts the sizing, image and disks of a template version.
+func templateVersionSummary(r rancher.SteveResource, defaultID string) map[string]interface{} {
+	spec, _ := r.Spec.(map[string]interface{})
+	vmSpec := nestedMap(spec, "vm", "spec")
+	domain := nestedMap(vmSpec, "template", "spec", "domain")
+	var disks []string
+	devices, _ := nestedMap(domain, "devices")["disks"].([]interface{})
+	for _, d := range devices {
+		if m, ok := d.(map[string]interface{}); ok {
+			disks = append(disks, fmt.Sprint(m["name"]))
+		}
+	}
+	return map[string]interface{}{
+		"name":        r.ObjectMeta.Name,
+		"namespace":   r.ObjectMeta.Namespace,
+		"version":     versionNumber(r),
+		"default":     r.ObjectMeta.Namespace+"/"+r.ObjectMeta.Name == defaultID,
+		"description": spec["description"],
+		"image_id":    spec["imageId"],
+		"key_pairs":   spec["keyPairIds"],
+		"cpu":         nestedMap(domain, "cpu")["cores"],
+		"memory":      nestedMap(domain, "resources", "limits")["memory"],
+		"disks":       disks,
+	}
+}
+
+func (t *Toolset) vmTemplateVersionCreateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	vmName, err := req.RequireString("vm")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	template, err := req.RequireString("template")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	description := req.GetString("description", "")
+	setDefault := req.GetBool("set_default", false)
+
+	vm, err := t.client.Get(ctx, cluster, rancher.TypeVirtualMachines, namespace, vmName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("VM %q not found in namespace %q: %v", vmName, namespace, err)), nil
+	}
+	vmSpec := cloneMap(vm.Spec)
+	podSpec := nestedMap(vmSpec, "template", "spec")
+	if podSpec == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("VM %q has no template spec", vmName)), nil
+	}
+	// Per-VM identity is not part of a template.
+	delete(podSpec, "hostname")
+	ifaces, _ := nestedMap(podSpec, "domain", "devices")["interfaces"].([]interface{})
+	for _, iface := range ifaces {
+		if m, ok := iface.(map[string]interface{}); ok {
+			delete(m, "macAddress")
+		}
+	}
+
+	versionName := template + "-" + utilrand.String(5)
+	var claims []map[string]interface{}
+	var cloudInit *cloudInitConfig
+	imageID := ""
+	volumes, _ := podSpec["volumes"].([]interface{})
+	for _, v := range volumes {
+		vol, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if claimName, _ := nestedMap(vol, "persistentVolumeClaim")["claimName"].(string); claimName != "" {
+			pvc, err := t.client.Get(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, claimName)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("volume %q: %v", claimName, err)), nil
+			}
+			claim := claimTemplate(pvc)
+			if id := pvc.ObjectMeta.Annotations["harvesterhci.io/imageId"]; id != "" && imageID == "" {
+				imageID = id
+			}
+			claims = append(claims, claim)
+		}
+		if source := nestedMap(vol, "cloudInitNoCloud"); source != nil {
+			secretName, _ := nestedMap(source, "secretRef")["name"].(string)
+			if secretName == "" {
+				continue
+			}
+			if cloudInit, err = t.readCloudInitSecret(ctx, cluster, namespace, secretName); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			vol["cloudInitNoCloud"] = cloudInitVolume(versionName+"-cloudinit", cloudInit)["cloudInitNoCloud"]
+		}
+	}
+	claimsJSON, _ := json.Marshal(claims)
+
+	var keyPairs []string
+	if raw := vm.ObjectMeta.Annotations[sshNamesAnnotation]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &keyPairs)
+	}
+
+	newTemplate := false
+	if _, err := t.client.Get(ctx, cluster, rancher.TypeVMTemplates, namespace, template); err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return mcp.NewToolResultError(fmt.Sprintf("template %q: %v", template, err)), nil
+		}
+		body := map[string]interface{}{
+			"apiVersion": "harvesterhci.io/v1beta1",
+			"kind":       "VirtualMachineTemplate",
+			"metadata":   map[string]interface{}{"name": template, "namespace": namespace},
+			"spec":       map[string]interface{}{"description": description},
+		}
+		if _, err := t.client.Create(ctx, cluster, rancher.TypeVMTemplates, namespace, body); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create template %q: %v", template, err)), nil
+		}
+		newTemplate = true
+	}
+	// A template created here is removed again if its first version cannot be created.
+	deleteNewTemplate := func() {
+		if newTemplate {
+			_ = t.client.Delete(ctx, cluster, rancher.TypeVMTemplates, namespace, template)
+		}
+	}
+
+	secretName := versionName + "-cloudinit"
+	if cloudInit != nil {
+		if _, err := t.client.Create(ctx, cluster, rancher.TypeSecrets, namespace, cloudInitSecret(secretName, namespace, cloudInit)); err != nil {
+			deleteNewTemplate()
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create cloud-init secret %q: %v", secretName, err)), nil
+		}
+	}
+	version := map[string]interface{}{
+		"apiVersion": "harvesterhci.io/v1beta1",
+		"kind":       "VirtualMachineTemplateVersion",
+		"metadata":   map[string]interface{}{"name": versionName, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"templateId":  namespace + "/" + template,
+			"description": description,
+			"imageId":     imageID,
+			"keyPairIds":  keyPairs,
+			"vm": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{volumeClaimTemplatesAnnotation: string(claimsJSON)},
+				},
+				"spec": vmSpec,
+			},
+		},
+	}
+	created, err := t.client.Create(ctx, cluster, rancher.TypeVMTemplateVersions, namespace, version)
+	if err != nil {
+		if cloudInit != nil {
+			_ = t.client.Delete(ctx, cluster, rancher.TypeSecrets, namespace, secretName)
+		}
+		deleteNewTemplate()
+		return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_template_version_create: %v", err)), nil
+	}
+
+	msg := fmt.Sprintf("Template version %q of template %q created from VM %q (%d disks)", versionName, template, vmName, len(claims))
+	if newTemplate {
+		msg = fmt.Sprintf("Template %q created; ", template) + msg
+	}
+	if cloudInit != nil {
+		if err := t.setSecretOwner(ctx, cluster, namespace, secretName, "harvesterhci.io/v1beta1", "VirtualMachineTemplateVersion", created); err != nil {
+			msg += fmt.Sprintf("; warning: could not set the version as owner of cloud-init secret %q: %v", secretName, err)
+		}
+	}
+	if setDefault || newTemplate {
+		patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"defaultVersionId": namespace + "/" + versionName}})
+		path, _ := rancher.ResourcePath(rancher.TypeVMTemplates, namespace, template)
+		body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeMerge)
+		switch {
+		case err != nil:
+			msg += fmt.Sprintf("; warning: could not set it as default version: %v", err)
+		case status < 200 || status >= 300:
+			msg += fmt.Sprintf("; warning: could not set it as default version: %d %s", status, string(body))
+		default:
+			msg += "; set as default version"
+		}
+	}
+	return mcp.NewToolResultText(msg), nil
+}
+
+func (t *Toolset) vmCreateFromTemplateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	template, err := req.RequireString("template")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	versionArg := req.GetString("version", "")
+
+	versions, defaultID, err := t.templateVersions(ctx, cluster, namespace, template)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var version *rancher.SteveResource
+	for i, v := range versions {
+		id := v.ObjectMeta.Namespace + "/" + v.ObjectMeta.Name
+		switch {
+		case versionArg == "" && id == defaultID,
+			versionArg == v.ObjectMeta.Name,
+			versionArg != "" && versionArg == strconv.Itoa(versionNumber(v)):
+			version = &versions[i]
+		}
+	}
+	if version == nil {
+		if versionArg == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("template %q has no default version (%q); pass version", template, defaultID)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("template %q has no version %q (use harvester_vm_template_list with template=%s)", template, versionArg, template)), nil
+	}
+	versionSpec := cloneMap(version.Spec)
+	vmTemplate := nestedMap(versionSpec, "vm")
+	vmSpec := nestedMap(vmTemplate, "spec")
+	podSpec := nestedMap(vmSpec, "template", "spec")
+	if podSpec == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("template version %q has no VM spec", version.ObjectMeta.Name)), nil
+	}
+
+	annotations := map[string]interface{}{}
+	for k, v := range nestedMap(vmTemplate, "metadata", "annotations") {
+		annotations[k] = v
+	}
+	labels := nestedMap(vmTemplate, "metadata", "labels")
+
+	// Claims are renamed to <vm>-<volume> so every VM gets its own volumes.
+	var claims []map[string]interface{}
+	if raw, _ := annotations[volumeClaimTemplatesAnnotation].(string); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &claims); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("template version %q: invalid %s: %v", version.ObjectMeta.Name, volumeClaimTemplatesAnnotation, err)), nil
+		}
+	}
+	renamed := map[string]string{}
+	volumes, _ := podSpec["volumes"].([]interface{})
+	var templateSecret string
+	cloudInitIdx := -1
+	for i, v := range volumes {
+		vol, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if pvc := nestedMap(vol, "persistentVolumeClaim"); pvc != nil {
+			oldName, _ := pvc["claimName"].(string)
+			newName := fmt.Sprintf("%s-%s", name, vol["name"])
+			renamed[oldName] = newName
+			pvc["claimName"] = newName
+		}
+		if source := nestedMap(vol, "cloudInitNoCloud"); source != nil {
+			cloudInitIdx = i
+			templateSecret, _ = nestedMap(source, "secretRef")["name"].(string)
+		}
+	}
+	var vmClaims []map[string]interface{}
+	for _, c := range claims {
+		meta := nestedMap(c, "metadata")
+		if newName, ok := renamed[fmt.Sprint(meta["name"])]; ok {
+			meta["name"] = newName
+			vmClaims = append(vmClaims, c)
+		}
+	}
+	claimsJSON, _ := json.Marshal(vmClaims)
+	annotations[volumeClaimTemplatesAnnotation] = string(claimsJSON)
+
+	// Sizing overrides: requests and guest memory are left to Harvester, as in harvester_vm_create.
+	domain := nestedMap(podSpec, "domain")
+	if cpu := req.GetInt("cpu", 0); cpu > 0 || req.GetString("memory", "") != "" {
+		if domain["resources"] == nil {
+			domain["resources"] = map[string]interface{}{}
+		}
+		resources := nestedMap(domain, "resources")
+		if resources["limits"] == nil {
+			resources["limits"] = map[string]interface{}{}
+		}
+		limits := nestedMap(resources, "limits")
+		delete(resources, "requests")
+		delete(nestedMap(domain, "memory"), "guest")
+		if cpu > 0 {
+			if domain["cpu"] == nil {
+				domain["cpu"] = map[string]interface{}{}
+			}
+			cpuSpec := nestedMap(domain, "cpu")
+			cpuSpec["cores"] = cpu
+			sockets, threads := 1, 1
+			if n, ok := cpuSpec["sockets"].(float64); ok && n > 0 {
+				sockets = int(n)
+			}
+			if n, ok := cpuSpec["threads"].(float64); ok && n > 0 {
+				threads = int(n)
+			}
+			limits["cpu"] = strconv.Itoa(cpu * sockets * threads)
+		}
+		if memory := req.GetString("memory", ""); memory != "" {
+			limits["memory"] = memory
+		}
+	}
+	if rs := req.GetString("run_strategy", ""); rs != "" {
+		vmSpec["runStrategy"] = rs
+	}
+	if tm := nestedMap(vmSpec, "template"); tm != nil {
+		if tm["metadata"] == nil {
+			tm["metadata"] = map[string]interface{}{}
+		}
+		meta := nestedMap(tm, "metadata")
+		if meta["labels"] == nil {
+			meta["labels"] = map[string]interface{}{}
+		}
+		nestedMap(meta, "labels")["harvesterhci.io/vmName"] = name
+	}
+
+	// Cloud-init: explicit arguments win; otherwise the template's secret is copied for this VM.
+	cloudInit, err := t.resolveCloudInit(ctx, cluster, namespace, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var sshNames interface{} = versionSpec["keyPairIds"]
+	if cloudInit != nil {
+		sshNames = cloudInit.SSHNames
+	} else if templateSecret != "" {
+		if cloudInit, err = t.readCloudInitSecret(ctx, cluster, namespace, templateSecret); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	delete(annotations, sshNamesAnnotation)
+	if names, _ := json.Marshal(sshNames); string(names) != "null" && string(names) != "[]" {
+		annotations[sshNamesAnnotation] = string(names)
+	}
+	secretName := name + "-cloudinit"
+	if cloudInit != nil {
+		vol := cloudInitVolume(secretName, cloudInit)
+		if cloudInitIdx >= 0 {
+			volumes[cloudInitIdx] = vol
+		} else {
+			podSpec["volumes"] = append(volumes, vol)
+			devices := nestedMap(domain, "devices")
+			disks, _ := devices["disks"].([]interface{})
+			devices["disks"] = append(disks, vmDisk(cloudInitDiskName, "disk", "virtio", 0))
+		}
+		if _, err := t.client.Create(ctx, cluster, rancher.TypeSecrets, namespace, cloudInitSecret(secretName, namespace, cloudInit)); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create cloud-init secret %q: %v", secretName, err)), nil
+		}
+	}
+
+	metadata := map[string]interface{}{"name": name, "namespace": namespace, "annotations": annotations}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	vm := map[string]interface{}{
+		"apiVersion": "kubevirt.io/v1",
+		"kind":       "VirtualMachine",
+		"metadata":   metadata,
+		"spec":       vmSpec,
+	}
+	created, err := t.client.Create(ctx, cluster, rancher.TypeVirtualMachines, namespace, vm)
+	if err != nil {
+		if cloudInit != nil {
+			_ = t.client.Delete(ctx, cluster, rancher.TypeSecrets, namespace, secretName)
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_create_from_template: %v", err)), nil
+	}
+	msg := fmt.Sprintf("VM %q created in namespace %q from template %q version %d (%s) with %d volumes", name, namespace, template, versionNumber(*version), version.ObjectMeta.Name, len(vmClaims))
+	if cloudInit != nil {
+		msg += fmt.Sprintf(", cloud-init secret %q", secretName)
+		if err := t.setSecretOwner(ctx, cluster, namespace, secretName, "kubevirt.io/v1", "VirtualMachine", created); err != nil {
+			msg += fmt.Sprintf("; warning: could not set the VM as owner of the secret, delete it with the VM: %v", err)
+		}
+	}
+	return mcp.NewToolResultText(msg), nil
+}
+
+// claimTemplate turns an existing PVC into a volume claim template entry (name, image annotation and spec).
+func claimTemplate(pvc *rancher.SteveResource) map[string]interface{} {
+	meta := map[string]interface{}{"name": pvc.ObjectMeta.Name}
+	if id := pvc.ObjectMeta.Annotations["harvesterhci.io/imageId"]; id != "" {
+		meta["annotations"] = map[string]string{"harvesterhci.io/imageId": id}
+	}
+	src, _ := pvc.Spec.(map[string]interface{})
+	spec := map[string]interface{}{}
+	for _, key := range []string{"accessModes", "storageClassName", "volumeMode"} {
+		if v, ok := src[key]; ok {
+			spec[key] = v
+		}
+	}
+	if storage := nestedMap(src, "resources", "requests")["storage"]; storage != nil {
+		spec["resources"] = map[string]interface{}{"requests": map[string]interface{}{"storage": storage}}
+	}
+	return map[string]interface{}{"metadata": meta, "spec": spec}
+}
+
+// readCloudInitSecret reads the user and network data of a cloud-init Secret.
+func (t *Toolset) readCloudInitSecret(ctx context.Context, cluster, namespace, name string) (*cloudInitConfig, error) {
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, name), nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("cloud-init secret %q: %v", name, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("cloud-init secret %q: %d %s", name, status, string(body))
+	}
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("cloud-init secret %q: %v", name, err)
+	}
+	decode := func(key string) string {
+		b, _ := base64.StdEncoding.DecodeString(secret.Data[key])
+		return string(b)
+	}
+	return &cloudInitConfig{UserData: decode("userdata"), NetworkData: decode("networkdata")}, nil
+}
+
+// nestedMap walks keys through nested maps, returning nil when a level is missing.
+func nestedMap(m map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, k := range keys {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = next
+	}
+	return m
+}
+
+// cloneMap deep-copies a decoded JSON object.
+func cloneMap(v interface{}) map[string]interface{} {
+	b, _ := json.Marshal(v)
+	var out map[string]interface{}
+	_ = json.Unmarshal(b, &out)
+	return out
+}
@@ -0,0 +1,498 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// descriptionAnnotation is where Rancher and the Harvester UI keep a resource's description.
+const descriptionAnnotation = "field.cattle.io/description"
+
+var runStrategies = map[string]bool{"Always": true, "RerunOnFailure": true, "Halted": true, "Manual": true}
+
+func (t *Toolset) vmUpdateTool() mcp.Tool {
+	return mcp.NewTool(
+		"harvester_vm_update",
+		mcp.WithDescription("Update a Harvester VM: CPU/memory, disks, NICs, run strategy, labels, description, and root volume size. CPU sockets and memory are hot-plugged when the VM was created with maxSockets/maxGuest and the new value fits; other changes to a running VM take effect after a restart. Reports the RestartRequired condition."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Harvester cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("VM name")),
+		mcp.WithNumber("cpu", mcp.Description("CPU cores")),
+		mcp.WithNumber("cpu_sockets", mcp.Description("CPU sockets; hot-pluggable up to the VM's maxSockets")),
+		mcp.WithString("memory", mcp.Description("Memory size e.g. 8Gi; hot-pluggable up to the VM's maxGuest")),
+		mcp.WithString("run_strategy", mcp.Description("RunStrategy: Always, RerunOnFailure, Halted, Manual")),
+		mcp.WithString("labels", mcp.Description("VM label changes as key=value pairs, key- to remove, comma-separated (e.g. tier=db,stale-)")),
+		mcp.WithString("description", mcp.Description("VM description (empty string is ignored)")),
+		mcp.WithString("add_disks", mcp.Description(`Disks to add, JSON array as in harvester_vm_create disks, e.g. [{"size_gib":50},{"volume":"data-pvc"}]`)),
+		mcp.WithString("remove_disks", mcp.Description("Comma-separated disk names to detach; their volumes are kept (delete them with harvester_vm_delete or kubernetes_delete). The boot disk is refused unless remove_boot_disk=true")),
+		mcp.WithBoolean("remove_boot_disk", mcp.Description("Allow remove_disks to detach the boot disk (bootOrder 1, or the first disk); the VM cannot boot until another bootable disk is added (default: false)")),
+		mcp.WithString("add_networks", mcp.Description(`Secondary NICs to add, JSON array as in harvester_vm_create networks, e.g. [{"network":"vlan100","interface_type":"bridge"}]`)),
+		mcp.WithString("remove_networks", mcp.Description("Comma-separated NIC names to remove (not the primary NIC)")),
+		mcp.WithString("root_volume_size", mcp.Description("Expand the root disk's volume to this size, e.g. 60Gi (grow only)")),
+	)
+}
+
+func (t *Toolset) vmUpdateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster := req.GetString("cluster", "")
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	vm, err := t.getVMObject(ctx, cluster, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	status, _ := vm["status"].(map[string]interface{})
+	running := status["printableStatus"] == "Running"
+	spec := nestedMap(vm, "spec")
+	podSpec := nestedMap(spec, "template", "spec")
+	domain := nestedMap(podSpec, "domain")
+	if domain == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("VM %q has no domain spec", name)), nil
+	}
+	metadata := nestedMap(vm, "metadata")
+
+	// changes lists what was changed; restart lists the changes that only apply after a restart.
+	var changes, restart []string
+	change := func(desc string, live bool) {
+		changes = append(changes, desc)
+		if !live && running {
+			restart = append(restart, desc)
+		}
+	}
+
+	cpuSpec := ensureMap(domain, "cpu")
+	cores, sockets, threads := intField(cpuSpec, "cores"), intField(cpuSpec, "sockets"), intField(cpuSpec, "threads")
+	newCores, newSockets := req.GetInt("cpu", 0), req.GetInt("cpu_sockets", 0)
+	if newCores < 0 || newSockets < 0 {
+		return mcp.NewToolResultError("cpu and cpu_sockets must be >= 1"), nil
+	}
+	if newCores > 0 && newCores != cores {
+		cpuSpec["cores"] = newCores
+		change(fmt.Sprintf("cpu cores %d -> %d", cores, newCores), false)
+		cores = newCores
+	}
+	if newSockets > 0 && newSockets != sockets {
+		maxSockets := intField(cpuSpec, "maxSockets")
+		cpuSpec["sockets"] = newSockets
+		change(fmt.Sprintf("cpu sockets %d -> %d", sockets, newSockets), maxSockets > 0 && newSockets <= maxSockets)
+		sockets = newSockets
+	}
+	resources := ensureMap(domain, "resources")
+	if newCores > 0 || newSockets > 0 {
+		ensureMap(resources, "limits")["cpu"] = fmt.Sprint(cores * sockets * threads)
+		deleteField(resources, "requests", "cpu")
+	}
+
+	if memory := req.GetString("memory", ""); memory != "" {
+		memQty, err := resource.ParseQuantity(memory)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid memory %q: %v", memory, err)), nil
+		}
+		memSpec := nestedMap(domain, "memory")
+		old, _ := nestedMap(resources, "limits")["memory"].(string)
+		hotplug := false
+		if maxGuest, _ := memSpec["maxGuest"].(string); maxGuest != "" {
+			if maxQty, err := resource.ParseQuantity(maxGuest); err == nil && memQty.Cmp(maxQty) <= 0 {
+				memSpec["guest"] = memory
+				hotplug = true
+			}
+		}
+		if !hotplug {
+			// Harvester derives the request and the guest memory from the limit again.
+			deleteField(domain, "memory", "guest")
+			deleteField(resources, "requests", "memory")
+		}
+		ensureMap(resources, "limits")["memory"] = memory
+		change(fmt.Sprintf("memory %s -> %s", old, memory), hotplug)
+	}
+
+	if rs := req.GetString("run_strategy", ""); rs != "" {
+		if !runStrategies[rs] {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid run_strategy %q; allowed: Always, RerunOnFailure, Halted, Manual", rs)), nil
+		}
+		delete(spec, "running")
+		spec["runStrategy"] = rs
+		change("run strategy "+rs, true)
+	}
+	if raw := req.GetString("labels", ""); raw != "" {
+		labels := ensureMap(metadata, "labels")
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			if key, value, ok := strings.Cut(part, "="); ok {
+				labels[key] = value
+			} else if key, ok := strings.CutSuffix(part, "-"); ok {
+				delete(labels, key)
+			} else {
+				return mcp.NewToolResultError(fmt.Sprintf("label change %q is not key=value or key-", part)), nil
+			}
+		}
+		change("labels", true)
+	}
+	if desc := req.GetString("description", ""); desc != "" {
+		ensureMap(metadata, "annotations")[descriptionAnnotation] = desc
+		change("description", true)
+	}
+
+	devices := ensureMap(domain, "devices")
+	disks, _ := devices["disks"].([]interface{})
+	volumes, _ := podSpec["volumes"].([]interface{})
+	var detachedClaims []string
+	// Names of removed disks stay reserved: their volumes (<vm>-<disk>) are kept.
+	usedDisks := map[string]bool{}
+	for _, d := range disks {
+		usedDisks[entryName(d)] = true
+	}
+	if raw := req.GetString("remove_disks", ""); raw != "" {
+		boot := bootDisk(disks)
+		for _, diskName := range splitList(raw) {
+			i := slices.IndexFunc(disks, func(d interface{}) bool { return entryName(d) == diskName })
+			if i < 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("VM %q has no disk %q", name, diskName)), nil
+			}
+			if diskName == boot && !req.GetBool("remove_boot_disk", false) {
+				return mcp.NewToolResultError(fmt.Sprintf("disk %q is the boot disk of VM %q; set remove_boot_disk=true to detach it anyway", diskName, name)), nil
+			}
+			disks = slices.Delete(disks, i, i+1)
+			if j := slices.IndexFunc(volumes, func(v interface{}) bool { return entryName(v) == diskName }); j >= 0 {
+				vol, _ := volumes[j].(map[string]interface{})
+				if claim, _ := nestedMap(vol, "persistentVolumeClaim")["claimName"].(string); claim != "" {
+					detachedClaims = append(detachedClaims, claim)
+				}
+				volumes = slices.Delete(volumes, j, j+1)
+			}
+			change("remove disk "+diskName, false)
+		}
+		removeClaimTemplates(metadata, detachedClaims)
+	}
+
+	var newClaims []map[string]interface{}
+	if raw := req.GetString("add_disks", ""); raw != "" {
+		added, err := parseDiskSpecs(raw, usedDisks)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, d := range added {
+			claimName, claim, err := t.diskVolume(ctx, cluster, namespace, name, d)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if claim != nil {
+				newClaims = append(newClaims, claim)
+			}
+			disks = append(disks, vmDisk(d.Name, d.Type, d.Bus, d.BootOrder))
+			volumes = append(volumes, map[string]interface{}{
+				"name":                  d.Name,
+				"persistentVolumeClaim": map[string]interface{}{"claimName": claimName},
+			})
+			change(fmt.Sprintf("add %s %s (volume %s)", d.Type, d.Name, claimName), false)
+		}
+	}
+	devices["disks"] = disks
+	podSpec["volumes"] = volumes
+
+	interfaces, _ := devices["interfaces"].([]interface{})
+	networks, _ := podSpec["networks"].([]interface{})
+	if raw := req.GetString("remove_networks", ""); raw != "" {
+		for _, nicName := range splitList(raw) {
+			i := slices.IndexFunc(interfaces, func(n interface{}) bool { return entryName(n) == nicName })
+			switch {
+			case i < 0:
+				return mcp.NewToolResultError(fmt.Sprintf("VM %q has no NIC %q", name, nicName)), nil
+			case i == 0:
+				return mcp.NewToolResultError(fmt.Sprintf("NIC %q is the primary NIC and cannot be removed", nicName)), nil
+			}
+			interfaces = slices.Delete(interfaces, i, i+1)
+			if j := slices.IndexFunc(networks, func(n interface{}) bool { return entryName(n) == nicName }); j >= 0 {
+				networks = slices.Delete(networks, j, j+1)
+			}
+			change("remove NIC "+nicName, false)
+		}
+	}
+	if raw := req.GetString("add_networks", ""); raw != "" {
+		existing := map[string]bool{}
+		for _, n := range interfaces {
+			existing[entryName(n)] = true
+		}
+		if len(existing) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q has no primary NIC to add secondary NICs to", name)), nil
+		}
+		added, err := parseNICSpecs(raw, existing)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for i := range added {
+			iface, net := vmNIC(&added[i], namespace, false)
+			interfaces = append(interfaces, iface)
+			networks = append(networks, net)
+			change(fmt.Sprintf("add NIC %s on %s", added[i].Name, added[i].Network), false)
+		}
+	}
+	devices["interfaces"] = interfaces
+	podSpec["networks"] = networks
+
+	// The root disk's volume is expanded separately; validate it before touching the VM.
+	rootClaim, newSize := "", req.GetString("root_volume_size", "")
+	if newSize != "" {
+		sizeQty, err := resource.ParseQuantity(newSize)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid root_volume_size %q: %v", newSize, err)), nil
+		}
+		if rootClaim = rootVolumeClaim(disks, volumes); rootClaim == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("VM %q has no root disk backed by a volume", name)), nil
+		}
+		pvc, err := t.client.Get(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, rootClaim)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("root volume %q: %v", rootClaim, err)), nil
+		}
+		pvcSpec, _ := pvc.Spec.(map[string]interface{})
+		current, _ := nestedMap(pvcSpec, "resources", "requests")["storage"].(string)
+		if currentQty, err := resource.ParseQuantity(current); err == nil && sizeQty.Cmp(currentQty) <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("root_volume_size %s must be larger than the current size %s of volume %q (volumes can only grow)", newSize, current, rootClaim)), nil
+		}
+	}
+	if len(changes) == 0 && rootClaim == "" {
+		return mcp.NewToolResultError("nothing to update; set at least one change"), nil
+	}
+
+	var createdClaims []string
+	for _, pvc := range newClaims {
+		claimName := pvc["metadata"].(map[string]interface{})["name"].(string)
+		if _, err := t.client.Create(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, pvc); err != nil {
+			t.deleteClaims(ctx, cluster, namespace, createdClaims)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create volume %q: %v", claimName, err)), nil
+		}
+		createdClaims = append(createdClaims, claimName)
+	}
+	if len(changes) > 0 {
+		delete(vm, "status")
+		if err := t.putVMObject(ctx, cluster, namespace, name, vm); err != nil {
+			t.deleteClaims(ctx, cluster, namespace, createdClaims)
+			return mcp.NewToolResultError(fmt.Sprintf("harvester_vm_update: %v", err)), nil
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "VM %q updated", name)
+	if len(changes) > 0 {
+		fmt.Fprintf(&b, ": %s", strings.Join(changes, "; "))
+	}
+	if len(detachedClaims) > 0 {
+		fmt.Fprintf(&b, "\nDetached volumes kept: %s", strings.Join(detachedClaims, ", "))
+	}
+	if rootClaim != "" {
+		patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"resources": map[string]interface{}{"requests": map[string]interface{}{"storage": newSize}}}})
+		body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, fmt.Sprintf("/api/v1/namespaces/%s/persistentvolumeclaims/%s", namespace, rootClaim), nil, patch, rancher.PatchTypeMerge)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "\nRoot volume %q not expanded: %v", rootClaim, err)
+		case status < 200 || status >= 300:
+			fmt.Fprintf(&b, "\nRoot volume %q not expanded: %d %s", rootClaim, status, apiMessage(body))
+		default:
+			fmt.Fprintf(&b, "\nRoot volume %q expanding to %s; grow the partition and filesystem in the guest afterwards", rootClaim, newSize)
+		}
+	}
+
+	// KubeVirt sets RestartRequired once it has compared the VM with the running instance.
+	restartRequired := false
+	if updated, err := t.getVMObject(ctx, cluster, namespace, name); err == nil {
+		restartRequired = hasCondition(updated, "RestartRequired")
+	}
+	switch {
+	case restartRequired:
+		b.WriteString("\nRestart required: yes (RestartRequired condition is set); restart with harvester_vm_action action=restart")
+	case len(restart) > 0:
+		fmt.Fprintf(&b, "\nRestart required: yes for %s (the RestartRequired condition may take a moment to appear)", strings.Join(restart, "; "))
+	case running:
+		b.WriteString("\nRestart required: no; changes were applied to the running VM")
+	default:
+		b.WriteString("\nRestart required: no; the VM is not running and picks up the changes on next start")
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// getVMObject reads a VirtualMachine as a full object, including resourceVersion, from the native API.
+func (t *Toolset) getVMObject(ctx context.Context, cluster, namespace, name string) (map[string]interface{}, error) {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachines, namespace, name)
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("VM %q: %v", name, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("VM %q not found in namespace %q: %d %s", name, namespace, status, apiMessage(body))
+	}
+	var vm map[string]interface{}
+	if err := json.Unmarshal(body, &vm); err != nil {
+		return nil, fmt.Errorf("VM %q: %v", name, err)
+	}
+	return vm, nil
+}
+
+// putVMObject replaces a VirtualMachine; the resourceVersion in vm guards against concurrent changes.
+func (t *Toolset) putVMObject(ctx context.Context, cluster, namespace, name string, vm map[string]interface{}) error {
+	path, _ := rancher.ResourcePath(rancher.TypeVirtualMachines, namespace, name)
+	data, err := json.Marshal(vm)
+	if err != nil {
+		return err
+	}
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPut, path, nil, data, "")
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("%d %s", status, apiMessage(body))
+	}
+	return nil
+}
+
+func (t *Toolset) deleteClaims(ctx context.Context, cluster, namespace string, claims []string) {
+	for _, c := range claims {
+		_ = t.client.Delete(ctx, cluster, rancher.TypePersistentVolumeClaims, namespace, c)
+	}
+}
+
+// bootDisk returns the name of the disk with boot order 1, or of the first disk.
+func bootDisk(disks []interface{}) string {
+	root := ""
+	for _, d := range disks {
+		m, _ := d.(map[string]interface{})
+		if _, isDisk := m["disk"]; !isDisk {
+			continue
+		}
+		if root == "" || intField(m, "bootOrder") == 1 {
+			root = entryName(m)
+		}
+		if intField(m, "bootOrder") == 1 {
+			break
+		}
+	}
+	return root
+}
+
+// rootVolumeClaim returns the claim of the boot disk.
+func rootVolumeClaim(disks, volumes []interface{}) string {
+	root := bootDisk(disks)
+	for _, v := range volumes {
+		if entryName(v) == root {
+			vol, _ := v.(map[string]interface{})
+			claim, _ := nestedMap(vol, "persistentVolumeClaim")["claimName"].(string)
+			return claim
+		}
+	}
+	return ""
+}
+
+// removeClaimTemplates drops claims from the VM's volume claim templates annotation so Harvester does not recreate them.
+func removeClaimTemplates(metadata map[string]interface{}, claims []string) {
+	annotations := nestedMap(metadata, "annotations")
+	raw, _ := annotations[volumeClaimTemplatesAnnotation].(string)
+	if raw == "" || len(claims) == 0 {
+		return
+	}
+	var templates []map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &templates); err != nil {
+		return
+	}
+	templates = slices.DeleteFunc(templates, func(c map[string]interface{}) bool {
+		return slices.Contains(claims, fmt.Sprint(nestedMap(c, "metadata")["name"]))
+	})
+	out, _ := json.Marshal(templates)
+	annotations[volumeClaimTemplatesAnnotation] = string(out)
+}
+
+// hasCondition reports whether obj has status condition condType with status True.
+func hasCondition(obj map[string]interface{}, condType string) bool {
+	conds, _ := nestedMap(obj, "status")["conditions"].([]interface{})
+	for _, c := range conds {
+		m, _ := c.(map[string]interface{})
+		if m["type"] == condType && m["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// apiMessage returns the message of a Kubernetes Status body, or the body itself.
+func apiMessage(body []byte) string {
+	var st struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &st) == nil && st.Message != "" {
+		return st.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// deleteField removes m[key][field], and m[key] when it becomes empty.
+func deleteField(m map[string]interface{}, key, field string) {
+	if next, ok := m[key].(map[string]interface{}); ok {
+		delete(next, field)
+		if len(next) == 0 {
+			delete(m, key)
+		}
+	}
+}
+
+// ensureMap returns m[key] as a map, creating it when missing.
+func ensureMap(m map[string]interface{}, key string) map[string]interface{} {
+	if next, ok := m[key].(map[string]interface{}); ok {
+		return next
+	}
+	next := map[string]interface{}{}
+	m[key] = next
+	return next
+}
+
+// intField returns a numeric field of a decoded JSON object, defaulting to 1 for CPU topology fields.
+func intField(m map[string]interface{}, key string) int {
+	switch v := m[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	switch key {
+	case "cores", "sockets", "threads":
+		return 1
+	}
+	return 0
+}
+
+// entryName returns the name of a disk, volume, interface or network entry.
+func entryName(v interface{}) string {
+	m, _ := v.(map[string]interface{})
+	name, _ := m["name"].(string)
+	return name
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
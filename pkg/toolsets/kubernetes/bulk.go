@@ -0,0 +1,304 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+// maxBulkPreview bounds the object names listed in a bulk operation preview.
+const maxBulkPreview = 100
+
+// Bulk operations.
+const (
+	bulkDelete   = "delete"
+	bulkLabel    = "label"
+	bulkAnnotate = "annotate"
+)
+
+type bulkObject struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (o bulkObject) String() string {
+	if o.Namespace == "" {
+		return o.Name
+	}
+	return o.Namespace + "/" + o.Name
+}
+
+type bulkResult struct {
+	Object string `json:"object"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkTool builds the tool definition shared by the selector-based bulk operations.
+func bulkTool(name, description string, extra ...mcp.ToolOption) mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(description),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, apps/v1)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Pod, ConfigMap)")),
+		mcp.WithString("namespace", mcp.Description("Namespace (empty = all namespaces allowed by policy; omit for cluster-scoped)")),
+		mcp.WithString("label_selector", mcp.Description("Label selector (label_selector or field_selector is required)")),
+		mcp.WithString("field_selector", mcp.Description("Field selector (e.g. status.phase=Failed)")),
+	}
+	opts = append(opts, extra...)
+	opts = append(opts, mcp.WithString("confirm", mcp.Description("Confirmation token from the preview; omit to get a preview without changing anything")))
+	return mcp.NewTool(name, opts...)
+}
+
+func (t *Toolset) deleteCollectionTool() mcp.Tool {
+	return bulkTool(
+		"kubernetes_delete_collection",
+		"Delete all objects matching a label/field selector. The first call returns a preview (count and names) with a confirmation token; call again with confirm=<token> to delete. Refuses more than max-bulk-objects objects.",
+	)
+}
+
+func (t *Toolset) labelTool() mcp.Tool {
+	return bulkTool(
+		"kubernetes_label",
+		"Add, change or remove labels on all objects matching a label/field selector. The first call returns a preview with a confirmation token; call again with confirm=<token> to apply. Refuses more than max-bulk-objects objects.",
+		mcp.WithString("labels", mcp.Required(), mcp.Description("Changes as key=value pairs, key- to remove, comma-separated (e.g. team=shop,stale-), or a JSON object (null removes)")),
+	)
+}
+
+func (t *Toolset) annotateTool() mcp.Tool {
+	return bulkTool(
+		"kubernetes_annotate",
+		"Add, change or remove annotations on all objects matching a label/field selector. The first call returns a preview with a confirmation token; call again with confirm=<token> to apply. Refuses more than max-bulk-objects objects.",
+		mcp.WithString("annotations", mcp.Required(), mcp.Description("Changes as key=value pairs, key- to remove, comma-separated, or a JSON object (null removes; use JSON for values containing commas)")),
+	)
+}
+
+func (t *Toolset) deleteCollectionHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckDestructive(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return t.bulkHandler(ctx, req, bulkDelete, nil)
+}
+
+func (t *Toolset) labelHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	raw, err := req.RequireString("labels")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	changes, err := parseMetadataChanges(raw)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid labels: %v", err)), nil
+	}
+	return t.bulkHandler(ctx, req, bulkLabel, changes)
+}
+
+func (t *Toolset) annotateHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	raw, err := req.RequireString("annotations")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	changes, err := parseMetadataChanges(raw)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid annotations: %v", err)), nil
+	}
+	return t.bulkHandler(ctx, req, bulkAnnotate, changes)
+}
+
+// bulkHandler lists the objects matching the selectors and either returns a preview with a confirmation
+// token or, when confirm matches the token for the same objects and changes, applies op to each object.
+func (t *Toolset) bulkHandler(ctx context.Context, req mcp.CallToolRequest, op string, changes map[string]interface{}) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	apiVersion, err := req.RequireString("api_version")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	labelSelector := req.GetString("label_selector", "")
+	fieldSelector := req.GetString("field_selector", "")
+	confirm := req.GetString("confirm", "")
+	if labelSelector == "" && fieldSelector == "" {
+		return mcp.NewToolResultError("label_selector or field_selector is required for bulk operations"), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resourceType := rancher.SteveType(apiVersion, kind)
+	items, err := t.listAll(ctx, cluster, resourceType, rancher.ListOpts{Namespace: namespace, LabelSelector: labelSelector, FieldSelector: fieldSelector})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list %s: %v", kind, err)), nil
+	}
+	var objects []bulkObject
+	skipped := 0
+	for _, item := range items {
+		if t.policy.CheckNamespace(item.ObjectMeta.Namespace) != nil {
+			skipped++
+			continue
+		}
+		objects = append(objects, bulkObject{Namespace: item.ObjectMeta.Namespace, Name: item.ObjectMeta.Name})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].String() < objects[j].String() })
+	if len(objects) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No %s objects match (skipped %d in namespaces denied by policy)", kind, skipped)), nil
+	}
+	if err := t.policy.CheckBulk(len(objects)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	token := bulkToken(op, cluster, resourceType, objects, changes)
+
+	if confirm != token {
+		preview := map[string]interface{}{
+			"operation":     op,
+			"kind":          kind,
+			"count":         len(objects),
+			"max_objects":   t.policy.MaxBulkObjects,
+			"confirm_token": token,
+		}
+		names := make([]string, 0, min(len(objects), maxBulkPreview))
+		for _, o := range objects[:min(len(objects), maxBulkPreview)] {
+			names = append(names, o.String())
+		}
+		preview["objects"] = names
+		if skipped > 0 {
+			preview["skipped_by_policy"] = skipped
+		}
+		if changes != nil {
+			preview["changes"] = changes
+		}
+		if confirm == "" {
+			preview["message"] = fmt.Sprintf("Preview only: nothing was changed. Call again with confirm=%q to %s these %d objects.", token, op, len(objects))
+		} else {
+			preview["message"] = fmt.Sprintf("Confirmation token does not match the current %d matching objects (they changed since the preview, or the token is wrong). Nothing was changed; review this preview and confirm with %q.", len(objects), token)
+		}
+		out, err := t.formatter.Format(preview, "json")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+		}
+		if confirm != "" {
+			return mcp.NewToolResultError(out), nil
+		}
+		return mcp.NewToolResultText(out), nil
+	}
+
+	results := make([]bulkResult, 0, len(objects))
+	failed := 0
+	for _, o := range objects {
+		var err error
+		if op == bulkDelete {
+			err = t.client.Delete(ctx, cluster, resourceType, o.Namespace, o.Name)
+		} else {
+			err = t.patchMetadata(ctx, cluster, resourceType, o, op, changes)
+		}
+		r := bulkResult{Object: o.String()}
+		if err != nil {
+			r.Error = err.Error()
+			failed++
+		}
+		results = append(results, r)
+	}
+	return t.formatResult(map[string]interface{}{
+		"operation": op,
+		"kind":      kind,
+		"succeeded": len(objects) - failed,
+		"failed":    failed,
+		"results":   results,
+	}, "json")
+}
+
+// patchMetadata merge-patches labels (op label) or annotations (op annotate) of one object.
+func (t *Toolset) patchMetadata(ctx context.Context, cluster, resourceType string, o bulkObject, op string, changes map[string]interface{}) error {
+	field := "labels"
+	if op == bulkAnnotate {
+		field = "annotations"
+	}
+	path, err := rancher.ResourcePath(resourceType, o.Namespace, o.Name)
+	if err != nil {
+		return err
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{field: changes}})
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeMerge)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("patch %d: %s", status, apiErrorMessage(body))
+	}
+	return nil
+}
+
+// parseMetadataChanges parses "k=v,k2-" (kubectl label syntax) or a JSON object into a merge-patch map
+// where nil removes the key.
+func parseMetadataChanges(raw string) (map[string]interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	changes := map[string]interface{}{}
+	if strings.HasPrefix(raw, "{") {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			return nil, err
+		}
+		for k, v := range m {
+			switch v.(type) {
+			case nil, string:
+				changes[k] = v
+			default:
+				return nil, fmt.Errorf("value of %q must be a string or null", k)
+			}
+		}
+	} else {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if key, value, ok := strings.Cut(part, "="); ok {
+				changes[strings.TrimSpace(key)] = value
+			} else if key, ok := strings.CutSuffix(part, "-"); ok {
+				changes[key] = nil
+			} else {
+				return nil, fmt.Errorf("%q is neither key=value nor key-", part)
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no changes given")
+	}
+	for k := range changes {
+		if k == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+	}
+	return changes, nil
+}
+
+// bulkToken derives the confirmation token from the operation, target and exact object set, so a token is
+// only valid for the objects and changes that were previewed. It echoes the object count.
+func bulkToken(op, cluster, resourceType string, objects []bulkObject, changes map[string]interface{}) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", op, cluster, resourceType)
+	for _, o := range objects {
+		fmt.Fprintln(h, o.String())
+	}
+	c, _ := json.Marshal(changes) // map keys are marshalled in sorted order
+	h.Write(c)
+	return fmt.Sprintf("%s-%d-%s", op, len(objects), hex.EncodeToString(h.Sum(nil))[:8])
+}
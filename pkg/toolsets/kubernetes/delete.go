@@ -3,24 +3,34 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
 )
 
 func (t *Toolset) deleteTool() mcp.Tool {
 	return mcp.NewTool(
 		"kubernetes_delete",
-		mcp.WithDescription("Delete a Kubernetes resource by apiVersion, kind, namespace, name"),
+		mcp.WithDescription("Delete a Kubernetes resource by apiVersion, kind, namespace, name. Optionally set the propagation policy and grace period, and wait until the object is actually gone (reporting blocking finalizers on timeout)."),
 		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
 		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, apps/v1)")),
 		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Pod, Deployment)")),
 		mcp.WithString("namespace", mcp.Description("Namespace (for namespaced resources)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Resource name")),
+		mcp.WithString("propagation_policy", mcp.Description("Dependent handling: Foreground (delete dependents first), Background (server default for most kinds), Orphan (keep dependents)")),
+		mcp.WithNumber("grace_period_seconds", mcp.Description("Grace period; 0 deletes immediately (default: the object's own grace period)")),
+		mcp.WithBoolean("wait", mcp.Description("Wait until the object no longer exists (default: false)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Max seconds to wait when wait=true (default: 120, max: 1800)")),
 	)
 }
 
 func (t *Toolset) deleteHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckDestructive(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	cluster, err := req.RequireString("cluster")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -42,10 +52,49 @@ func (t *Toolset) deleteHandler(ctx context.Context, req mcp.CallToolRequest) (*
 	if err := t.policy.CheckNamespace(namespace); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	var opts rancher.DeleteOptions
+	if p := req.GetString("propagation_policy", ""); p != "" {
+		switch strings.ToLower(p) {
+		case "foreground":
+			opts.PropagationPolicy = rancher.PropagationForeground
+		case "background":
+			opts.PropagationPolicy = rancher.PropagationBackground
+		case "orphan":
+			opts.PropagationPolicy = rancher.PropagationOrphan
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid propagation_policy %q; allowed: Foreground, Background, Orphan", p)), nil
+		}
+	}
+	if args := req.GetArguments(); args["grace_period_seconds"] != nil {
+		grace := int64(req.GetInt("grace_period_seconds", 0))
+		if grace < 0 {
+			return mcp.NewToolResultError("grace_period_seconds must be >= 0"), nil
+		}
+		opts.GracePeriodSeconds = &grace
+	}
 
 	resourceType := rancher.SteveType(apiVersion, kind)
-	if err := t.client.Delete(ctx, cluster, resourceType, namespace, name); err != nil {
+	if err := t.client.DeleteWithOptions(ctx, cluster, resourceType, namespace, name, opts); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_delete: %v", err)), nil
 	}
-	return mcp.NewToolResultText(fmt.Sprintf("Deleted %s %q", kind, name)), nil
+	if !req.GetBool("wait", false) {
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted %s %q", kind, name)), nil
+	}
+
+	timeoutSec := req.GetInt("timeout_seconds", defaultWaitTimeoutSeconds)
+	if timeoutSec <= 0 {
+		timeoutSec = defaultWaitTimeoutSeconds
+	}
+	timeout := time.Duration(min(timeoutSec, maxWaitTimeoutSeconds)) * time.Second
+	target := wait.Target{Cluster: cluster, ResourceType: resourceType, Namespace: namespace, Name: name}
+	cond, _ := wait.ParseCondition(wait.KindDelete)
+	res, err := wait.Until(ctx, t.client, target, cond, timeout, wait.Progress(ctx, req, timeout))
+	if err != nil {
+		msg := fmt.Sprintf("%s %q delete requested but: %v", kind, name, err)
+		if res != nil && res.Object != nil && len(res.Object.ObjectMeta.Finalizers) > 0 {
+			msg += fmt.Sprintf(". Still terminating with finalizers %s; see kubernetes_remove_finalizers for what each one waits on", strings.Join(res.Object.ObjectMeta.Finalizers, ", "))
+		}
+		return mcp.NewToolResultError(msg), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted %s %q (gone after %ds)", kind, name, int(res.Elapsed.Seconds()))), nil
 }
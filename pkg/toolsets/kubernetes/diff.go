@@ -0,0 +1,606 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	maxDiffChanges = 200
+	diffContext    = 3
+	// maxDiffCells bounds the LCS table of a unified diff; larger inputs are shown as full replacement.
+	maxDiffCells = 4_000_000
+)
+
+// Diff operations.
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// errObjectNotFound is returned by getObject when the API answers 404.
+var errObjectNotFound = errors.New("not found")
+
+// serverManagedPaths are dotted paths set by the API server or controllers, removed before diffing.
+var serverManagedPaths = []string{
+	"status",
+	"metadata.managedFields",
+	"metadata.resourceVersion",
+	"metadata.uid",
+	"metadata.generation",
+	"metadata.creationTimestamp",
+	"metadata.deletionTimestamp",
+	"metadata.deletionGracePeriodSeconds",
+	"metadata.selfLink",
+	"metadata.namespace",
+	"metadata.annotations.kubectl.kubernetes.io/last-applied-configuration",
+	"metadata.annotations.deployment.kubernetes.io/revision",
+	"metadata.annotations.pv.kubernetes.io/bind-completed",
+	"metadata.annotations.pv.kubernetes.io/bound-by-controller",
+}
+
+// serverManagedKindPaths are server-assigned fields of specific kinds.
+var serverManagedKindPaths = map[string][]string{
+	"Service":               {"spec.clusterIP", "spec.clusterIPs"},
+	"PersistentVolumeClaim": {"spec.volumeName"},
+}
+
+type diffChange struct {
+	Path  string      `json:"path"`
+	Op    string      `json:"op"`
+	Left  interface{} `json:"left,omitempty"`
+	Right interface{} `json:"right,omitempty"`
+}
+
+func (t *Toolset) diffTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_diff",
+		mcp.WithDescription("Compare a resource across clusters or namespaces (e.g. staging vs prod). Server-managed fields (status, managedFields, resourceVersion, uid, timestamps, generation) are ignored. Compares one object, or all objects matching label_selector paired by name. Returns structured changes (json) or a unified YAML diff."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID of the left side")),
+		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, apps/v1)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Deployment, ConfigMap)")),
+		mcp.WithString("namespace", mcp.Description("Namespace of the left side (required for namespaced resources)")),
+		mcp.WithString("name", mcp.Description("Resource name (omit when using label_selector)")),
+		mcp.WithString("target_cluster", mcp.Description("Cluster ID of the right side (default: cluster)")),
+		mcp.WithString("target_namespace", mcp.Description("Namespace of the right side (default: namespace)")),
+		mcp.WithString("target_name", mcp.Description("Resource name on the right side (default: name)")),
+		mcp.WithString("label_selector", mcp.Description("Compare every object matching this selector on both sides, paired by name (namespace/name when namespace is omitted)")),
+		mcp.WithString("ignore_fields", mcp.Description("Comma-separated extra dotted paths to ignore (e.g. spec.replicas,metadata.labels)")),
+		mcp.WithString("format", mcp.Description("Output format: json (structured changes), unified (default: json)")),
+	)
+}
+
+func (t *Toolset) diffHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	apiVersion, err := req.RequireString("api_version")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	name := req.GetString("name", "")
+	targetCluster := req.GetString("target_cluster", cluster)
+	targetNamespace := req.GetString("target_namespace", namespace)
+	targetName := req.GetString("target_name", name)
+	selector := req.GetString("label_selector", "")
+	format := req.GetString("format", "json")
+	if (name == "") == (selector == "") {
+		return mcp.NewToolResultError("provide either name or label_selector"), nil
+	}
+	for _, ns := range []string{namespace, targetNamespace} {
+		if err := t.policy.CheckNamespace(ns); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	var ignore []string
+	for _, p := range strings.Split(req.GetString("ignore_fields", ""), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			ignore = append(ignore, p)
+		}
+	}
+	resourceType := rancher.SteveType(apiVersion, kind)
+	leftLabel := diffLocation(cluster, namespace)
+	rightLabel := diffLocation(targetCluster, targetNamespace)
+
+	if selector == "" {
+		left, err := t.getObject(ctx, cluster, resourceType, namespace, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s %q (%s): %v", kind, name, leftLabel, err)), nil
+		}
+		right, err := t.getObject(ctx, targetCluster, resourceType, targetNamespace, targetName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s %q (%s): %v", kind, targetName, rightLabel, err)), nil
+		}
+		left, right = t.normalizeObject(left, ignore), t.normalizeObject(right, ignore)
+		t.redactSecrets(left, right)
+		leftLabel += "/" + kind + "/" + name
+		rightLabel += "/" + kind + "/" + targetName
+		if format == "unified" {
+			return mcp.NewToolResultText(unifiedObjectDiff(leftLabel, rightLabel, left, right)), nil
+		}
+		changes, truncated := objectChanges(left, right)
+		return t.formatResult(map[string]interface{}{
+			"left":      leftLabel,
+			"right":     rightLabel,
+			"identical": len(changes) == 0,
+			"changes":   changes,
+			"truncated": truncated,
+		}, format)
+	}
+
+	// Across all namespaces objects are paired by namespace/name, so both sides must list the same way.
+	if (namespace == "") != (targetNamespace == "") {
+		return mcp.NewToolResultError("with label_selector, set both namespace and target_namespace or neither"), nil
+	}
+	leftItems, err := t.listObjects(ctx, cluster, resourceType, namespace, selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list %s (%s): %v", kind, leftLabel, err)), nil
+	}
+	rightItems, err := t.listObjects(ctx, targetCluster, resourceType, targetNamespace, selector)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list %s (%s): %v", kind, rightLabel, err)), nil
+	}
+	names := map[string]bool{}
+	for n := range leftItems {
+		names[n] = true
+	}
+	for n := range rightItems {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	onlyLeft, onlyRight, identical := []string{}, []string{}, []string{}
+	var unified []string
+	differing := []map[string]interface{}{}
+	for _, n := range sorted {
+		l, inLeft := leftItems[n]
+		r, inRight := rightItems[n]
+		switch {
+		case !inRight:
+			onlyLeft = append(onlyLeft, n)
+		case !inLeft:
+			onlyRight = append(onlyRight, n)
+		default:
+			l, r = t.normalizeObject(l, ignore), t.normalizeObject(r, ignore)
+			t.redactSecrets(l, r)
+			changes, truncated := objectChanges(l, r)
+			if len(changes) == 0 {
+				identical = append(identical, n)
+				continue
+			}
+			if format == "unified" {
+				unified = append(unified, unifiedObjectDiff(leftLabel+"/"+kind+"/"+n, rightLabel+"/"+kind+"/"+n, l, r))
+				continue
+			}
+			differing = append(differing, map[string]interface{}{"name": n, "changes": changes, "truncated": truncated})
+		}
+	}
+	if format == "unified" {
+		var b strings.Builder
+		for _, n := range onlyLeft {
+			fmt.Fprintf(&b, "only in %s: %s/%s\n", leftLabel, kind, n)
+		}
+		for _, n := range onlyRight {
+			fmt.Fprintf(&b, "only in %s: %s/%s\n", rightLabel, kind, n)
+		}
+		b.WriteString(strings.Join(unified, "\n"))
+		if b.Len() == 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("no differences (%d objects identical)", len(identical))), nil
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	return t.formatResult(map[string]interface{}{
+		"left":          leftLabel,
+		"right":         rightLabel,
+		"identical":     identical,
+		"different":     differing,
+		"only_in_left":  onlyLeft,
+		"only_in_right": onlyRight,
+	}, format)
+}
+
+func (t *Toolset) formatResult(data map[string]interface{}, format string) (*mcp.CallToolResult, error) {
+	out, err := t.formatter.Format(data, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+func diffLocation(cluster, namespace string) string {
+	if namespace == "" {
+		return cluster
+	}
+	return cluster + "/" + namespace
+}
+
+// getObject reads a full object through the raw API, so top-level payloads (ConfigMap data, RBAC rules)
+// that SteveResource drops take part in the diff.
+func (t *Toolset) getObject(ctx context.Context, cluster, resourceType, namespace, name string) (map[string]interface{}, error) {
+	path, err := rancher.ResourcePath(resourceType, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, errObjectNotFound
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("get %d: %s", status, string(body))
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return obj, nil
+}
+
+// listObjects reads all objects matching selector through the raw API, keyed by name, or by namespace/name
+// for namespaced objects when listing across all namespaces.
+func (t *Toolset) listObjects(ctx context.Context, cluster, resourceType, namespace, selector string) (map[string]map[string]interface{}, error) {
+	path, err := rancher.ResourcePath(resourceType, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"limit": {"500"}}
+	if selector != "" {
+		query.Set("labelSelector", selector)
+	}
+	out := map[string]map[string]interface{}{}
+	for page := 0; page < maxListPages; page++ {
+		body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, query, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("list %d: %s", status, string(body))
+		}
+		var list struct {
+			Items    []map[string]interface{} `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("decode: %w", err)
+		}
+		for _, item := range list.Items {
+			meta := nestedMap(item, "metadata")
+			name, _ := meta["name"].(string)
+			ns, _ := meta["namespace"].(string)
+			if t.policy.CheckNamespace(ns) != nil {
+				continue
+			}
+			if namespace == "" && ns != "" {
+				name = ns + "/" + name
+			}
+			out[name] = item
+		}
+		if list.Metadata.Continue == "" {
+			break
+		}
+		query.Set("continue", list.Metadata.Continue)
+	}
+	return out, nil
+}
+
+// normalizeObject removes server-managed and ignored fields.
+func (t *Toolset) normalizeObject(obj map[string]interface{}, ignore []string) map[string]interface{} {
+	kind, _ := obj["kind"].(string)
+	paths := append(append(append([]string{}, serverManagedPaths...), serverManagedKindPaths[kind]...), ignore...)
+	for _, p := range paths {
+		removePath(obj, p)
+	}
+	if meta := nestedMap(obj, "metadata"); meta != nil {
+		for _, key := range []string{"annotations", "labels"} {
+			if m, ok := meta[key].(map[string]interface{}); ok && len(m) == 0 {
+				delete(meta, key)
+			}
+		}
+		refs, _ := meta["ownerReferences"].([]interface{})
+		for _, r := range refs {
+			delete(asMap(r), "uid")
+		}
+	}
+	return obj
+}
+
+// Markers that replace Secret values in diffs unless sensitive data may be shown.
+const (
+	redactedValue        = "<redacted>"
+	redactedChangedValue = "<redacted, changed>"
+)
+
+// redactSecrets replaces the Secret values of two normalized objects (either may be nil) with fixed
+// markers, unless sensitive data may be shown. The values are compared here first, so a changed value
+// still shows up as a change without anything derived from it leaving the server.
+func (t *Toolset) redactSecrets(left, right map[string]interface{}) {
+	if t.policy.CanShowSecret() || (!isSecret(left) && !isSecret(right)) {
+		return
+	}
+	for _, key := range []string{"data", "stringData"} {
+		l, r := asMap(left[key]), asMap(right[key])
+		for k, v := range r {
+			if lv, ok := l[k]; ok && fmt.Sprint(lv) != fmt.Sprint(v) {
+				r[k] = redactedChangedValue
+			} else {
+				r[k] = redactedValue
+			}
+		}
+		for k := range l {
+			l[k] = redactedValue
+		}
+	}
+}
+
+func isSecret(obj map[string]interface{}) bool {
+	kind, _ := obj["kind"].(string)
+	return strings.EqualFold(kind, "Secret")
+}
+
+// removePath deletes a dotted path from m. Map keys may themselves contain dots (annotation keys),
+// so the longest key matching the remaining path is tried first.
+func removePath(m map[string]interface{}, path string) {
+	if m == nil {
+		return
+	}
+	if _, ok := m[path]; ok {
+		delete(m, path)
+		return
+	}
+	for i := strings.LastIndex(path, "."); i > 0; i = strings.LastIndex(path[:i], ".") {
+		if next, ok := m[path[:i]].(map[string]interface{}); ok {
+			removePath(next, path[i+1:])
+			return
+		}
+	}
+}
+
+// objectChanges lists the differences between two normalized objects, capped at maxDiffChanges.
+func objectChanges(left, right map[string]interface{}) ([]diffChange, bool) {
+	var changes []diffChange
+	diffValues("", left, right, &changes)
+	if len(changes) > maxDiffChanges {
+		return changes[:maxDiffChanges], true
+	}
+	return changes, false
+}
+
+// diffValues walks a and b in parallel. Lists whose elements all carry a unique name (containers, ports,
+// volumes) are matched by name, other lists by index.
+func diffValues(path string, a, b interface{}, out *[]diffChange) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			l, inA := av[k]
+			r, inB := bv[k]
+			switch {
+			case !inB:
+				*out = append(*out, diffChange{Path: join(k), Op: diffRemoved, Left: l})
+			case !inA:
+				*out = append(*out, diffChange{Path: join(k), Op: diffAdded, Right: r})
+			default:
+				diffValues(join(k), l, r, out)
+			}
+		}
+		return
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		if an, bn := namedItems(av), namedItems(bv); an != nil && bn != nil {
+			var names []string
+			for _, item := range av {
+				names = append(names, asMap(item)["name"].(string))
+			}
+			for _, item := range bv {
+				if n := asMap(item)["name"].(string); an[n] == nil {
+					names = append(names, n)
+				}
+			}
+			for _, n := range names {
+				p := fmt.Sprintf("%s[name=%s]", path, n)
+				switch {
+				case bn[n] == nil:
+					*out = append(*out, diffChange{Path: p, Op: diffRemoved, Left: an[n]})
+				case an[n] == nil:
+					*out = append(*out, diffChange{Path: p, Op: diffAdded, Right: bn[n]})
+				default:
+					diffValues(p, an[n], bn[n], out)
+				}
+			}
+			return
+		}
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(bv):
+				*out = append(*out, diffChange{Path: p, Op: diffRemoved, Left: av[i]})
+			case i >= len(av):
+				*out = append(*out, diffChange{Path: p, Op: diffAdded, Right: bv[i]})
+			default:
+				diffValues(p, av[i], bv[i], out)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*out = append(*out, diffChange{Path: path, Op: diffChanged, Left: a, Right: b})
+	}
+}
+
+// namedItems indexes list elements by their "name" field; nil when any element has no unique name.
+func namedItems(items []interface{}) map[string]map[string]interface{} {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make(map[string]map[string]interface{}, len(items))
+	for _, item := range items {
+		m := asMap(item)
+		name, _ := m["name"].(string)
+		if name == "" || out[name] != nil {
+			return nil
+		}
+		out[name] = m
+	}
+	return out
+}
+
+// unifiedObjectDiff renders both objects as YAML and returns a unified diff; a nil object renders as empty.
+func unifiedObjectDiff(leftLabel, rightLabel string, left, right map[string]interface{}) string {
+	toLines := func(obj map[string]interface{}) []string {
+		if obj == nil {
+			return nil
+		}
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return []string{fmt.Sprintf("# yaml: %v", err)}
+		}
+		return strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	}
+	diff := unifiedDiff(toLines(left), toLines(right))
+	if diff == "" {
+		return fmt.Sprintf("--- %s\n+++ %s\n(no differences)", leftLabel, rightLabel)
+	}
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", leftLabel, rightLabel, diff)
+}
+
+// unifiedDiff returns the hunks of a line-based unified diff with diffContext lines of context.
+func unifiedDiff(a, b []string) string {
+	type line struct {
+		op   byte // ' ', '-', '+'
+		text string
+	}
+	var lines []line
+	if len(a)*len(b) > maxDiffCells {
+		for _, s := range a {
+			lines = append(lines, line{'-', s})
+		}
+		for _, s := range b {
+			lines = append(lines, line{'+', s})
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(a) || j < len(b) {
+			switch {
+			case i < len(a) && j < len(b) && a[i] == b[j]:
+				lines = append(lines, line{' ', a[i]})
+				i++
+				j++
+			case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+				lines = append(lines, line{'-', a[i]})
+				i++
+			default:
+				lines = append(lines, line{'+', b[j]})
+				j++
+			}
+		}
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(lines); {
+		// Find the next change and extend the hunk while changes are within 2*diffContext lines.
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		end := first
+		for k := first; k < len(lines) && k <= end+2*diffContext; k++ {
+			if lines[k].op != ' ' {
+				end = k
+			}
+		}
+		from, to := max(first-diffContext, 0), min(end+diffContext+1, len(lines))
+		aStart, bStart := 1, 1
+		for _, l := range lines[:from] {
+			if l.op != '+' {
+				aStart++
+			}
+			if l.op != '-' {
+				bStart++
+			}
+		}
+		aLen, bLen := 0, 0
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, l := range lines[from:to] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
@@ -0,0 +1,238 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// dryRunFieldManager is the field manager used for server-side dry-run applies.
+	dryRunFieldManager = "rancher-mcp-server"
+	maxManifestObjects = 50
+)
+
+// clusterScopedKinds are common kinds that never take a namespace, even when the manifest has a default one.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"StorageClass":                   true,
+	"PriorityClass":                  true,
+	"IngressClass":                   true,
+	"RuntimeClass":                   true,
+	"CSIDriver":                      true,
+	"APIService":                     true,
+	"ValidatingWebhookConfiguration": true,
+	"MutatingWebhookConfiguration":   true,
+}
+
+// Manifest diff actions.
+const (
+	manifestCreate    = "create"
+	manifestUpdate    = "update"
+	manifestUnchanged = "unchanged"
+	manifestError     = "error"
+)
+
+type manifestDiff struct {
+	Object    string       `json:"object"`
+	Action    string       `json:"action"`
+	Changes   []diffChange `json:"changes,omitempty"`
+	Truncated bool         `json:"truncated,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	unified   string
+}
+
+func (t *Toolset) diffManifestTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_diff_manifest",
+		mcp.WithDescription("Preview what applying a manifest would change, like kubectl diff: each YAML/JSON document is server-side dry-run applied (nothing is persisted) and the result is compared with the live object. Reports objects to be created, per-object added/changed/removed fields, and unchanged objects."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("manifest", mcp.Required(), mcp.Description("YAML or JSON manifest; multiple documents separated by ---, or a List")),
+		mcp.WithString("namespace", mcp.Description("Namespace for namespaced objects without metadata.namespace (default: default)")),
+		mcp.WithString("format", mcp.Description("Output format: json (structured changes), unified (default: json)")),
+	)
+}
+
+func (t *Toolset) diffManifestHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	manifest, err := req.RequireString("manifest")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defaultNamespace := req.GetString("namespace", "default")
+	format := req.GetString("format", "json")
+
+	docs, err := parseManifest(manifest)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(docs) == 0 {
+		return mcp.NewToolResultError("manifest contains no objects"), nil
+	}
+	if len(docs) > maxManifestObjects {
+		return mcp.NewToolResultError(fmt.Sprintf("manifest has %d objects; at most %d are diffed per call", len(docs), maxManifestObjects)), nil
+	}
+
+	results := make([]manifestDiff, 0, len(docs))
+	counts := map[string]int{}
+	for _, doc := range docs {
+		d := t.diffManifestObject(ctx, cluster, doc, defaultNamespace)
+		counts[d.Action]++
+		results = append(results, d)
+	}
+
+	if format == "unified" {
+		var parts []string
+		for _, d := range results {
+			switch d.Action {
+			case manifestError:
+				parts = append(parts, fmt.Sprintf("# %s: error: %s", d.Object, d.Error))
+			case manifestUnchanged:
+				parts = append(parts, fmt.Sprintf("# %s: unchanged", d.Object))
+			default:
+				parts = append(parts, d.unified)
+			}
+		}
+		return mcp.NewToolResultText(strings.Join(parts, "\n")), nil
+	}
+	out, err := t.formatter.Format(map[string]interface{}{
+		"summary": map[string]int{
+			manifestCreate:    counts[manifestCreate],
+			manifestUpdate:    counts[manifestUpdate],
+			manifestUnchanged: counts[manifestUnchanged],
+			manifestError:     counts[manifestError],
+		},
+		"objects": results,
+	}, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// parseManifest decodes every YAML/JSON document of a manifest, expanding List kinds.
+func parseManifest(manifest string) ([]map[string]interface{}, error) {
+	dec := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	var docs []map[string]interface{}
+	for i := 1; ; i++ {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("manifest document %d: %v", i, err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		if kind, _ := doc["kind"].(string); strings.HasSuffix(kind, "List") {
+			if items, ok := doc["items"].([]interface{}); ok {
+				for _, item := range items {
+					if m := asMap(item); m != nil {
+						docs = append(docs, m)
+					}
+				}
+				continue
+			}
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// diffManifestObject dry-run applies one document and diffs the result against the live object.
+func (t *Toolset) diffManifestObject(ctx context.Context, cluster string, doc map[string]interface{}, defaultNamespace string) manifestDiff {
+	apiVersion, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+	meta := nestedMap(doc, "metadata")
+	name, _ := meta["name"].(string)
+	if apiVersion == "" || kind == "" || name == "" {
+		return manifestDiff{Object: fmt.Sprintf("%s/%s", kind, name), Action: manifestError, Error: "document must include apiVersion, kind and metadata.name"}
+	}
+	namespace, _ := meta["namespace"].(string)
+	if clusterScopedKinds[kind] {
+		namespace = ""
+	} else if namespace == "" {
+		namespace = defaultNamespace
+		meta["namespace"] = namespace
+	}
+	d := manifestDiff{Object: relatedID(kind, namespace, name)}
+	fail := func(err error) manifestDiff {
+		d.Action, d.Error = manifestError, err.Error()
+		return d
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return fail(err)
+	}
+
+	resourceType := rancher.SteveType(apiVersion, kind)
+	live, err := t.getObject(ctx, cluster, resourceType, namespace, name)
+	if err != nil && !errors.Is(err, errObjectNotFound) {
+		return fail(fmt.Errorf("get live object: %w", err))
+	}
+	path, err := rancher.ResourcePath(resourceType, namespace, name)
+	if err != nil {
+		return fail(err)
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fail(err)
+	}
+	query := url.Values{"dryRun": {"All"}, "fieldManager": {dryRunFieldManager}, "force": {"true"}}
+	resp, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, query, body, rancher.PatchTypeApply)
+	if err != nil {
+		return fail(fmt.Errorf("dry-run apply: %w", err))
+	}
+	if status < 200 || status >= 300 {
+		return fail(fmt.Errorf("dry-run apply %d: %s", status, apiErrorMessage(resp)))
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(resp, &merged); err != nil {
+		return fail(fmt.Errorf("decode dry-run result: %w", err))
+	}
+
+	merged = t.normalizeObject(merged, nil)
+	if live == nil {
+		t.redactSecrets(nil, merged)
+		d.Action = manifestCreate
+		d.unified = unifiedObjectDiff("/dev/null", d.Object, nil, merged)
+		return d
+	}
+	live = t.normalizeObject(live, nil)
+	t.redactSecrets(live, merged)
+	d.Changes, d.Truncated = objectChanges(live, merged)
+	if len(d.Changes) == 0 {
+		d.Action = manifestUnchanged
+		return d
+	}
+	d.Action = manifestUpdate
+	d.unified = unifiedObjectDiff("live/"+d.Object, "merged/"+d.Object, live, merged)
+	return d
+}
+
+// apiErrorMessage extracts the message of a Kubernetes Status error body, falling back to the raw body.
+func apiErrorMessage(body []byte) string {
+	var status struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &status) == nil && status.Message != "" {
+		return status.Message
+	}
+	return string(body)
+}
@@ -2,76 +2,408 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
 	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
 )
 
+// Event APIs accepted by kubernetes_events.
+const (
+	eventsAPIAuto   = "auto"
+	eventsAPICore   = "core"
+	eventsAPIEvents = "events.k8s.io"
+)
+
+// maxEventMessageLen truncates long event messages so aggregated output stays compact.
+const maxEventMessageLen = 500
+
 func (t *Toolset) eventsTool() mcp.Tool {
 	return mcp.NewTool(
 		"kubernetes_events",
-		mcp.WithDescription("List Kubernetes events in a namespace (optionally filtered by involvedObject)"),
+		mcp.WithDescription("List Kubernetes events, filtered and aggregated: events with the same reason, object and message are merged (counts summed) and sorted by last seen, newest first. Reads events.k8s.io/v1 (series) with fallback to core/v1."),
 		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
-		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
-		mcp.WithString("involved_object_name", mcp.Description("Filter by involvedObject.name")),
+		mcp.WithString("namespace", mcp.Description("Namespace (optional; empty = all allowed namespaces)")),
+		mcp.WithString("involved_object_kind", mcp.Description("Filter by involved object kind (e.g. Pod, Deployment)")),
+		mcp.WithString("involved_object_name", mcp.Description("Filter by involved object name")),
+		mcp.WithString("type", mcp.Description("Filter by type: Warning or Normal")),
+		mcp.WithString("reason", mcp.Description("Filter by reason (e.g. BackOff, FailedScheduling)")),
+		mcp.WithString("since", mcp.Description("Only events last seen within this duration (e.g. 30m, 2h)")),
+		mcp.WithBoolean("dedup", mcp.Description("Merge events by (reason, object, message) (default: true). With dedup=false events are listed raw, one API page of limit events at a time (see continue)")),
+		mcp.WithString("api", mcp.Description("Event API: auto, core, events.k8s.io (default: auto)")),
 		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
-		mcp.WithNumber("limit", mcp.Description("Max events (default: 50)")),
-		mcp.WithString("continue", mcp.Description("Pagination token from previous response (for next page)")),
+		mcp.WithNumber("limit", mcp.Description("Max events returned after aggregation, or the page size with dedup=false (default: 50)")),
+		mcp.WithString("continue", mcp.Description("dedup=false: pagination token from the previous response (for the next page)")),
 	)
 }
 
+// eventFilter narrows an event listing; Kind/Name/Type/Reason are applied server-side where the API supports it.
+type eventFilter struct {
+	Kind   string
+	Name   string
+	Type   string
+	Reason string
+	Since  time.Duration
+}
+
+func (f eventFilter) fieldSelector(objectField string) string {
+	var parts []string
+	if f.Kind != "" {
+		parts = append(parts, objectField+".kind="+f.Kind)
+	}
+	if f.Name != "" {
+		parts = append(parts, objectField+".name="+f.Name)
+	}
+	if f.Type != "" {
+		parts = append(parts, "type="+f.Type)
+	}
+	if f.Reason != "" {
+		parts = append(parts, "reason="+f.Reason)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f eventFilter) match(e eventRecord, cutoff time.Time) bool {
+	switch {
+	case f.Kind != "" && !strings.EqualFold(e.InvolvedObject.Kind, f.Kind),
+		f.Name != "" && e.InvolvedObject.Name != f.Name,
+		f.Type != "" && !strings.EqualFold(e.Type, f.Type),
+		f.Reason != "" && e.Reason != f.Reason,
+		f.Since > 0 && e.lastSeen().Before(cutoff):
+		return false
+	}
+	return true
+}
+
+// eventSummary is one (possibly merged) event in kubernetes_events output.
+type eventSummary struct {
+	Type        string `json:"type"`
+	Reason      string `json:"reason"`
+	Object      string `json:"object"`
+	Namespace   string `json:"namespace,omitempty"`
+	Message     string `json:"message"`
+	Count       int64  `json:"count"`
+	Occurrences int    `json:"occurrences,omitempty"`
+	FirstSeen   string `json:"first_seen,omitempty"`
+	LastSeen    string `json:"last_seen,omitempty"`
+	firstSeen   time.Time
+	lastSeen    time.Time
+}
+
 func (t *Toolset) eventsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	cluster, err := req.RequireString("cluster")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	namespace, err := req.RequireString("namespace")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+	namespace := req.GetString("namespace", "")
 	if err := t.policy.CheckNamespace(namespace); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	involvedName := req.GetString("involved_object_name", "")
+	filter := eventFilter{
+		Kind:   req.GetString("involved_object_kind", ""),
+		Name:   req.GetString("involved_object_name", ""),
+		Type:   req.GetString("type", ""),
+		Reason: req.GetString("reason", ""),
+	}
+	if since := req.GetString("since", ""); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid since %q: use a duration like 30m or 2h", since)), nil
+		}
+		filter.Since = d
+	}
+	api := req.GetString("api", eventsAPIAuto)
+	dedup := req.GetBool("dedup", true)
 	format := req.GetString("format", "json")
 	limit := req.GetInt("limit", 50)
 	if limit <= 0 {
 		limit = 50
 	}
-	continueToken := req.GetString("continue", "")
-
-	opts := rancher.ListOpts{Namespace: namespace, Limit: limit, Continue: continueToken}
-	if involvedName != "" {
-		opts.FieldSelector = fmt.Sprintf("involvedObject.name=%s", involvedName)
+	// Raw listings page through the API like other list tools; aggregation needs every event.
+	var page *eventPage
+	if continueToken := req.GetString("continue", ""); !dedup {
+		page = &eventPage{Limit: limit, Continue: continueToken}
+	} else if continueToken != "" {
+		return mcp.NewToolResultError("continue pages the raw listing; set dedup=false"), nil
 	}
-	col, err := t.client.List(ctx, cluster, rancher.TypeEvents, opts)
+
+	events, next, err := t.fetchEvents(ctx, cluster, namespace, api, filter, page)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("list events: %v", err)), nil
 	}
-	items := make([]map[string]interface{}, 0, len(col.Data))
-	for _, e := range col.Data {
-		items = append(items, map[string]interface{}{
-			"name":           e.ObjectMeta.Name,
-			"namespace":      e.ObjectMeta.Namespace,
-			"reason":         getEventReason(e),
-			"message":        getEventMessage(e),
-			"count":          getEventCount(e),
-			"lastTimestamp":  getEventLastTimestamp(e),
-			"involvedObject": getEventInvolvedObject(e),
-		})
-	}
-	out, err := formatter.FormatListWithContinue(t.formatter, items, col.Continue, format)
+	cutoff := time.Now().Add(-filter.Since)
+	groups := map[string]*eventSummary{}
+	var items []*eventSummary
+	for i, e := range events {
+		if !filter.match(e, cutoff) || t.policy.CheckNamespace(e.Metadata.Namespace) != nil {
+			continue
+		}
+		obj := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+		key := strings.Join([]string{e.Metadata.Namespace, e.Reason, obj, e.Message}, "\x00")
+		if !dedup {
+			key = fmt.Sprint(i)
+		}
+		count := e.Count
+		if e.Series != nil && e.Series.Count > count {
+			count = e.Series.Count
+		}
+		if count == 0 {
+			count = 1
+		}
+		first, last := e.firstSeen(), e.lastSeen()
+		if g := groups[key]; g != nil {
+			g.Count += count
+			g.Occurrences++
+			if !first.IsZero() && (g.firstSeen.IsZero() || first.Before(g.firstSeen)) {
+				g.firstSeen = first
+			}
+			if last.After(g.lastSeen) {
+				g.lastSeen = last
+			}
+			continue
+		}
+		msg := e.Message
+		if len(msg) > maxEventMessageLen {
+			msg = msg[:maxEventMessageLen] + "..."
+		}
+		s := &eventSummary{
+			Type: e.Type, Reason: e.Reason, Object: obj, Namespace: e.Metadata.Namespace,
+			Message: msg, Count: count, Occurrences: 1, firstSeen: first, lastSeen: last,
+		}
+		groups[key] = s
+		items = append(items, s)
+	}
+	for _, s := range items {
+		if !s.firstSeen.IsZero() {
+			s.FirstSeen = s.firstSeen.Format(time.RFC3339)
+		}
+		if !s.lastSeen.IsZero() {
+			s.LastSeen = s.lastSeen.Format(time.RFC3339)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].lastSeen.After(items[j].lastSeen) })
+	total := len(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	if format == formatter.FormatTable {
+		rows := make([][]string, 0, len(items))
+		for _, e := range items {
+			rows = append(rows, []string{e.LastSeen, e.Type, e.Reason, strings.Trim(e.Namespace+"/"+e.Object, "/"), fmt.Sprint(e.Count), e.Message})
+		}
+		return mcp.NewToolResultText(renderTable([]string{"LAST SEEN", "TYPE", "REASON", "OBJECT", "COUNT", "MESSAGE"}, rows)), nil
+	}
+	data := map[string]interface{}{
+		"total":  total,
+		"events": items,
+	}
+	if next != "" {
+		data["continue"] = next
+	}
+	out, err := t.formatter.Format(data, format)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
 	}
 	return mcp.NewToolResultText(out), nil
 }
 
-func getEventInvolvedObject(e rancher.SteveResource) interface{} {
-	if m, ok := e.Status.(map[string]interface{}); ok {
-		return m["involvedObject"]
+// eventPage selects a single page of an event listing.
+type eventPage struct {
+	Limit    int
+	Continue string
+}
+
+// fetchEvents lists events from the requested API. auto prefers events.k8s.io/v1 and falls back to core/v1.
+// With page nil it reads every page; otherwise only the requested one and returns the next continue token.
+func (t *Toolset) fetchEvents(ctx context.Context, cluster, namespace, api string, f eventFilter, page *eventPage) ([]eventRecord, string, error) {
+	switch api {
+	case eventsAPICore:
+		return t.listEvents(ctx, cluster, namespace, f.fieldSelector("involvedObject"), page)
+	case eventsAPIEvents:
+		return t.listEventsV1(ctx, cluster, namespace, f.fieldSelector("regarding"), page)
+	case eventsAPIAuto, "":
+		events, next, err := t.listEventsV1(ctx, cluster, namespace, f.fieldSelector("regarding"), page)
+		if err != nil && strings.Contains(err.Error(), "404") {
+			return t.listEvents(ctx, cluster, namespace, f.fieldSelector("involvedObject"), page)
+		}
+		return events, next, err
+	}
+	return nil, "", fmt.Errorf("unsupported api %q; use auto, core or events.k8s.io", api)
+}
+
+// eventRecord is a core/v1 Event. Events keep reason/message/count at the top level, which SteveResource drops,
+// so they are read from the native API.
+type eventRecord struct {
+	Metadata       rancher.ObjectMeta `json:"metadata"`
+	Type           string             `json:"type"`
+	Reason         string             `json:"reason"`
+	Message        string             `json:"message"`
+	Count          int64              `json:"count"`
+	FirstTimestamp string             `json:"firstTimestamp"`
+	LastTimestamp  string             `json:"lastTimestamp"`
+	EventTime      string             `json:"eventTime"`
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"involvedObject"`
+	Series *struct {
+		Count            int64  `json:"count"`
+		LastObservedTime string `json:"lastObservedTime"`
+	} `json:"series,omitempty"`
+}
+
+// lastSeen returns when the event last occurred (lastTimestamp, series, eventTime, then creation time).
+func (e eventRecord) lastSeen() time.Time {
+	return firstTime(e.LastTimestamp, seriesLastObserved(e), e.EventTime, e.Metadata.CreationTimestamp)
+}
+
+// firstSeen returns when the event first occurred (firstTimestamp, eventTime, then creation time).
+func (e eventRecord) firstSeen() time.Time {
+	return firstTime(e.FirstTimestamp, e.EventTime, e.Metadata.CreationTimestamp)
+}
+
+// firstTime returns the first value that parses as RFC 3339 (micro-time included).
+func firstTime(values ...string) time.Time {
+	for _, s := range values {
+		if s == "" {
+			continue
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
+
+func seriesLastObserved(e eventRecord) string {
+	if e.Series != nil {
+		return e.Series.LastObservedTime
+	}
+	return ""
+}
+
+// eventV1Record is an events.k8s.io/v1 Event.
+type eventV1Record struct {
+	Metadata                 rancher.ObjectMeta `json:"metadata"`
+	Type                     string             `json:"type"`
+	Reason                   string             `json:"reason"`
+	Note                     string             `json:"note"`
+	EventTime                string             `json:"eventTime"`
+	DeprecatedCount          int64              `json:"deprecatedCount"`
+	DeprecatedFirstTimestamp string             `json:"deprecatedFirstTimestamp"`
+	DeprecatedLastTimestamp  string             `json:"deprecatedLastTimestamp"`
+	Regarding                struct {
+		Kind      string `json:"kind"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"regarding"`
+	Series *struct {
+		Count            int64  `json:"count"`
+		LastObservedTime string `json:"lastObservedTime"`
+	} `json:"series,omitempty"`
+}
+
+// record maps an events.k8s.io/v1 Event onto the core/v1 shape.
+func (e eventV1Record) record() eventRecord {
+	r := eventRecord{
+		Metadata:       e.Metadata,
+		Type:           e.Type,
+		Reason:         e.Reason,
+		Message:        e.Note,
+		Count:          e.DeprecatedCount,
+		FirstTimestamp: e.DeprecatedFirstTimestamp,
+		LastTimestamp:  e.DeprecatedLastTimestamp,
+		EventTime:      e.EventTime,
+		Series:         e.Series,
+	}
+	r.InvolvedObject.Kind = e.Regarding.Kind
+	r.InvolvedObject.Namespace = e.Regarding.Namespace
+	r.InvolvedObject.Name = e.Regarding.Name
+	return r
+}
+
+// listEvents reads core/v1 events in namespace (empty = all namespaces), following continue tokens.
+func (t *Toolset) listEvents(ctx context.Context, cluster, namespace, fieldSelector string, page *eventPage) ([]eventRecord, string, error) {
+	var events []eventRecord
+	next, err := t.listEventPages(ctx, cluster, "/api/v1", namespace, fieldSelector, page, func(body []byte) (string, error) {
+		var list struct {
+			Items    []eventRecord `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return "", fmt.Errorf("decode events: %w", err)
+		}
+		events = append(events, list.Items...)
+		return list.Metadata.Continue, nil
+	})
+	return events, next, err
+}
+
+// listEventsV1 reads events.k8s.io/v1 events and converts them to eventRecord.
+func (t *Toolset) listEventsV1(ctx context.Context, cluster, namespace, fieldSelector string, page *eventPage) ([]eventRecord, string, error) {
+	var events []eventRecord
+	next, err := t.listEventPages(ctx, cluster, "/apis/events.k8s.io/v1", namespace, fieldSelector, page, func(body []byte) (string, error) {
+		var list struct {
+			Items    []eventV1Record `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return "", fmt.Errorf("decode events: %w", err)
+		}
+		for _, e := range list.Items {
+			events = append(events, e.record())
+		}
+		return list.Metadata.Continue, nil
+	})
+	return events, next, err
+}
+
+// listEventPages GETs <groupPath>[/namespaces/<ns>]/events page by page; decode returns the continue token.
+// With page set only that page is read, and its continue token is returned.
+func (t *Toolset) listEventPages(ctx context.Context, cluster, groupPath, namespace, fieldSelector string, page *eventPage, decode func([]byte) (string, error)) (string, error) {
+	apiPath := groupPath + "/events"
+	if namespace != "" {
+		apiPath = groupPath + "/namespaces/" + namespace + "/events"
+	}
+	query := url.Values{"limit": {"500"}}
+	if fieldSelector != "" {
+		query.Set("fieldSelector", fieldSelector)
+	}
+	if page != nil {
+		query.Set("limit", fmt.Sprint(page.Limit))
+		if page.Continue != "" {
+			query.Set("continue", page.Continue)
+		}
+	}
+	for i := 0; i < maxListPages; i++ {
+		body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, apiPath, query, nil, "")
+		if err != nil {
+			return "", err
+		}
+		if code < 200 || code >= 300 {
+			return "", fmt.Errorf("HTTP %d: %s", code, strings.TrimSpace(string(body)))
+		}
+		next, err := decode(body)
+		if err != nil {
+			return "", err
+		}
+		if next == "" || page != nil {
+			return next, nil
+		}
+		query.Set("continue", next)
 	}
-	return nil
+	return "", nil
 }
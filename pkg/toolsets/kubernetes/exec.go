@@ -0,0 +1,228 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/internal/security"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+const (
+	defaultExecTimeoutSeconds = 30
+	maxExecTimeoutSeconds     = 300
+	defaultExecMaxBytes       = 64 * 1024
+	maxExecMaxBytes           = 1024 * 1024
+)
+
+// secretEnvHints are substrings of env var names whose values are redacted from env/printenv output.
+var secretEnvHints = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE", "API_KEY", "ACCESS_KEY", "AUTH"}
+
+func (t *Toolset) execTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_exec",
+		mcp.WithDescription("Run a non-interactive diagnostic command in a pod container (pods/exec over WebSocket). No shell and no stdin: the command is split on whitespace and its executable must be in the exec allowlist. Returns stdout, stderr and exit code. Unless show-sensitive-data is enabled, values of secret env vars are redacted from env/printenv output and /proc/<pid>/environ and service account token paths are refused."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the pod")),
+		mcp.WithString("pod", mcp.Required(), mcp.Description("Pod name")),
+		mcp.WithString("container", mcp.Description("Container name (optional; default is first/only container)")),
+		mcp.WithString("command", mcp.Required(), mcp.Description("Command and arguments, e.g. 'cat /etc/resolv.conf' or 'ls -la /data'")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Timeout in seconds (default: 30, max: 300)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Cap on combined stdout+stderr bytes (default: 65536, max: 1048576)")),
+	)
+}
+
+func (t *Toolset) execHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pod, err := req.RequireString("pod")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	commandStr, err := req.RequireString("command")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	command := strings.Fields(commandStr)
+	if err := t.policy.CheckExec(command); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	container := req.GetString("container", "")
+	timeoutSeconds := req.GetInt("timeout_seconds", defaultExecTimeoutSeconds)
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultExecTimeoutSeconds
+	}
+	if timeoutSeconds > maxExecTimeoutSeconds {
+		timeoutSeconds = maxExecTimeoutSeconds
+	}
+	maxBytes := req.GetInt("max_bytes", defaultExecMaxBytes)
+	if maxBytes <= 0 {
+		maxBytes = defaultExecMaxBytes
+	}
+	if maxBytes > maxExecMaxBytes {
+		maxBytes = maxExecMaxBytes
+	}
+
+	res, err := t.client.Exec(ctx, cluster, namespace, pod, rancher.ExecOptions{
+		Container: container,
+		Command:   command,
+		Timeout:   time.Duration(timeoutSeconds) * time.Second,
+		MaxBytes:  maxBytes,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("exec: %v", err)), nil
+	}
+	// Redaction follows the command env would run, so "env printenv NAME" is treated like "printenv NAME".
+	effective := command
+	for path.Base(effective[0]) == "env" {
+		wrapped := security.EnvCommand(effective[1:])
+		if len(wrapped) == 0 {
+			break
+		}
+		effective = wrapped
+	}
+	if bin := path.Base(effective[0]); (bin == "env" || bin == "printenv" || path.Base(command[0]) == "env") && !t.policy.CanShowSecret() {
+		secretNames := t.secretEnvNames(ctx, cluster, namespace, pod, container)
+		res.Stdout = redactEnvOutput(res.Stdout, secretNames)
+		if bin == "printenv" && printsSecret(effective[1:], secretNames) {
+			// printenv NAME prints bare values, and skips unset names, so lines cannot be matched to names.
+			res.Stdout = redactAll(res.Stdout)
+		}
+	}
+	out, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// secretEnvNames returns env var names the container sources from Secrets (env[].valueFrom.secretKeyRef
+// and envFrom[].secretRef). Lookup failures are ignored; name-based redaction still applies.
+func (t *Toolset) secretEnvNames(ctx context.Context, cluster, namespace, pod, container string) map[string]bool {
+	names := map[string]bool{}
+	res, err := t.client.Get(ctx, cluster, "core.v1.pods", namespace, pod)
+	if err != nil {
+		return names
+	}
+	containers, _ := asMap(res.Spec)["containers"].([]interface{})
+	for i, c := range containers {
+		cm := asMap(c)
+		if (container != "" && cm["name"] != container) || (container == "" && i > 0) {
+			continue
+		}
+		env, _ := cm["env"].([]interface{})
+		for _, e := range env {
+			em := asMap(e)
+			if nestedMap(em, "valueFrom", "secretKeyRef") != nil {
+				if name, _ := em["name"].(string); name != "" {
+					names[name] = true
+				}
+			}
+		}
+		envFrom, _ := cm["envFrom"].([]interface{})
+		for _, ef := range envFrom {
+			efm := asMap(ef)
+			secretName, _ := nestedMap(efm, "secretRef")["name"].(string)
+			if secretName == "" {
+				continue
+			}
+			prefix, _ := efm["prefix"].(string)
+			body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, "/api/v1/namespaces/"+namespace+"/secrets/"+secretName, nil, nil, "")
+			if err != nil || code != http.StatusOK {
+				continue
+			}
+			var secret struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if json.Unmarshal(body, &secret) != nil {
+				continue
+			}
+			for key := range secret.Data {
+				names[prefix+key] = true
+			}
+		}
+	}
+	return names
+}
+
+// redactEnvOutput replaces the value of NAME=value lines whose name is in secretNames or looks secret.
+// Lines that do not start a new NAME= belong to the previous multi-line value and are dropped with it.
+func redactEnvOutput(out string, secretNames map[string]bool) string {
+	lines := strings.Split(out, "\n")
+	kept := lines[:0]
+	redacting := false
+	for i, line := range lines {
+		name, _, ok := strings.Cut(line, "=")
+		if !ok || !isEnvName(name) {
+			if !redacting || (line == "" && i == len(lines)-1) {
+				kept = append(kept, line)
+			}
+			continue
+		}
+		redacting = secretNames[name] || looksSecret(name)
+		if redacting {
+			line = name + "=<redacted>"
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// isEnvName reports whether name is a shell-style env var name, i.e. whether a line starts a new NAME=value.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// printsSecret reports whether printenv arguments name a secret env var.
+func printsSecret(args []string, secretNames map[string]bool) bool {
+	for _, name := range args {
+		if !strings.HasPrefix(name, "-") && (secretNames[name] || looksSecret(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAll replaces every non-empty output line.
+func redactAll(out string) string {
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "<redacted>"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func looksSecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, hint := range secretEnvHints {
+		if strings.Contains(upper, hint) {
+			return true
+		}
+	}
+	return false
+}
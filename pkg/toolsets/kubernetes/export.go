@@ -0,0 +1,324 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultExportMaxBytes = 256 * 1024
+	maxExportMaxBytes     = 4 * 1024 * 1024
+)
+
+// Secret handling modes of kubernetes_export.
+const (
+	exportSecretsExclude = "exclude"
+	exportSecretsRedact  = "redact"
+	exportSecretsInclude = "include"
+)
+
+type exportKind struct {
+	apiVersion, kind string
+}
+
+// isSecret reports whether k resolves to core Secrets, whatever its spelling.
+func (k exportKind) isSecret() bool {
+	return rancher.SteveType(strings.ToLower(k.apiVersion), k.kind) == "core.v1.secrets"
+}
+
+// defaultExportKinds are the namespaced kinds exported when none are requested, in an order that applies
+// cleanly (identities and config before the workloads that reference them).
+var defaultExportKinds = []exportKind{
+	{"v1", "ServiceAccount"},
+	{"v1", "Secret"},
+	{"v1", "ConfigMap"},
+	{"v1", "PersistentVolumeClaim"},
+	{"rbac.authorization.k8s.io/v1", "Role"},
+	{"rbac.authorization.k8s.io/v1", "RoleBinding"},
+	{"v1", "Service"},
+	{"apps/v1", "Deployment"},
+	{"apps/v1", "StatefulSet"},
+	{"apps/v1", "DaemonSet"},
+	{"batch/v1", "CronJob"},
+	{"networking.k8s.io/v1", "Ingress"},
+	{"networking.k8s.io/v1", "NetworkPolicy"},
+	{"autoscaling/v2", "HorizontalPodAutoscaler"},
+	{"policy/v1", "PodDisruptionBudget"},
+}
+
+// exportStripPaths are removed from every exported object in addition to serverManagedPaths
+// (metadata.namespace is kept so the export re-applies to the same namespace).
+var exportStripPaths = []string{
+	"metadata.ownerReferences",
+	"metadata.finalizers",
+}
+
+// exportKindPaths are cluster-assigned fields of specific kinds that would conflict when re-applied.
+var exportKindPaths = map[string][]string{
+	"Service":               {"spec.clusterIP", "spec.clusterIPs", "spec.healthCheckNodePort"},
+	"PersistentVolumeClaim": {"spec.volumeName"},
+	"Job":                   {"spec.selector"},
+}
+
+// exportAnnotationPrefixes match annotations written by Rancher, controllers or the volume binder.
+var exportAnnotationPrefixes = []string{
+	"field.cattle.io/",
+	"cattle.io/",
+	"lifecycle.cattle.io/",
+	"objectset.rio.cattle.io/",
+	"pv.kubernetes.io/",
+	"volume.kubernetes.io/",
+	"volume.beta.kubernetes.io/",
+	"control-plane.alpha.kubernetes.io/",
+	"autoscaling.alpha.kubernetes.io/",
+}
+
+// jobGeneratedLabels are added to a Job's pod template by the Job controller.
+var jobGeneratedLabels = []string{"controller-uid", "job-name", "batch.kubernetes.io/controller-uid", "batch.kubernetes.io/job-name"}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (t *Toolset) exportTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_export",
+		mcp.WithDescription("Export the resources of a namespace as clean, re-applyable multi-document YAML (backup, migration, GitOps bootstrap). Status, uids, managedFields, resourceVersions, owner references and cluster-specific annotations and fields are stripped; objects generated by a controller (owned ReplicaSets, Jobs, token Secrets, the default ServiceAccount) are skipped. Secrets are excluded by default. Returned inline up to max_bytes, or written to the configured export directory."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace to export")),
+		mcp.WithString("kinds", mcp.Description("Comma-separated kinds, as Kind (default set) or apiVersion/Kind (e.g. Deployment,ConfigMap,longhorn.io/v1beta2/RecurringJob); default: common workload, config, network and RBAC kinds")),
+		mcp.WithString("secrets", mcp.Description("Secrets: exclude, redact (keys kept, values replaced), include (requires show-sensitive-data) (default: exclude)")),
+		mcp.WithString("output", mcp.Description("inline (return the YAML) or file (write to the export directory and return its path) (default: inline)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max size of inline output (default: 262144, max: 4194304)")),
+	)
+}
+
+func (t *Toolset) exportHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	secrets := strings.ToLower(req.GetString("secrets", exportSecretsExclude))
+	switch secrets {
+	case exportSecretsExclude, exportSecretsRedact:
+	case exportSecretsInclude:
+		if !t.policy.CanShowSecret() {
+			return mcp.NewToolResultError("secrets=include requires show-sensitive-data; use secrets=redact to export Secret keys without values"), nil
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid secrets %q; allowed: exclude, redact, include", secrets)), nil
+	}
+	output := req.GetString("output", "inline")
+	switch output {
+	case "inline":
+	case "file":
+		if t.policy.ExportDir == "" {
+			return mcp.NewToolResultError("output=file requires an export directory (--export-dir); use output=inline"), nil
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid output %q; allowed: inline, file", output)), nil
+	}
+	maxBytes := req.GetInt("max_bytes", defaultExportMaxBytes)
+	if maxBytes <= 0 {
+		maxBytes = defaultExportMaxBytes
+	}
+	maxBytes = min(maxBytes, maxExportMaxBytes)
+	kinds, err := parseExportKinds(req.GetString("kinds", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var docs []string
+	counts := map[string]int{}
+	skipped := map[string]int{}
+	var warnings []string
+	for _, k := range kinds {
+		if k.isSecret() && secrets == exportSecretsExclude {
+			continue
+		}
+		items, err := t.listObjects(ctx, cluster, rancher.SteveType(k.apiVersion, k.kind), namespace, "")
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", k.kind, err))
+			continue
+		}
+		names := make([]string, 0, len(items))
+		for name := range items {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			obj := items[name]
+			if reason := exportSkipReason(k.kind, obj); reason != "" {
+				skipped[reason]++
+				continue
+			}
+			// Lists omit apiVersion/kind on items.
+			obj["apiVersion"], obj["kind"] = k.apiVersion, k.kind
+			out, err := yaml.Marshal(t.cleanForExport(obj, secrets))
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s/%s: %v", k.kind, name, err))
+				continue
+			}
+			docs = append(docs, string(out))
+			counts[k.kind]++
+		}
+	}
+
+	header := fmt.Sprintf("# Exported from cluster %s, namespace %s at %s (%d objects)\n", cluster, namespace, time.Now().UTC().Format(time.RFC3339), len(docs))
+	if secrets == exportSecretsRedact && counts["Secret"] > 0 {
+		header += "# Secret values are redacted; fill them in before applying.\n"
+	}
+	for _, w := range warnings {
+		header += "# warning: " + strings.ReplaceAll(w, "\n", " ") + "\n"
+	}
+	manifest := header + strings.Join(docs, "---\n")
+
+	if output == "file" {
+		name := unsafeFileChars.ReplaceAllString(fmt.Sprintf("%s_%s_%s.yaml", cluster, namespace, time.Now().UTC().Format("20060102-150405")), "_")
+		if err := os.MkdirAll(t.policy.ExportDir, 0o700); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("kubernetes_export: %v", err)), nil
+		}
+		path := filepath.Join(t.policy.ExportDir, name)
+		if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("kubernetes_export: %v", err)), nil
+		}
+		report := map[string]interface{}{
+			"path":    path,
+			"bytes":   len(manifest),
+			"objects": counts,
+		}
+		if len(skipped) > 0 {
+			report["skipped"] = skipped
+		}
+		if len(warnings) > 0 {
+			report["warnings"] = warnings
+		}
+		return t.formatResult(report, "json")
+	}
+	if len(manifest) > maxBytes {
+		return mcp.NewToolResultError(fmt.Sprintf("export is %d bytes, more than max_bytes=%d (%d objects); select fewer kinds or use output=file", len(manifest), maxBytes, len(docs))), nil
+	}
+	return mcp.NewToolResultText(manifest), nil
+}
+
+// parseExportKinds resolves the kinds argument; bare kinds are looked up in defaultExportKinds
+// (plus Job), anything else must be given as apiVersion/Kind.
+func parseExportKinds(raw string) ([]exportKind, error) {
+	if strings.TrimSpace(raw) == "" {
+		return defaultExportKinds, nil
+	}
+	known := append(append([]exportKind{}, defaultExportKinds...), exportKind{"batch/v1", "Job"})
+	var kinds []exportKind
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if i := strings.LastIndex(part, "/"); i >= 0 {
+			if i == 0 || i == len(part)-1 {
+				return nil, fmt.Errorf("invalid kind %q; use Kind or apiVersion/Kind", part)
+			}
+			k := exportKind{part[:i], part[i+1:]}
+			// Use the canonical spelling of known kinds so checks on the kind (Secret) see v1/secrets too.
+			for _, c := range known {
+				if rancher.SteveType(c.apiVersion, c.kind) == rancher.SteveType(strings.ToLower(k.apiVersion), k.kind) {
+					k = c
+					break
+				}
+			}
+			kinds = append(kinds, k)
+			continue
+		}
+		found := false
+		for _, k := range known {
+			if strings.EqualFold(k.kind, part) {
+				kinds = append(kinds, k)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown kind %q; give it as apiVersion/Kind (e.g. apps/v1/Deployment)", part)
+		}
+	}
+	return kinds, nil
+}
+
+// exportSkipReason reports why an object is recreated by the cluster rather than exported, or "".
+func exportSkipReason(kind string, obj map[string]interface{}) string {
+	meta := nestedMap(obj, "metadata")
+	name, _ := meta["name"].(string)
+	refs, _ := meta["ownerReferences"].([]interface{})
+	for _, r := range refs {
+		if controller, _ := asMap(r)["controller"].(bool); controller {
+			return "owned by a controller"
+		}
+	}
+	switch {
+	case kind == "ServiceAccount" && name == "default":
+		return "default ServiceAccount"
+	case kind == "ConfigMap" && name == "kube-root-ca.crt":
+		return "cluster CA ConfigMap"
+	case kind == "Secret" && obj["type"] == "kubernetes.io/service-account-token":
+		return "service account token"
+	case kind == "Secret" && obj["type"] == "helm.sh/release.v1":
+		return "Helm release record"
+	}
+	return ""
+}
+
+// cleanForExport strips server-managed and cluster-specific fields so the object can be re-applied,
+// and handles Secret data according to the secrets mode.
+func (t *Toolset) cleanForExport(obj map[string]interface{}, secrets string) map[string]interface{} {
+	kind, _ := obj["kind"].(string)
+	for _, p := range append(append(append([]string{}, serverManagedPaths...), exportStripPaths...), exportKindPaths[kind]...) {
+		if p != "metadata.namespace" {
+			removePath(obj, p)
+		}
+	}
+	meta := nestedMap(obj, "metadata")
+	if annotations, ok := meta["annotations"].(map[string]interface{}); ok {
+		for key := range annotations {
+			for _, prefix := range exportAnnotationPrefixes {
+				if strings.HasPrefix(key, prefix) {
+					delete(annotations, key)
+					break
+				}
+			}
+		}
+	}
+	for _, key := range []string{"annotations", "labels"} {
+		if m, ok := meta[key].(map[string]interface{}); ok && len(m) == 0 {
+			delete(meta, key)
+		}
+	}
+	if kind == "Job" {
+		labels := nestedMap(obj, "spec", "template", "metadata", "labels")
+		for _, l := range jobGeneratedLabels {
+			delete(labels, l)
+		}
+	}
+	if strings.EqualFold(kind, "Secret") && secrets != exportSecretsInclude {
+		// Redacted values are not valid base64, so applying an unedited export fails instead of
+		// silently creating Secrets with placeholder values.
+		for k := range asMap(obj["data"]) {
+			asMap(obj["data"])[k] = "<redacted>"
+		}
+	}
+	return obj
+}
@@ -0,0 +1,156 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+// knownFinalizers explains what common finalizers wait on, matched by exact name or prefix (ending in "/").
+var knownFinalizers = []struct {
+	match, explanation string
+}{
+	{"kubernetes.io/pvc-protection", "PVC is still mounted by a pod; delete the pods using it instead of removing the finalizer"},
+	{"kubernetes.io/pv-protection", "PV is still bound to a PVC; delete the PVC first"},
+	{"foregroundDeletion", "foreground cascading delete: waiting for dependents with blockOwnerDeletion to be deleted"},
+	{"orphan", "garbage collector is removing ownerReferences from dependents before deletion"},
+	{"batch.kubernetes.io/job-tracking", "Job controller has not yet accounted for this pod's completion"},
+	{"external-attacher/", "CSI attacher: the volume is still attached to a node (check VolumeAttachments)"},
+	{"snapshot.storage.kubernetes.io/", "volume snapshot protection: a snapshot or its source is still in use"},
+	{"longhorn.io", "Longhorn manager cleanup (detach volume, remove replicas/engines); check longhorn-manager pods and that the volume is detached"},
+	{"kubevirt.io/", "KubeVirt controller cleanup (VMI shutdown, launcher pod); check virt-controller and virt-handler"},
+	{"harvesterhci.io/", "Harvester controller cleanup; check the harvester pods in harvester-system"},
+	{"wrangler.cattle.io/", "Rancher controller cleanup; check rancher / cattle-cluster-agent is running and connected"},
+	{"controller.cattle.io/", "Rancher controller cleanup; check rancher / cattle-cluster-agent is running and connected"},
+	{"fleet.cattle.io/", "Fleet controller cleanup; check fleet-controller and fleet-agent"},
+	{"helm.cattle.io/", "helm-controller uninstall job has not finished"},
+}
+
+type finalizerInfo struct {
+	Name        string `json:"name"`
+	Explanation string `json:"explanation"`
+}
+
+func explainFinalizer(name string) string {
+	for _, f := range knownFinalizers {
+		if name == f.match || (strings.HasSuffix(f.match, "/") && strings.HasPrefix(name, f.match)) || strings.HasPrefix(name, f.match+"/") {
+			return f.explanation
+		}
+	}
+	return "set by a controller or operator that must finish its cleanup; check that it is running and what it logs about this object"
+}
+
+func (t *Toolset) removeFinalizersTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_remove_finalizers",
+		mcp.WithDescription("Inspect and remove finalizers blocking an object stuck in Terminating (common with Longhorn volumes and Harvester VMs). Without finalizers it only explains which finalizers block deletion and what each waits on. Removing finalizers skips the owning controller's cleanup and can leak external resources; only objects already being deleted are accepted."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, longhorn.io/v1beta2)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. PersistentVolumeClaim, Volume, VirtualMachine)")),
+		mcp.WithString("namespace", mcp.Description("Namespace (for namespaced resources)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Resource name")),
+		mcp.WithString("finalizers", mcp.Description("Comma-separated finalizers to remove, or * for all; omit to only explain")),
+	)
+}
+
+func (t *Toolset) removeFinalizersHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckDestructive(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	apiVersion, err := req.RequireString("api_version")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if kind == "Namespace" {
+		return mcp.NewToolResultError("Namespace deletion is blocked by spec.finalizers and the resources left inside it; list what remains in the namespace instead of removing finalizers"), nil
+	}
+
+	resourceType := rancher.SteveType(apiVersion, kind)
+	obj, err := t.client.Get(ctx, cluster, resourceType, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s %q not found: %v", kind, name, err)), nil
+	}
+	current := obj.ObjectMeta.Finalizers
+	infos := make([]finalizerInfo, 0, len(current))
+	for _, f := range current {
+		infos = append(infos, finalizerInfo{Name: f, Explanation: explainFinalizer(f)})
+	}
+	report := map[string]interface{}{
+		"object":             relatedID(kind, namespace, name),
+		"deletion_timestamp": obj.ObjectMeta.DeletionTimestamp,
+		"terminating":        obj.ObjectMeta.DeletionTimestamp != "",
+		"finalizers":         infos,
+	}
+
+	requested := req.GetString("finalizers", "")
+	if requested == "" {
+		return t.formatResult(report, "json")
+	}
+	if len(current) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("%s %q has no finalizers", kind, name)), nil
+	}
+	if obj.ObjectMeta.DeletionTimestamp == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("%s %q is not being deleted (no deletionTimestamp); delete it first and only remove finalizers if it stays in Terminating", kind, name)), nil
+	}
+	var remove []string
+	for _, f := range strings.Split(requested, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		if f != "*" && !slices.Contains(current, f) {
+			return mcp.NewToolResultError(fmt.Sprintf("finalizer %q is not set on %s %q (current: %s)", f, kind, name, strings.Join(current, ", "))), nil
+		}
+		remove = append(remove, f)
+	}
+	remaining := []string{}
+	for _, f := range current {
+		if !slices.Contains(remove, f) && !slices.Contains(remove, "*") {
+			remaining = append(remaining, f)
+		}
+	}
+
+	// The test op makes the patch fail if a controller changed the finalizers since they were read.
+	patch, _ := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/finalizers", "value": current},
+		{"op": "replace", "path": "/metadata/finalizers", "value": remaining},
+	})
+	path, err := rancher.ResourcePath(resourceType, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeJSON)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_remove_finalizers: %v", err)), nil
+	}
+	if status == http.StatusNotFound {
+		return mcp.NewToolResultText(fmt.Sprintf("%s %q is already gone", kind, name)), nil
+	}
+	if status < 200 || status >= 300 {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_remove_finalizers: patch %d: %s", status, apiErrorMessage(body))), nil
+	}
+	report["removed"] = slices.DeleteFunc(slices.Clone(current), func(f string) bool { return slices.Contains(remaining, f) })
+	report["remaining"] = remaining
+	return t.formatResult(report, "json")
+}
@@ -0,0 +1,136 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	defaultProbeTimeoutSeconds = 10
+	maxProbeTimeoutSeconds     = 60
+	defaultProbeMaxBytes       = 16 * 1024
+	maxProbeMaxBytes           = 1024 * 1024
+)
+
+func (t *Toolset) httpProbeTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_http_probe",
+		mcp.WithDescription("Make a bounded HTTP GET to a Service or Pod port from inside the cluster via the Kubernetes proxy subresource (e.g. /healthz, /metrics). Returns status, headers and a truncated body. Namespaces and ports are subject to security policy."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("kind", mcp.Description("Target kind: service or pod (default: service)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Service or Pod name")),
+		mcp.WithString("port", mcp.Description("Port number or name (optional for single-port services)")),
+		mcp.WithString("path", mcp.Description("Request path, may include a query string (default: /)")),
+		mcp.WithString("scheme", mcp.Description("http or https (default: http)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Timeout in seconds (default: 10, max: 60)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max body bytes returned (default: 16384, max: 1048576)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) httpProbeHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	port := req.GetString("port", "")
+	if err := t.policy.CheckProbePort(port); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var resource string
+	switch kind := strings.ToLower(req.GetString("kind", "service")); kind {
+	case "service", "services", "svc":
+		resource = "services"
+	case "pod", "pods":
+		resource = "pods"
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported kind %q; use service or pod", kind)), nil
+	}
+	scheme := strings.ToLower(req.GetString("scheme", "http"))
+	if scheme != "http" && scheme != "https" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported scheme %q; use http or https", scheme)), nil
+	}
+	path, rawQuery, _ := strings.Cut(req.GetString("path", "/"), "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid query string: %v", err)), nil
+	}
+	timeoutSeconds := req.GetInt("timeout_seconds", defaultProbeTimeoutSeconds)
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultProbeTimeoutSeconds
+	}
+	if timeoutSeconds > maxProbeTimeoutSeconds {
+		timeoutSeconds = maxProbeTimeoutSeconds
+	}
+	maxBytes := req.GetInt("max_bytes", defaultProbeMaxBytes)
+	if maxBytes <= 0 {
+		maxBytes = defaultProbeMaxBytes
+	}
+	if maxBytes > maxProbeMaxBytes {
+		maxBytes = maxProbeMaxBytes
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+	target := name
+	if port != "" {
+		target += ":" + port
+	}
+	start := time.Now()
+	resp, err := t.client.ProxyGet(ctx, cluster, namespace, resource, scheme, name, port, path, query, int64(maxBytes))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("http probe: %v", err)), nil
+	}
+	out := map[string]interface{}{
+		"target":      fmt.Sprintf("%s %s/%s/%s/%s", scheme, namespace, resource, target, strings.TrimPrefix(path, "/")),
+		"status_code": resp.StatusCode,
+		"status":      http.StatusText(resp.StatusCode),
+		"ok":          resp.StatusCode >= 200 && resp.StatusCode < 400,
+		"elapsed_ms":  time.Since(start).Milliseconds(),
+		"headers":     t.probeHeaders(resp.Header),
+		"body_bytes":  len(resp.Body),
+		"truncated":   resp.Truncated,
+	}
+	if utf8.Valid(resp.Body) {
+		out["body"] = string(resp.Body)
+	} else {
+		out["body"] = fmt.Sprintf("<%d bytes of binary data omitted>", len(resp.Body))
+	}
+	text, err := t.formatter.Format(out, req.GetString("format", "json"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// probeHeaders flattens response headers; Set-Cookie values are redacted unless sensitive data may be shown.
+func (t *Toolset) probeHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if strings.EqualFold(k, "Set-Cookie") && !t.policy.CanShowSecret() {
+			out[k] = "<redacted>"
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
@@ -3,11 +3,16 @@ package kubernetes
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mrostamii/rancher-mcp-server/internal/security"
 	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
@@ -177,3 +182,1387 @@ func TestGetHandler_Success(t *testing.T) {
 		t.Errorf("output should contain resource name: %s", tc.Text)
 	}
 }
+
+func TestRolloutStatus_Deployment(t *testing.T) {
+	res := &rancher.SteveResource{
+		ObjectMeta: rancher.ObjectMeta{Name: "web", Namespace: "default", Generation: 3},
+		Spec:       map[string]interface{}{"replicas": float64(3)},
+		Status: map[string]interface{}{
+			"observedGeneration": float64(3),
+			"replicas":           float64(3),
+			"updatedReplicas":    float64(1),
+			"availableReplicas":  float64(2),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded", "message": "ReplicaSet web-abc has timed out progressing."},
+			},
+		},
+	}
+	got := rolloutStatus("Deployment", res)
+	if got["complete"] != false {
+		t.Errorf("complete = %v, want false", got["complete"])
+	}
+	if got["stuck"] != true {
+		t.Errorf("stuck = %v, want true", got["stuck"])
+	}
+	if msg, _ := got["message"].(string); !strings.Contains(msg, "1 out of 3 new replicas") {
+		t.Errorf("message = %q", msg)
+	}
+
+	res.Status = map[string]interface{}{
+		"observedGeneration": float64(3),
+		"replicas":           float64(3),
+		"updatedReplicas":    float64(3),
+		"availableReplicas":  float64(3),
+	}
+	if got := rolloutStatus("Deployment", res); got["complete"] != true {
+		t.Errorf("complete = %v, want true (%v)", got["complete"], got["message"])
+	}
+}
+
+func TestScaleHandler_Success(t *testing.T) {
+	var patched map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/apis/apps/v1/namespaces/default/deployments/web/scale" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{"spec": map[string]interface{}{"replicas": 2}})
+		case http.MethodPatch:
+			if ct := r.Header.Get("Content-Type"); ct != rancher.PatchTypeMerge {
+				t.Errorf("Content-Type = %s", ct)
+			}
+			json.NewDecoder(r.Body).Decode(&patched)
+			json.NewEncoder(w).Encode(patched)
+		}
+	}))
+	defer srv.Close()
+
+	client := rancher.NewSteveClient(srv.URL, "token", true)
+	toolset := NewToolset(client, &security.Policy{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "kubernetes_scale",
+			Arguments: map[string]interface{}{
+				"cluster":   "c-xxx",
+				"kind":      "Deployment",
+				"namespace": "default",
+				"name":      "web",
+				"replicas":  float64(5),
+			},
+		},
+	}
+	result, err := toolset.scaleHandler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("scaleHandler: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	if tc := result.Content[0].(mcp.TextContent); !strings.Contains(tc.Text, "from 2 to 5") {
+		t.Errorf("output = %s", tc.Text)
+	}
+	if spec, _ := patched["spec"].(map[string]interface{}); spec["replicas"] != float64(5) {
+		t.Errorf("patched = %v", patched)
+	}
+}
+
+func TestScaleHandler_ReadOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("should not call API in read-only mode")
+	}))
+	defer srv.Close()
+
+	client := rancher.NewSteveClient(srv.URL, "token", true)
+	toolset := NewToolset(client, &security.Policy{ReadOnly: true})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "kubernetes_scale",
+			Arguments: map[string]interface{}{
+				"cluster": "c-xxx", "kind": "Deployment", "namespace": "default", "name": "web", "replicas": float64(1),
+			},
+		},
+	}
+	result, err := toolset.scaleHandler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("scaleHandler: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected error in read-only mode")
+	}
+}
+
+func TestRolloutUndoHandler_Deployment(t *testing.T) {
+	template := func(image, hash string) map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web", "pod-template-hash": hash}},
+			"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "app", "image": image}}},
+		}
+	}
+	replicaSet := func(name, revision, image, hash string) map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": "default",
+				"annotations":     map[string]interface{}{"deployment.kubernetes.io/revision": revision},
+				"ownerReferences": []interface{}{map[string]interface{}{"kind": "Deployment", "name": "web", "uid": "d-1", "controller": true}}},
+			"spec": map[string]interface{}{"template": template(image, hash)},
+		}
+	}
+	var patches []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/k8s/clusters/c-xxx/apis/apps/v1/namespaces/default/deployments/web":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"apiVersion": "apps/v1", "kind": "Deployment",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default", "uid": "d-1", "resourceVersion": "7",
+					"annotations": map[string]interface{}{"deployment.kubernetes.io/revision": "3"}, "finalizers": []interface{}{"example.com/keep"}},
+				"spec": map[string]interface{}{"replicas": 2, "template": template("web:3", "")},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/k8s/clusters/c-xxx/apis/apps/v1/namespaces/default/replicasets":
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{
+				replicaSet("web-1", "1", "web:1", "h1"), replicaSet("web-2", "2", "web:2", "h2"), replicaSet("web-3", "3", "web:3", "h3"),
+			}})
+		case r.Method == http.MethodPatch && r.URL.Path == "/k8s/clusters/c-xxx/apis/apps/v1/namespaces/default/deployments/web":
+			if ct := r.Header.Get("Content-Type"); ct != rancher.PatchTypeJSON {
+				t.Errorf("Content-Type = %s", ct)
+			}
+			body, _ := io.ReadAll(r.Body)
+			patches = append(patches, string(body))
+			w.Write([]byte(`{}`))
+		default:
+			if r.Method != http.MethodGet {
+				t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "kind": "deployment", "namespace": "default", "name": "web"}
+	result, err := toolset.rolloutUndoHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("rolloutUndoHandler: err=%v result=%v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "from revision 3 to revision 2 (ReplicaSet/web-2)") {
+		t.Errorf("output = %s", text)
+	}
+	want := `[{"op":"test","path":"/metadata/resourceVersion","value":"7"},{"op":"replace","path":"/spec/template","value":{"metadata":{"labels":{"app":"web"}},"spec":{"containers":[{"image":"web:2","name":"app"}]}}}]`
+	if len(patches) != 1 || patches[0] != want {
+		t.Errorf("patches = %v, want %s", patches, want)
+	}
+
+	args["to_revision"] = float64(3)
+	result, _ = toolset.rolloutUndoHandler(context.Background(), callToolRequest(args))
+	if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, "already at revision 3") || len(patches) != 1 {
+		t.Errorf("undo to the current revision: %s", text)
+	}
+	args["to_revision"] = float64(9)
+	if result, _ = toolset.rolloutUndoHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected an unknown revision to be rejected")
+	}
+}
+
+func TestRolloutPauseResumeHandler(t *testing.T) {
+	var patches []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/k8s/clusters/c-xxx/apis/apps/v1/namespaces/default/deployments/web" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != rancher.PatchTypeMerge {
+			t.Errorf("Content-Type = %s", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		patches = append(patches, string(body))
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "web"}
+	if result, _ := toolset.rolloutPauseHandler(context.Background(), callToolRequest(args)); result.IsError {
+		t.Errorf("pause: %v", result.Content)
+	}
+	if result, _ := toolset.rolloutResumeHandler(context.Background(), callToolRequest(args)); result.IsError {
+		t.Errorf("resume: %v", result.Content)
+	}
+	if strings.Join(patches, " ") != `{"spec":{"paused":true}} {"spec":{"paused":false}}` {
+		t.Errorf("patches = %v", patches)
+	}
+	args["name"] = "missing"
+	if result, _ := toolset.rolloutPauseHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected a missing Deployment to fail")
+	}
+	toolset.policy.ReadOnly = true
+	args["name"] = "web"
+	if result, _ := toolset.rolloutPauseHandler(context.Background(), callToolRequest(args)); !result.IsError || len(patches) != 2 {
+		t.Error("expected read-only to block pause")
+	}
+}
+
+func TestExecHandler_RedactsSecretEnv(t *testing.T) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"v5.channel.k8s.io"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pods/web/exec"):
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("upgrade: %v", err)
+				return
+			}
+			defer conn.Close()
+			if slices.Contains(r.URL.Query()["command"], "printenv") {
+				conn.WriteMessage(websocket.BinaryMessage, append([]byte{1}, "/root\nhunter2\n"...))
+			} else {
+				conn.WriteMessage(websocket.BinaryMessage, append([]byte{1}, "HOME=/root\nDB_PASSWORD=hunter2\nTLS_PRIVATE_KEY=-----BEGIN KEY-----\nc2VjcmV0\n-----END KEY-----\nAPP_CONN=postgres://u:p@db\nFROM_SECRET_KEY=x\n"...))
+			}
+			conn.WriteMessage(websocket.BinaryMessage, append([]byte{3}, `{"status":"Success"}`...))
+		case strings.HasSuffix(r.URL.Path, "/pods/web"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"apiVersion": "v1", "kind": "Pod",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{
+					"name":    "app",
+					"env":     []interface{}{map[string]interface{}{"name": "APP_CONN", "valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "db", "key": "url"}}}},
+					"envFrom": []interface{}{map[string]interface{}{"prefix": "FROM_", "secretRef": map[string]interface{}{"name": "app-secret"}}},
+				}}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/secrets/app-secret"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"SECRET_KEY": "eA=="}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := rancher.NewSteveClient(srv.URL, "token", true)
+	toolset := NewToolset(client, &security.Policy{AllowExec: true, ExecAllowedCommands: []string{"env", "printenv"}})
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "pod": "web", "command": "env"}
+	result, err := toolset.execHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("execHandler: err=%v result=%v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, leaked := range []string{"hunter2", "c2VjcmV0", "END KEY", "postgres://", "FROM_SECRET_KEY=x"} {
+		if strings.Contains(text, leaked) {
+			t.Errorf("output leaks %q: %s", leaked, text)
+		}
+	}
+	if !strings.Contains(text, "HOME=/root") || !strings.Contains(text, `"exit_code": 0`) {
+		t.Errorf("unexpected output: %s", text)
+	}
+
+	for _, cmd := range []string{"printenv HOME DB_PASSWORD", "env printenv DB_PASSWORD", "env -i A=1 printenv DB_PASSWORD"} {
+		args["command"] = cmd
+		result, _ = toolset.execHandler(context.Background(), callToolRequest(args))
+		if text := result.Content[0].(mcp.TextContent).Text; result.IsError || strings.Contains(text, "hunter2") {
+			t.Errorf("%q leaks a secret var: %s", cmd, text)
+		}
+	}
+
+	for _, cmd := range []string{"sh -c env", "env sh -c id"} {
+		args["command"] = cmd
+		result, _ = toolset.execHandler(context.Background(), callToolRequest(args))
+		if !result.IsError {
+			t.Errorf("expected %q to be rejected by the exec allowlist", cmd)
+		}
+	}
+}
+
+func TestHTTPProbeHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/api/v1/namespaces/default/services/web:8080/proxy/healthz" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("verbose") != "1" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(strings.Repeat("ok", 100)))
+	}))
+	defer srv.Close()
+
+	client := rancher.NewSteveClient(srv.URL, "token", true)
+	toolset := NewToolset(client, &security.Policy{ProbeAllowedPorts: []string{"8080"}})
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "name": "web", "port": "8080", "path": "/healthz?verbose=1", "max_bytes": 10}
+	result, err := toolset.httpProbeHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("httpProbeHandler: err=%v result=%v", err, result)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out["status_code"] != float64(200) || out["body"] != "okokokokok" || out["truncated"] != true {
+		t.Errorf("unexpected result: %v", out)
+	}
+	if headers, _ := out["headers"].(map[string]interface{}); headers["Set-Cookie"] != "<redacted>" {
+		t.Errorf("Set-Cookie should be redacted: %v", headers)
+	}
+
+	args["port"] = "22"
+	result, _ = toolset.httpProbeHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected port 22 to be rejected by policy")
+	}
+
+	args["port"] = "8080"
+	args["path"] = "/%2e%2e/%2e%2e/secrets"
+	result, _ = toolset.httpProbeHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected a path climbing out of the proxy to be rejected")
+	}
+}
+
+func TestNodeReportHandler_Overcommitted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/v1/nodes"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{
+					"metadata": map[string]interface{}{"name": "node-1", "labels": map[string]interface{}{"node-role.kubernetes.io/worker": "true"}},
+					"spec":     map[string]interface{}{"taints": []interface{}{map[string]interface{}{"key": "dedicated", "value": "db", "effect": "NoSchedule"}}},
+					"status": map[string]interface{}{
+						"allocatable": map[string]interface{}{"cpu": "2", "memory": "4Gi", "pods": "110"},
+						"nodeInfo":    map[string]interface{}{"kubeletVersion": "v1.30.1"},
+						"conditions": []interface{}{
+							map[string]interface{}{"type": "Ready", "status": "True"},
+							map[string]interface{}{"type": "MemoryPressure", "status": "True"},
+						},
+					},
+				},
+			}})
+		case strings.HasSuffix(r.URL.Path, "/api/v1/pods"):
+			container := func(cpu, mem string) interface{} {
+				return map[string]interface{}{"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"cpu": cpu, "memory": mem},
+					"limits":   map[string]interface{}{"cpu": cpu, "memory": mem},
+				}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "a", "namespace": "default"}, "spec": map[string]interface{}{"nodeName": "node-1", "containers": []interface{}{container("1500m", "1Gi")}}, "status": map[string]interface{}{"phase": "Running"}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "b", "namespace": "default"}, "spec": map[string]interface{}{"nodeName": "node-1", "containers": []interface{}{container("1", "512Mi")}, "initContainers": []interface{}{container("1", "2Gi")}}, "status": map[string]interface{}{"phase": "Running"}},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	result, err := toolset.nodeReportHandler(context.Background(), callToolRequest(map[string]interface{}{"cluster": "c-xxx"}))
+	if err != nil || result.IsError {
+		t.Fatalf("nodeReportHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Nodes []nodeReport `json:"nodes"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(out.Nodes) != 1 {
+		t.Fatalf("nodes = %d, want 1", len(out.Nodes))
+	}
+	n := out.Nodes[0]
+	if n.CPU.Requests != "2500m" || n.CPU.RequestsPct != 125 || n.Memory.Requests != "3.0Gi" || n.Pods != "2/110" {
+		t.Errorf("unexpected usage: cpu=%+v memory=%+v pods=%s", n.CPU, n.Memory, n.Pods)
+	}
+	if !n.Overcommitted || n.KubeletVersion != "v1.30.1" || len(n.Taints) != 1 || n.Taints[0] != "dedicated=db:NoSchedule" {
+		t.Errorf("unexpected report: %+v", n)
+	}
+	if !slices.Contains(n.Warnings, "MemoryPressure") {
+		t.Errorf("warnings = %v, want MemoryPressure", n.Warnings)
+	}
+
+	result, _ = toolset.nodeReportHandler(context.Background(), callToolRequest(map[string]interface{}{"cluster": "c-xxx", "format": "table"}))
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "NAME") || !strings.Contains(text, "node-1") {
+		t.Errorf("unexpected table: %s", text)
+	}
+}
+
+func TestBuildNodeReport_Overcommit(t *testing.T) {
+	node := rancher.SteveResource{
+		ObjectMeta: rancher.ObjectMeta{Name: "node-1"},
+		Status: map[string]interface{}{
+			"allocatable": map[string]interface{}{"cpu": "2", "memory": "4Gi", "pods": "110"},
+			"conditions":  []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}
+	usage := func(requestsCPU, limitsCPU int64) *nodeUsage {
+		return &nodeUsage{
+			requests: resourceTotals{CPUMilli: requestsCPU, MemoryBytes: 1 << 30},
+			limits:   resourceTotals{CPUMilli: limitsCPU, MemoryBytes: 1 << 30},
+			pods:     3,
+		}
+	}
+
+	r := buildNodeReport(node, usage(1900, 1900))
+	if r.Overcommitted || !slices.Contains(r.Warnings, "cpu requests 95% of allocatable") {
+		t.Errorf("requests near allocatable: overcommitted=%v warnings=%v", r.Overcommitted, r.Warnings)
+	}
+	r = buildNodeReport(node, usage(500, 4000))
+	if !r.Overcommitted || !slices.Contains(r.Warnings, "cpu limits 200% of allocatable") {
+		t.Errorf("limits above allocatable: overcommitted=%v warnings=%v", r.Overcommitted, r.Warnings)
+	}
+	if r = buildNodeReport(node, usage(500, 1000)); r.Overcommitted || len(r.Warnings) != 0 {
+		t.Errorf("headroom: overcommitted=%v warnings=%v", r.Overcommitted, r.Warnings)
+	}
+}
+
+func TestTopPodsHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/apis/metrics.k8s.io/v1beta1/namespaces/default/pods"):
+			pod := func(name, cpu, mem string) interface{} {
+				return map[string]interface{}{
+					"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+					"containers": []interface{}{map[string]interface{}{"name": "app", "usage": map[string]interface{}{"cpu": cpu, "memory": mem}}},
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{
+				pod("idle", "5000000n", "64Mi"),
+				pod("hot", "480m", "120Mi"),
+			}})
+		case strings.HasSuffix(r.URL.Path, "/api/v1/namespaces/default/pods"):
+			pod := func(name string) interface{} {
+				return map[string]interface{}{
+					"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+					"spec": map[string]interface{}{"nodeName": "node-1", "containers": []interface{}{map[string]interface{}{
+						"name":      "app",
+						"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "100m"}, "limits": map[string]interface{}{"cpu": "500m", "memory": "128Mi"}},
+					}}},
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{pod("idle"), pod("hot")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	result, err := toolset.topPodsHandler(context.Background(), callToolRequest(map[string]interface{}{"cluster": "c-xxx", "namespace": "default"}))
+	if err != nil || result.IsError {
+		t.Fatalf("topPodsHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Total int      `json:"total"`
+		Pods  []topPod `json:"pods"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Total != 2 || out.Pods[0].Name != "hot" || out.Pods[0].CPU != "480m" || out.Pods[0].CPULimitPct != 96 {
+		t.Fatalf("unexpected pods: %+v", out.Pods)
+	}
+	if len(out.Pods[0].Flags) != 2 || len(out.Pods[1].Flags) != 0 {
+		t.Errorf("flags = %v / %v", out.Pods[0].Flags, out.Pods[1].Flags)
+	}
+}
+
+func TestTopNodesHandler_NoMetricsServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	result, _ := toolset.topNodesHandler(context.Background(), callToolRequest(map[string]interface{}{"cluster": "c-xxx"}))
+	if !result.IsError {
+		t.Fatal("expected error without metrics-server")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "metrics-server") {
+		t.Errorf("error should mention metrics-server: %s", text)
+	}
+}
+
+func TestTriageHandler(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		items := []interface{}{}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/v1/namespaces/shop/pods"):
+			containerStatus := func(waiting, lastTerminated string) interface{} {
+				cs := map[string]interface{}{"name": "app", "restartCount": 7, "state": map[string]interface{}{"waiting": map[string]interface{}{"reason": waiting}}}
+				if lastTerminated != "" {
+					cs["lastState"] = map[string]interface{}{"terminated": map[string]interface{}{"reason": lastTerminated}}
+				}
+				return cs
+			}
+			recovered := func(finishedAt string) interface{} {
+				return map[string]interface{}{"name": "app", "restartCount": 1, "ready": true, "state": map[string]interface{}{"running": map[string]interface{}{}},
+					"lastState": map[string]interface{}{"terminated": map[string]interface{}{"reason": "OOMKilled", "finishedAt": finishedAt}}}
+			}
+			items = []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "db-1", "namespace": "shop"}, "status": map[string]interface{}{"phase": "Running", "containerStatuses": []interface{}{recovered("2020-01-01T00:00:00Z")}}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "queue-1", "namespace": "shop"}, "status": map[string]interface{}{"phase": "Running", "containerStatuses": []interface{}{recovered(now)}}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "api-1", "namespace": "shop"}, "status": map[string]interface{}{"phase": "Running", "containerStatuses": []interface{}{containerStatus("CrashLoopBackOff", "Error")}}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "cache-1", "namespace": "shop"}, "status": map[string]interface{}{"phase": "Running", "containerStatuses": []interface{}{containerStatus("CrashLoopBackOff", "OOMKilled")}}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "web-1", "namespace": "shop"}, "status": map[string]interface{}{"phase": "Pending", "containerStatuses": []interface{}{containerStatus("ImagePullBackOff", "")}}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "big-1", "namespace": "shop"}, "status": map[string]interface{}{"phase": "Pending", "conditions": []interface{}{
+					map[string]interface{}{"type": "PodScheduled", "status": "False", "reason": "Unschedulable", "message": "0/3 nodes are available: 3 Insufficient cpu."},
+				}}},
+			}
+		case strings.HasSuffix(r.URL.Path, "/apis/apps/v1/namespaces/shop/deployments"):
+			items = []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "api", "namespace": "shop"}, "spec": map[string]interface{}{"replicas": 2}, "status": map[string]interface{}{"availableReplicas": 0}},
+			}
+		case strings.HasSuffix(r.URL.Path, "/api/v1/namespaces/shop/persistentvolumeclaims"):
+			items = []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "data", "namespace": "shop"}, "spec": map[string]interface{}{"storageClassName": "fast"}, "status": map[string]interface{}{"phase": "Pending"}},
+			}
+		case strings.HasSuffix(r.URL.Path, "/api/v1/namespaces/shop/events"):
+			if r.URL.Query().Get("fieldSelector") != "type=Warning" {
+				t.Errorf("events fieldSelector = %q", r.URL.Query().Get("fieldSelector"))
+			}
+			items = []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "e1", "namespace": "shop"}, "type": "Warning", "reason": "BackOff", "lastTimestamp": now,
+					"involvedObject": map[string]interface{}{"kind": "Pod", "namespace": "shop", "name": "api-1"}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "e2", "namespace": "shop"}, "type": "Warning", "reason": "FailedMount", "lastTimestamp": now,
+					"involvedObject": map[string]interface{}{"kind": "Pod", "namespace": "shop", "name": "worker-1"}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "e3", "namespace": "shop"}, "type": "Warning", "reason": "Old", "lastTimestamp": "2020-01-01T00:00:00Z",
+					"involvedObject": map[string]interface{}{"kind": "Pod", "namespace": "shop", "name": "old-1"}},
+			}
+		case strings.HasSuffix(r.URL.Path, "/apis/batch/v1/namespaces/shop/jobs"):
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	result, err := toolset.triageHandler(context.Background(), callToolRequest(map[string]interface{}{"cluster": "c-xxx", "namespace": "shop"}))
+	if err != nil || result.IsError {
+		t.Fatalf("triageHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Issues []triageIssue `json:"issues"`
+		Errors []string      `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(out.Errors) != 0 {
+		t.Errorf("scan errors: %v", out.Errors)
+	}
+	got := map[string][]string{}
+	var order []string
+	for _, issue := range out.Issues {
+		order = append(order, issue.Cause)
+		for _, o := range issue.Objects {
+			got[issue.Cause] = append(got[issue.Cause], o.Name)
+		}
+	}
+	want := map[string][]string{
+		"CrashLoopBackOff":      {"api-1"},
+		"OOMKilled":             {"queue-1", "cache-1"},
+		"PreviouslyOOMKilled":   {"db-1"},
+		"DeploymentUnavailable": {"api"},
+		"ImagePullBackOff":      {"web-1"},
+		"Unschedulable":         {"big-1"},
+		"PVCUnbound":            {"data"},
+		"WarningEvents":         {"worker-1"},
+	}
+	for cause, names := range want {
+		if strings.Join(got[cause], ",") != strings.Join(names, ",") {
+			t.Errorf("%s = %v, want %v", cause, got[cause], names)
+		}
+	}
+	if order[0] != "CrashLoopBackOff" && order[0] != "OOMKilled" && order[0] != "DeploymentUnavailable" || order[len(order)-1] != "WarningEvents" {
+		t.Errorf("issues not prioritized: %v", order)
+	}
+	for _, issue := range out.Issues {
+		if issue.Cause == "PreviouslyOOMKilled" && issue.Severity != severityLow {
+			t.Errorf("PreviouslyOOMKilled severity = %s, want %s", issue.Severity, severityLow)
+		}
+	}
+}
+
+func TestEventsHandler_DedupAndSince(t *testing.T) {
+	recent := time.Now().Add(-5 * time.Minute).UTC().Format(time.RFC3339)
+	newer := time.Now().Add(-1 * time.Minute).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-3 * time.Hour).UTC().Format(time.RFC3339)
+	var limit string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/c-xxx/apis/events.k8s.io/v1/namespaces/default/events" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("fieldSelector"); got != "regarding.kind=Pod,type=Warning" {
+			t.Errorf("fieldSelector = %q", got)
+		}
+		event := func(name, reason, pod, note, eventTime string, series int) interface{} {
+			e := map[string]interface{}{
+				"metadata":  map[string]interface{}{"name": name, "namespace": "default"},
+				"type":      "Warning",
+				"reason":    reason,
+				"note":      note,
+				"eventTime": eventTime,
+				"regarding": map[string]interface{}{"kind": "Pod", "namespace": "default", "name": pod},
+			}
+			if series > 0 {
+				e["series"] = map[string]interface{}{"count": series, "lastObservedTime": eventTime}
+			}
+			return e
+		}
+		w.Header().Set("Content-Type", "application/json")
+		limit = r.URL.Query().Get("limit")
+		if r.URL.Query().Get("continue") == "page-2" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{event("e5", "BackOff", "web-2", "Back-off", old, 0)}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"metadata": map[string]interface{}{"continue": "page-2"}, "items": []interface{}{
+			event("e1", "BackOff", "web-1", "Back-off restarting failed container", recent, 12),
+			event("e2", "BackOff", "web-1", "Back-off restarting failed container", newer, 3),
+			event("e3", "FailedMount", "db-0", "MountVolume.SetUp failed", recent, 0),
+			event("e4", "BackOff", "old-1", "Back-off restarting failed container", old, 40),
+		}})
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "default", "involved_object_kind": "Pod", "type": "Warning", "since": "1h"}
+	result, err := toolset.eventsHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("eventsHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Total  int            `json:"total"`
+		Events []eventSummary `json:"events"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Total != 2 {
+		t.Fatalf("total = %d, want 2: %+v", out.Total, out.Events)
+	}
+	first := out.Events[0]
+	if first.Object != "Pod/web-1" || first.Count != 15 || first.Occurrences != 2 || first.LastSeen != newer {
+		t.Errorf("merged event = %+v", first)
+	}
+	if out.Events[1].Object != "Pod/db-0" || out.Events[1].Count != 1 {
+		t.Errorf("second event = %+v", out.Events[1])
+	}
+
+	if strings.Contains(result.Content[0].(mcp.TextContent).Text, "page-2") {
+		t.Errorf("aggregated events should read every page: %s", result.Content[0].(mcp.TextContent).Text)
+	}
+
+	args["continue"] = "page-2"
+	if result, _ = toolset.eventsHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected continue to require dedup=false")
+	}
+	args["dedup"], args["limit"] = false, float64(2)
+	delete(args, "continue")
+	delete(args, "since")
+	result, _ = toolset.eventsHandler(context.Background(), callToolRequest(args))
+	var page struct {
+		Events   []eventSummary `json:"events"`
+		Continue string         `json:"continue"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil || page.Continue != "page-2" || limit != "2" {
+		t.Fatalf("raw page: err=%v %s", err, result.Content[0].(mcp.TextContent).Text)
+	}
+	args["continue"] = page.Continue
+	result, _ = toolset.eventsHandler(context.Background(), callToolRequest(args))
+	page.Continue = ""
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil || len(page.Events) != 1 || page.Events[0].Object != "Pod/web-2" || page.Continue != "" {
+		t.Errorf("second page: err=%v %s", err, result.Content[0].(mcp.TextContent).Text)
+	}
+
+	args["since"] = "yesterday"
+	result, _ = toolset.eventsHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected invalid since to be rejected")
+	}
+}
+
+func TestRelatedHandler_PodGraph(t *testing.T) {
+	controller := true
+	meta := func(name, uid string, owner map[string]interface{}) map[string]interface{} {
+		m := map[string]interface{}{"name": name, "namespace": "shop", "uid": uid}
+		if owner != nil {
+			m["ownerReferences"] = []interface{}{owner}
+		}
+		return m
+	}
+	objects := map[string]interface{}{
+		"/api/v1/namespaces/shop/pods/web-abc-1": map[string]interface{}{
+			"apiVersion": "v1", "kind": "Pod",
+			"metadata": map[string]interface{}{"name": "web-abc-1", "namespace": "shop", "uid": "p1", "labels": map[string]interface{}{"app": "web"},
+				"ownerReferences": []interface{}{map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web-abc", "uid": "rs1", "controller": controller}}},
+			"spec": map[string]interface{}{
+				"serviceAccountName": "web",
+				"nodeName":           "node-1",
+				"volumes": []interface{}{
+					map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "web-config"}},
+					map[string]interface{}{"name": "tls", "secret": map[string]interface{}{"secretName": "web-tls"}},
+				},
+				"containers": []interface{}{map[string]interface{}{"name": "app", "envFrom": []interface{}{map[string]interface{}{"secretRef": map[string]interface{}{"name": "web-env"}}}}},
+			},
+		},
+		"/apis/apps/v1/namespaces/shop/replicasets/web-abc": map[string]interface{}{
+			"apiVersion": "apps/v1", "kind": "ReplicaSet",
+			"metadata": meta("web-abc", "rs1", map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web", "uid": "d1", "controller": controller}),
+		},
+		"/apis/apps/v1/namespaces/shop/deployments/web": map[string]interface{}{
+			"apiVersion": "apps/v1", "kind": "Deployment",
+			"metadata": map[string]interface{}{"name": "web", "namespace": "shop", "uid": "d1",
+				"annotations": map[string]interface{}{"meta.helm.sh/release-name": "shop-web", "meta.helm.sh/release-namespace": "shop"}},
+		},
+		"/api/v1/namespaces/shop/serviceaccounts/web":   map[string]interface{}{"metadata": meta("web", "sa1", nil)},
+		"/api/v1/namespaces/shop/configmaps/web-config": map[string]interface{}{"metadata": meta("web-config", "cm1", nil)},
+		"/api/v1/namespaces/shop/secrets/web-env":       map[string]interface{}{"metadata": meta("web-env", "s1", nil)},
+		"/api/v1/namespaces/shop/services": map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"metadata": meta("web", "svc1", nil), "spec": map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}},
+			map[string]interface{}{"metadata": meta("other", "svc2", nil), "spec": map[string]interface{}{"selector": map[string]interface{}{"app": "other"}}},
+			map[string]interface{}{"metadata": meta("external", "svc3", nil), "spec": map[string]interface{}{}},
+		}},
+		"/apis/networking.k8s.io/v1/namespaces/shop/networkpolicies": map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"metadata": meta("default-deny", "np1", nil), "spec": map[string]interface{}{"podSelector": map[string]interface{}{}}},
+		}},
+		"/apis/policy/v1/namespaces/shop/poddisruptionbudgets": map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"metadata": meta("web", "pdb1", nil), "spec": map[string]interface{}{"selector": map[string]interface{}{
+				"matchExpressions": []interface{}{map[string]interface{}{"key": "app", "operator": "In", "values": []interface{}{"web", "api"}}},
+			}}},
+		}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		obj, ok := objects[strings.TrimPrefix(r.URL.Path, "/k8s/clusters/c-xxx")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(obj)
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	args := map[string]interface{}{"cluster": "c-xxx", "api_version": "v1", "kind": "Pod", "namespace": "shop", "name": "web-abc-1"}
+	result, err := toolset.relatedHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("relatedHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Nodes []relatedNode `json:"nodes"`
+		Edges []relatedEdge `json:"edges"`
+		Tree  string        `json:"tree"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	edges := map[string]bool{}
+	for _, e := range out.Edges {
+		edges[e.From+" "+e.Relation+" "+e.To] = true
+	}
+	for _, want := range []string{
+		"Deployment/shop/web owns ReplicaSet/shop/web-abc",
+		"ReplicaSet/shop/web-abc owns Pod/shop/web-abc-1",
+		"HelmRelease/shop/shop-web manages Deployment/shop/web",
+		"Pod/shop/web-abc-1 references ServiceAccount/shop/web",
+		"Pod/shop/web-abc-1 references ConfigMap/shop/web-config",
+		"Pod/shop/web-abc-1 references Secret/shop/web-tls",
+		"Pod/shop/web-abc-1 references Secret/shop/web-env",
+		"Pod/shop/web-abc-1 scheduled-on Node/node-1",
+		"Service/shop/web selects Pod/shop/web-abc-1",
+		"NetworkPolicy/shop/default-deny selects Pod/shop/web-abc-1",
+		"PodDisruptionBudget/shop/web selects Pod/shop/web-abc-1",
+	} {
+		if !edges[want] {
+			t.Errorf("missing edge %q in %v", want, out.Edges)
+		}
+	}
+	if edges["Service/shop/other selects Pod/shop/web-abc-1"] || edges["Service/shop/external selects Pod/shop/web-abc-1"] {
+		t.Errorf("unexpected service edges: %v", out.Edges)
+	}
+	for _, n := range out.Nodes {
+		if missing := n.ID == "Secret/shop/web-tls"; n.Missing != missing {
+			t.Errorf("node %s missing = %v", n.ID, n.Missing)
+		}
+	}
+	if !strings.HasPrefix(out.Tree, "Deployment/shop/web\n") || !strings.Contains(out.Tree, "Secret/shop/web-tls [missing]") {
+		t.Errorf("tree:\n%s", out.Tree)
+	}
+}
+
+func TestSearchHandler_FanOut(t *testing.T) {
+	deployments := func(names ...string) interface{} {
+		items := []interface{}{}
+		for _, n := range names {
+			items = append(items, map[string]interface{}{"metadata": map[string]interface{}{"name": n, "namespace": "shop"}})
+		}
+		return map[string]interface{}{"items": items}
+	}
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/k8s/clusters/local/v1/management.cattle.io.clusters":
+			if got := r.URL.Query().Get("labelSelector"); got != "env=prod" {
+				t.Errorf("cluster labelSelector = %q", got)
+			}
+			cluster := func(id, name string) interface{} {
+				return map[string]interface{}{"metadata": map[string]interface{}{"name": id}, "spec": map[string]interface{}{"displayName": name}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{
+				cluster("c-1", "eu-prod"), cluster("c-2", "us-prod"), cluster("c-3", "ap-prod"), cluster("c-4", "sa-prod"),
+			}})
+		case "/k8s/clusters/c-1/apis/apps/v1/deployments":
+			if got := r.URL.Query().Get("labelSelector"); got != "tier=frontend" {
+				t.Errorf("labelSelector = %q", got)
+			}
+			json.NewEncoder(w).Encode(deployments("web", "web-canary", "worker"))
+		case "/k8s/clusters/c-2/apis/apps/v1/deployments":
+			json.NewEncoder(w).Encode(deployments("api"))
+		case "/k8s/clusters/c-3/apis/apps/v1/deployments":
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		case "/k8s/clusters/c-4/v1/apps.v1.deployments":
+			w.WriteHeader(http.StatusForbidden)
+		case "/k8s/clusters/c-1/api/v1/namespaces":
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "shop"}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "kube-system"}},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	args := map[string]interface{}{
+		"api_version": "apps/v1", "kind": "Deployment", "cluster_selector": "env=prod",
+		"name_regex": "^web", "label_selector": "tier=frontend", "timeout_seconds": float64(1), "include_empty": true,
+	}
+	result, err := toolset.searchHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("searchHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Searched int                   `json:"clusters_searched"`
+		Matched  int                   `json:"clusters_matched"`
+		Failed   int                   `json:"clusters_failed"`
+		Results  []searchClusterResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Searched != 4 || out.Matched != 1 || out.Failed != 2 || len(out.Results) != 4 {
+		t.Fatalf("summary = %+v", out)
+	}
+	byCluster := map[string]searchClusterResult{}
+	for _, r := range out.Results {
+		byCluster[r.Cluster] = r
+	}
+	if r := byCluster["c-1"]; r.Count != 2 || r.DisplayName != "eu-prod" || r.Items[0].Name != "web" || r.Items[1].Name != "web-canary" {
+		t.Errorf("c-1 = %+v", r)
+	}
+	if r := byCluster["c-2"]; r.Count != 0 || r.Error != "" {
+		t.Errorf("c-2 = %+v", r)
+	}
+	if r := byCluster["c-3"]; !r.TimedOut {
+		t.Errorf("c-3 = %+v, want timeout", r)
+	}
+	if r := byCluster["c-4"]; r.Error == "" || r.TimedOut {
+		t.Errorf("c-4 = %+v, want error", r)
+	}
+
+	args["clusters"] = "eu-prod,missing"
+	result, _ = toolset.searchHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected unknown cluster to be rejected")
+	}
+
+	toolset.policy.DeniedNamespaces = []string{"kube-system"}
+	args = map[string]interface{}{"api_version": "v1", "kind": "Namespace", "clusters": "eu-prod", "cluster_selector": "env=prod"}
+	result, _ = toolset.searchHandler(context.Background(), callToolRequest(args))
+	if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, `"shop"`) || strings.Contains(text, "kube-system") {
+		t.Errorf("denied namespaces should be filtered from Namespace results: %s", text)
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	deployment := func(image string, replicas int, rv string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "apps/v1", "kind": "Deployment",
+			"metadata": map[string]interface{}{"name": "web", "namespace": "shop", "uid": "u-" + rv, "resourceVersion": rv, "creationTimestamp": "2024-01-0" + rv + "T00:00:00Z",
+				"annotations": map[string]interface{}{"deployment.kubernetes.io/revision": rv}, "managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}}},
+			"spec": map[string]interface{}{"replicas": replicas, "template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "sidecar", "image": "envoy:1.0"},
+				map[string]interface{}{"name": "app", "image": image},
+			}}}},
+			"status": map[string]interface{}{"readyReplicas": replicas},
+		}
+	}
+	secret := func(value string) map[string]interface{} {
+		return map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]interface{}{"name": "creds", "namespace": "shop", "labels": map[string]interface{}{"app": "web"}}, "data": map[string]interface{}{"password": value}}
+	}
+	configMap := func(namespace, value string) map[string]interface{} {
+		return map[string]interface{}{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "settings", "namespace": namespace}, "data": map[string]interface{}{"level": value}}
+	}
+	objects := map[string]interface{}{
+		"/k8s/clusters/staging/apis/apps/v1/namespaces/shop/deployments/web": deployment("web:2.0", 1, "1"),
+		"/k8s/clusters/prod/apis/apps/v1/namespaces/shop/deployments/web":    deployment("web:1.9", 3, "2"),
+		"/k8s/clusters/staging/api/v1/namespaces/shop/secrets": map[string]interface{}{"items": []interface{}{
+			secret("c3RhZ2luZw=="),
+			map[string]interface{}{"kind": "Secret", "metadata": map[string]interface{}{"name": "staging-only", "namespace": "shop"}},
+		}},
+		"/k8s/clusters/prod/api/v1/namespaces/shop/secrets": map[string]interface{}{"items": []interface{}{secret("cHJvZA==")}},
+		"/k8s/clusters/staging/api/v1/configmaps":           map[string]interface{}{"items": []interface{}{configMap("shop", "1"), configMap("blog", "1")}},
+		"/k8s/clusters/prod/api/v1/configmaps":              map[string]interface{}{"items": []interface{}{configMap("shop", "1"), configMap("blog", "2")}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		obj, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(obj)
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "staging", "target_cluster": "prod", "api_version": "apps/v1", "kind": "Deployment", "namespace": "shop", "name": "web"}
+	result, err := toolset.diffHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("diffHandler: err=%v result=%v", err, result)
+	}
+	var single struct {
+		Identical bool         `json:"identical"`
+		Changes   []diffChange `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &single); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	var paths []string
+	for _, c := range single.Changes {
+		paths = append(paths, c.Op+" "+c.Path)
+	}
+	want := []string{"changed spec.replicas", "changed spec.template.spec.containers[name=app].image"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("changes = %v, want %v", paths, want)
+	}
+
+	args["format"] = "unified"
+	result, _ = toolset.diffHandler(context.Background(), callToolRequest(args))
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, s := range []string{"--- staging/shop/Deployment/web", "+++ prod/shop/Deployment/web", "-  replicas: 1", "+  replicas: 3", "-      - image: web:2.0", "+      - image: web:1.9"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("unified diff missing %q:\n%s", s, text)
+		}
+	}
+	if strings.Contains(text, "resourceVersion") || strings.Contains(text, "readyReplicas") {
+		t.Errorf("server-managed fields not removed:\n%s", text)
+	}
+
+	args = map[string]interface{}{"cluster": "staging", "target_cluster": "prod", "api_version": "v1", "kind": "Secret", "namespace": "shop", "label_selector": "app=web"}
+	result, _ = toolset.diffHandler(context.Background(), callToolRequest(args))
+	text = result.Content[0].(mcp.TextContent).Text
+	var set struct {
+		Different  []map[string]interface{} `json:"different"`
+		OnlyInLeft []string                 `json:"only_in_left"`
+	}
+	if err := json.Unmarshal([]byte(text), &set); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(set.Different) != 1 || set.Different[0]["name"] != "creds" || !slices.Equal(set.OnlyInLeft, []string{"staging-only"}) {
+		t.Errorf("set diff = %s", text)
+	}
+	if strings.Contains(text, "c3RhZ2luZw==") || strings.Contains(text, "cHJvZA==") || strings.Contains(text, "sha256") {
+		t.Errorf("secret values not redacted: %s", text)
+	}
+	if changes, _ := set.Different[0]["changes"].([]interface{}); len(changes) != 1 || changes[0].(map[string]interface{})["left"] != "<redacted>" || changes[0].(map[string]interface{})["right"] != "<redacted, changed>" {
+		t.Errorf("secret changes = %v", set.Different[0]["changes"])
+	}
+
+	args = map[string]interface{}{"cluster": "prod", "api_version": "v1", "kind": "Secret", "namespace": "shop", "label_selector": "app=web"}
+	result, _ = toolset.diffHandler(context.Background(), callToolRequest(args))
+	var same struct {
+		Identical []string `json:"identical"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &same); err != nil || !slices.Equal(same.Identical, []string{"creds"}) {
+		t.Errorf("equal secret values should compare identical: %s", result.Content[0].(mcp.TextContent).Text)
+	}
+
+	args = map[string]interface{}{"cluster": "staging", "target_cluster": "prod", "api_version": "v1", "kind": "ConfigMap", "label_selector": "app=web"}
+	result, _ = toolset.diffHandler(context.Background(), callToolRequest(args))
+	text = result.Content[0].(mcp.TextContent).Text
+	var all struct {
+		Identical []string                 `json:"identical"`
+		Different []map[string]interface{} `json:"different"`
+	}
+	if err := json.Unmarshal([]byte(text), &all); err != nil || !slices.Equal(all.Identical, []string{"shop/settings"}) || len(all.Different) != 1 || all.Different[0]["name"] != "blog/settings" {
+		t.Errorf("objects across namespaces should pair by namespace/name: %s", text)
+	}
+	args["target_namespace"] = "shop"
+	if result, _ = toolset.diffHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected a selector diff of all namespaces against one namespace to be rejected")
+	}
+}
+
+func TestDiffManifestHandler(t *testing.T) {
+	live := map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "shop", "resourceVersion": "10", "generation": 4},
+		"spec":     map[string]interface{}{"replicas": 2, "template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "app", "image": "web:1.0"}}}}},
+		"status":   map[string]interface{}{"replicas": 2},
+	}
+	namespace := map[string]interface{}{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "shop", "uid": "n1"}}
+	var patched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/k8s/clusters/c-xxx")
+		if r.Method == http.MethodPatch {
+			if r.URL.Query().Get("dryRun") != "All" || r.Header.Get("Content-Type") != rancher.PatchTypeApply {
+				t.Errorf("PATCH %s without dry-run apply: query=%s content-type=%s", path, r.URL.RawQuery, r.Header.Get("Content-Type"))
+			}
+			patched = append(patched, path)
+			var applied map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&applied)
+			asMap(applied["metadata"])["resourceVersion"] = "11"
+			json.NewEncoder(w).Encode(applied)
+			return
+		}
+		switch path {
+		case "/apis/apps/v1/namespaces/shop/deployments/web":
+			json.NewEncoder(w).Encode(live)
+		case "/api/v1/namespaces/shop":
+			json.NewEncoder(w).Encode(namespace)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"kind": "Status", "message": "not found"})
+		}
+	}))
+	defer srv.Close()
+
+	manifest := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: web:1.1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: shop
+data:
+  mode: prod
+---
+kind: ConfigMap
+metadata:
+  name: broken
+`
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "shop", "manifest": manifest}
+	result, err := toolset.diffManifestHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("diffManifestHandler: err=%v result=%v", err, result)
+	}
+	var out struct {
+		Summary map[string]int `json:"summary"`
+		Objects []manifestDiff `json:"objects"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Summary["create"] != 1 || out.Summary["update"] != 1 || out.Summary["unchanged"] != 1 || out.Summary["error"] != 1 {
+		t.Errorf("summary = %v", out.Summary)
+	}
+	byObject := map[string]manifestDiff{}
+	for _, o := range out.Objects {
+		byObject[o.Object] = o
+	}
+	if d := byObject["Namespace/shop"]; d.Action != "unchanged" {
+		t.Errorf("namespace = %+v", d)
+	}
+	if d := byObject["ConfigMap/shop/web-config"]; d.Action != "create" {
+		t.Errorf("configmap = %+v", d)
+	}
+	var paths []string
+	for _, c := range byObject["Deployment/shop/web"].Changes {
+		paths = append(paths, c.Path)
+	}
+	if !slices.Equal(paths, []string{"spec.replicas", "spec.template.spec.containers[name=app].image"}) {
+		t.Errorf("deployment changes = %v", paths)
+	}
+	if !slices.Contains(patched, "/apis/apps/v1/namespaces/shop/deployments/web") || !slices.Contains(patched, "/api/v1/namespaces/shop") {
+		t.Errorf("dry-run applies = %v", patched)
+	}
+
+	args["format"] = "unified"
+	result, _ = toolset.diffManifestHandler(context.Background(), callToolRequest(args))
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, s := range []string{"+++ ConfigMap/shop/web-config", "+  mode: prod", "-  replicas: 2", "+  replicas: 3", "# Namespace/shop: unchanged"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("unified output missing %q:\n%s", s, text)
+		}
+	}
+}
+
+func TestBulkLabelHandler_PreviewAndConfirm(t *testing.T) {
+	var patches []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/k8s/clusters/c-xxx/api/v1/pods":
+			if r.URL.Query().Get("labelSelector") != "app=web" {
+				t.Errorf("labelSelector = %q", r.URL.Query().Get("labelSelector"))
+			}
+			pod := func(ns, name string) interface{} {
+				return map[string]interface{}{"metadata": map[string]interface{}{"name": name, "namespace": ns}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{pod("shop", "web-2"), pod("shop", "web-1"), pod("kube-system", "web-sys")}})
+		case r.Method == http.MethodPatch:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			patches = append(patches, r.URL.Path+" "+string(b))
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	policy := &security.Policy{DeniedNamespaces: []string{"kube-system"}, MaxBulkObjects: 5}
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), policy)
+	args := map[string]interface{}{"cluster": "c-xxx", "api_version": "v1", "kind": "Pod", "label_selector": "app=web", "labels": "team=shop,stale-"}
+	result, err := toolset.labelHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("preview: err=%v result=%v", err, result)
+	}
+	var preview struct {
+		Count   int      `json:"count"`
+		Token   string   `json:"confirm_token"`
+		Objects []string `json:"objects"`
+		Skipped int      `json:"skipped_by_policy"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &preview); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if preview.Count != 2 || preview.Skipped != 1 || !slices.Equal(preview.Objects, []string{"shop/web-1", "shop/web-2"}) || !strings.HasPrefix(preview.Token, "label-2-") {
+		t.Fatalf("preview = %+v", preview)
+	}
+	if len(patches) != 0 {
+		t.Fatalf("preview patched objects: %v", patches)
+	}
+
+	args["confirm"] = "label-2-deadbeef"
+	result, _ = toolset.labelHandler(context.Background(), callToolRequest(args))
+	if !result.IsError || len(patches) != 0 {
+		t.Fatalf("wrong token should be rejected without changes: %v", patches)
+	}
+
+	args["confirm"] = preview.Token
+	result, err = toolset.labelHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("confirm: err=%v result=%v", err, result)
+	}
+	want := []string{
+		`/k8s/clusters/c-xxx/api/v1/namespaces/shop/pods/web-1 {"metadata":{"labels":{"stale":null,"team":"shop"}}}`,
+		`/k8s/clusters/c-xxx/api/v1/namespaces/shop/pods/web-2 {"metadata":{"labels":{"stale":null,"team":"shop"}}}`,
+	}
+	if !slices.Equal(patches, want) {
+		t.Errorf("patches = %v", patches)
+	}
+
+	policy.MaxBulkObjects = 1
+	delete(args, "confirm")
+	result, _ = toolset.labelHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected max-bulk-objects to be enforced")
+	}
+}
+
+func TestRemoveFinalizersHandler(t *testing.T) {
+	deletionTimestamp := "2024-05-01T10:00:00Z"
+	var patch []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/k8s/clusters/c-xxx/api/v1/namespaces/shop/persistentvolumeclaims/data":
+			json.NewEncoder(w).Encode(map[string]interface{}{"metadata": map[string]interface{}{
+				"name": "data", "namespace": "shop", "deletionTimestamp": deletionTimestamp,
+				"finalizers": []interface{}{"kubernetes.io/pvc-protection", "external-attacher/driver-longhorn-io"},
+			}})
+		case r.Method == http.MethodPatch && r.URL.Path == "/k8s/clusters/c-xxx/api/v1/namespaces/shop/persistentvolumeclaims/data":
+			if ct := r.Header.Get("Content-Type"); ct != rancher.PatchTypeJSON {
+				t.Errorf("content-type = %s", ct)
+			}
+			json.NewDecoder(r.Body).Decode(&patch)
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+	args := map[string]interface{}{"cluster": "c-xxx", "api_version": "v1", "kind": "PersistentVolumeClaim", "namespace": "shop", "name": "data"}
+	result, err := toolset.removeFinalizersHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("explain: err=%v result=%v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "still mounted by a pod") || !strings.Contains(text, "still attached to a node") || patch != nil {
+		t.Errorf("explain output = %s", text)
+	}
+
+	args["finalizers"] = "unknown.io/x"
+	result, _ = toolset.removeFinalizersHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected unknown finalizer to be rejected")
+	}
+
+	args["finalizers"] = "external-attacher/driver-longhorn-io"
+	result, err = toolset.removeFinalizersHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("remove: err=%v result=%v", err, result)
+	}
+	if len(patch) != 2 || patch[0]["op"] != "test" || patch[1]["op"] != "replace" {
+		t.Fatalf("patch = %v", patch)
+	}
+	if got, _ := json.Marshal(patch[1]["value"]); string(got) != `["kubernetes.io/pvc-protection"]` {
+		t.Errorf("remaining finalizers = %s", got)
+	}
+
+	deletionTimestamp = ""
+	result, _ = toolset.removeFinalizersHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected finalizer removal on a live object to be refused")
+	}
+
+	toolset.policy.DisableDestructive = true
+	result, _ = toolset.removeFinalizersHandler(context.Background(), callToolRequest(args))
+	if !result.IsError {
+		t.Error("expected disable-destructive to block finalizer removal")
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	lists := map[string]interface{}{
+		"/k8s/clusters/c-xxx/apis/apps/v1/namespaces/shop/deployments": map[string]interface{}{"items": []interface{}{map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "shop", "uid": "u-1", "resourceVersion": "42", "managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+				"annotations": map[string]interface{}{"deployment.kubernetes.io/revision": "3", "field.cattle.io/publicEndpoints": "[]", "team": "shop"}},
+			"spec":   map[string]interface{}{"replicas": 2},
+			"status": map[string]interface{}{"readyReplicas": 2},
+		}}},
+		"/k8s/clusters/c-xxx/api/v1/namespaces/shop/services": map[string]interface{}{"items": []interface{}{map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "shop"},
+			"spec":     map[string]interface{}{"clusterIP": "10.43.0.10", "clusterIPs": []interface{}{"10.43.0.10"}, "ports": []interface{}{map[string]interface{}{"port": 80}}},
+		}}},
+		"/k8s/clusters/c-xxx/api/v1/namespaces/shop/secrets": map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "creds", "namespace": "shop"}, "type": "Opaque", "data": map[string]interface{}{"password": "c2VjcmV0"}},
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "sa-token", "namespace": "shop"}, "type": "kubernetes.io/service-account-token"},
+		}},
+		"/k8s/clusters/c-xxx/api/v1/namespaces/shop/serviceaccounts": map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "default", "namespace": "shop"}},
+		}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, ok := lists[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer srv.Close()
+	toolset := NewToolset(rancher.NewSteveClient(srv.URL, "token", true), &security.Policy{})
+
+	args := map[string]interface{}{"cluster": "c-xxx", "namespace": "shop", "kinds": "ServiceAccount,Secret,Service,Deployment"}
+	result, err := toolset.exportHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("exportHandler: err=%v result=%v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"kind: Deployment", "apiVersion: apps/v1", "namespace: shop", "team: shop", "kind: Service", "port: 80"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("export missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"uid", "resourceVersion", "managedFields", "status", "revision", "field.cattle.io", "clusterIP", "kind: Secret", "name: default"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("export contains %q:\n%s", unwanted, text)
+		}
+	}
+
+	args["secrets"] = "redact"
+	result, _ = toolset.exportHandler(context.Background(), callToolRequest(args))
+	text = result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "password: <redacted>") || strings.Contains(text, "c2VjcmV0") || strings.Contains(text, "sa-token") {
+		t.Errorf("redacted export:\n%s", text)
+	}
+
+	for _, kinds := range []string{"v1/secret", "v1/secrets"} {
+		lower := map[string]interface{}{"cluster": "c-xxx", "namespace": "shop", "kinds": kinds}
+		result, _ = toolset.exportHandler(context.Background(), callToolRequest(lower))
+		if text = result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "c2VjcmV0") {
+			t.Errorf("kinds=%s exported Secret data:\n%s", kinds, text)
+		}
+		lower["secrets"] = "redact"
+		result, _ = toolset.exportHandler(context.Background(), callToolRequest(lower))
+		if text = result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "password: <redacted>") || strings.Contains(text, "c2VjcmV0") {
+			t.Errorf("kinds=%s secrets=redact:\n%s", kinds, text)
+		}
+	}
+
+	args["secrets"] = "include"
+	if result, _ = toolset.exportHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected secrets=include to require show-sensitive-data")
+	}
+
+	args["secrets"] = "exclude"
+	args["max_bytes"] = float64(100)
+	if result, _ = toolset.exportHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected inline export over max_bytes to fail")
+	}
+
+	args["output"] = "file"
+	if result, _ = toolset.exportHandler(context.Background(), callToolRequest(args)); !result.IsError {
+		t.Error("expected output=file without an export directory to fail")
+	}
+	toolset.policy.ExportDir = t.TempDir()
+	result, err = toolset.exportHandler(context.Background(), callToolRequest(args))
+	if err != nil || result.IsError {
+		t.Fatalf("export to file: err=%v result=%v", err, result)
+	}
+	var report struct {
+		Path    string         `json:"path"`
+		Objects map[string]int `json:"objects"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	data, err := os.ReadFile(report.Path)
+	if err != nil || !strings.Contains(string(data), "kind: Deployment") || report.Objects["Deployment"] != 1 {
+		t.Errorf("exported file %s: err=%v objects=%v", report.Path, err, report.Objects)
+	}
+}
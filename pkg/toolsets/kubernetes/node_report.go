@@ -0,0 +1,258 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+)
+
+const labelNodeRolePrefix = "node-role.kubernetes.io/"
+
+// nodePressureConditions are node conditions that indicate a problem when True.
+var nodePressureConditions = []string{"MemoryPressure", "DiskPressure", "PIDPressure", "NetworkUnavailable"}
+
+func (t *Toolset) nodeReportTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_node_report",
+		mcp.WithDescription("Per-node diagnostics: readiness and pressure conditions, taints, kubelet version, and CPU/memory/pod requests and limits of scheduled pods vs allocatable. Flags over-committed nodes (limits above allocatable) and warns when requests near allocatable."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("node", mcp.Description("Only report this node (optional)")),
+		mcp.WithString("label_selector", mcp.Description("Node label selector (optional)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) nodeReportHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	nodeName := req.GetString("node", "")
+	format := req.GetString("format", "json")
+
+	nodes, err := t.listAll(ctx, cluster, rancher.TypeNodes, rancher.ListOpts{LabelSelector: req.GetString("label_selector", "")})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list nodes: %v", err)), nil
+	}
+	podOpts := rancher.ListOpts{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"}
+	if nodeName != "" {
+		podOpts.FieldSelector += ",spec.nodeName=" + nodeName
+	}
+	pods, err := t.listAll(ctx, cluster, "core.v1.pods", podOpts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list pods: %v", err)), nil
+	}
+
+	usage := map[string]*nodeUsage{}
+	for _, pod := range pods {
+		if phase := podPhase(pod); phase == "Succeeded" || phase == "Failed" {
+			continue
+		}
+		node, _ := asMap(pod.Spec)["nodeName"].(string)
+		if node == "" {
+			continue
+		}
+		u := usage[node]
+		if u == nil {
+			u = &nodeUsage{}
+			usage[node] = u
+		}
+		requests, limits := podResources(asMap(pod.Spec))
+		u.requests.add(requests)
+		u.limits.add(limits)
+		u.pods++
+	}
+
+	reports := make([]nodeReport, 0, len(nodes))
+	for _, n := range nodes {
+		if nodeName != "" && n.ObjectMeta.Name != nodeName {
+			continue
+		}
+		u := usage[n.ObjectMeta.Name]
+		if u == nil {
+			u = &nodeUsage{}
+		}
+		reports = append(reports, buildNodeReport(n, u))
+	}
+	if nodeName != "" && len(reports) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("node %q not found", nodeName)), nil
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+
+	if format == formatter.FormatTable {
+		return mcp.NewToolResultText(nodeReportTable(reports)), nil
+	}
+	overcommitted := 0
+	for _, r := range reports {
+		if r.Overcommitted {
+			overcommitted++
+		}
+	}
+	out, err := t.formatter.Format(map[string]interface{}{
+		"node_count":          len(reports),
+		"overcommitted_nodes": overcommitted,
+		"nodes":               reports,
+	}, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// nodeRequestsWarnPct is the share of allocatable requested by pods from which a node is reported as nearly full.
+const nodeRequestsWarnPct = 90
+
+type nodeUsage struct {
+	requests resourceTotals
+	limits   resourceTotals
+	pods     int64
+}
+
+type nodeResource struct {
+	Allocatable string `json:"allocatable"`
+	Requests    string `json:"requests"`
+	Limits      string `json:"limits"`
+	RequestsPct int64  `json:"requests_percent"`
+	LimitsPct   int64  `json:"limits_percent"`
+}
+
+type nodeReport struct {
+	Name           string            `json:"name"`
+	Ready          string            `json:"ready"`
+	Roles          []string          `json:"roles,omitempty"`
+	Unschedulable  bool              `json:"unschedulable,omitempty"`
+	KubeletVersion string            `json:"kubelet_version,omitempty"`
+	Conditions     map[string]string `json:"conditions,omitempty"`
+	Taints         []string          `json:"taints,omitempty"`
+	CPU            nodeResource      `json:"cpu"`
+	Memory         nodeResource      `json:"memory"`
+	Pods           string            `json:"pods"`
+	PodsPct        int64             `json:"pods_percent"`
+	Overcommitted  bool              `json:"overcommitted"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+func buildNodeReport(n rancher.SteveResource, u *nodeUsage) nodeReport {
+	status := asMap(n.Status)
+	spec := asMap(n.Spec)
+	r := nodeReport{Name: n.ObjectMeta.Name, Ready: "Unknown", Conditions: map[string]string{}}
+
+	for label := range n.ObjectMeta.Labels {
+		if role, ok := strings.CutPrefix(label, labelNodeRolePrefix); ok && role != "" {
+			r.Roles = append(r.Roles, role)
+		}
+	}
+	sort.Strings(r.Roles)
+	r.Unschedulable, _ = spec["unschedulable"].(bool)
+	r.KubeletVersion, _ = nestedMap(status, "nodeInfo")["kubeletVersion"].(string)
+
+	conditions, _ := status["conditions"].([]interface{})
+	for _, c := range conditions {
+		cm := asMap(c)
+		typ, _ := cm["type"].(string)
+		st, _ := cm["status"].(string)
+		r.Conditions[typ] = st
+		if typ == "Ready" {
+			r.Ready = st
+		}
+	}
+	if r.Ready != "True" {
+		r.Warnings = append(r.Warnings, "NotReady")
+	}
+	for _, typ := range nodePressureConditions {
+		if r.Conditions[typ] == "True" {
+			r.Warnings = append(r.Warnings, typ)
+		}
+	}
+	if r.Unschedulable {
+		r.Warnings = append(r.Warnings, "SchedulingDisabled")
+	}
+
+	taints, _ := spec["taints"].([]interface{})
+	for _, tt := range taints {
+		tm := asMap(tt)
+		key, _ := tm["key"].(string)
+		value, _ := tm["value"].(string)
+		effect, _ := tm["effect"].(string)
+		s := key
+		if value != "" {
+			s += "=" + value
+		}
+		r.Taints = append(r.Taints, s+":"+effect)
+	}
+
+	alloc := resourceList(asMap(status["allocatable"]))
+	r.CPU = nodeResource{
+		Allocatable: formatCPU(alloc.CPUMilli),
+		Requests:    formatCPU(u.requests.CPUMilli),
+		Limits:      formatCPU(u.limits.CPUMilli),
+		RequestsPct: percent(u.requests.CPUMilli, alloc.CPUMilli),
+		LimitsPct:   percent(u.limits.CPUMilli, alloc.CPUMilli),
+	}
+	r.Memory = nodeResource{
+		Allocatable: formatMemory(alloc.MemoryBytes),
+		Requests:    formatMemory(u.requests.MemoryBytes),
+		Limits:      formatMemory(u.limits.MemoryBytes),
+		RequestsPct: percent(u.requests.MemoryBytes, alloc.MemoryBytes),
+		LimitsPct:   percent(u.limits.MemoryBytes, alloc.MemoryBytes),
+	}
+	podsQty := parseQuantity(asMap(status["allocatable"])["pods"])
+	maxPods := podsQty.Value()
+	r.Pods = fmt.Sprintf("%d/%d", u.pods, maxPods)
+	r.PodsPct = percent(u.pods, maxPods)
+
+	for _, res := range []struct {
+		name string
+		nr   nodeResource
+	}{{"cpu", r.CPU}, {"memory", r.Memory}} {
+		if res.nr.RequestsPct >= nodeRequestsWarnPct {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s requests %d%% of allocatable", res.name, res.nr.RequestsPct))
+		}
+		if res.nr.LimitsPct > 100 {
+			r.Overcommitted = true
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s limits %d%% of allocatable", res.name, res.nr.LimitsPct))
+		}
+	}
+	if maxPods > 0 && u.pods >= maxPods {
+		r.Overcommitted = true
+		r.Warnings = append(r.Warnings, "pod capacity reached")
+	}
+	return r
+}
+
+func nodeReportTable(reports []nodeReport) string {
+	rows := make([][]string, 0, len(reports))
+	for _, r := range reports {
+		status := "Ready"
+		if r.Ready != "True" {
+			status = "NotReady"
+		}
+		if r.Unschedulable {
+			status += ",SchedulingDisabled"
+		}
+		roles := strings.Join(r.Roles, ",")
+		if roles == "" {
+			roles = "<none>"
+		}
+		taints := strconv.Itoa(len(r.Taints))
+		warnings := strings.Join(r.Warnings, "; ")
+		if warnings == "" {
+			warnings = "-"
+		}
+		rows = append(rows, []string{
+			r.Name, status, roles, r.KubeletVersion,
+			fmt.Sprintf("%s/%s (%d%%)", r.CPU.Requests, r.CPU.Allocatable, r.CPU.RequestsPct),
+			fmt.Sprintf("%s (%d%%)", r.CPU.Limits, r.CPU.LimitsPct),
+			fmt.Sprintf("%s/%s (%d%%)", r.Memory.Requests, r.Memory.Allocatable, r.Memory.RequestsPct),
+			fmt.Sprintf("%s (%d%%)", r.Memory.Limits, r.Memory.LimitsPct),
+			r.Pods, taints, warnings,
+		})
+	}
+	return renderTable([]string{"NAME", "STATUS", "ROLES", "VERSION", "CPU REQ", "CPU LIM", "MEM REQ", "MEM LIM", "PODS", "TAINTS", "WARNINGS"}, rows)
+}
@@ -0,0 +1,485 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	maxOwnerDepth             = 5
+	defaultRelatedDependDepth = 2
+	maxRelatedDependDepth     = 4
+
+	annotationHelmReleaseName      = "meta.helm.sh/release-name"
+	annotationHelmReleaseNamespace = "meta.helm.sh/release-namespace"
+)
+
+// Edge relations in a kubernetes_related graph.
+const (
+	relationOwns       = "owns"
+	relationReferences = "references"
+	relationSelects    = "selects"
+	relationManages    = "manages"
+	relationScheduled  = "scheduled-on"
+)
+
+// dependentTypes lists the Steve types that may be owned by each kind (walked down by owner UID).
+var dependentTypes = map[string][]string{
+	"Deployment":             {"apps.v1.replicasets"},
+	"ReplicaSet":             {"core.v1.pods"},
+	"StatefulSet":            {"core.v1.pods", "core.v1.persistentvolumeclaims"},
+	"DaemonSet":              {"core.v1.pods"},
+	"Job":                    {"core.v1.pods"},
+	"CronJob":                {"batch.v1.jobs"},
+	"VirtualMachine":         {rancher.TypeVirtualMachineInstances},
+	"VirtualMachineInstance": {"core.v1.pods"},
+}
+
+// selectorTypes are the namespaced objects whose label selectors are matched against pod labels.
+var selectorTypes = []struct {
+	kind, apiVersion, resourceType, path string
+}{
+	{"Service", "v1", "core.v1.services", "spec.selector"},
+	{"NetworkPolicy", "networking.k8s.io/v1", "networking.k8s.io.v1.networkpolicies", "spec.podSelector"},
+	{"PodDisruptionBudget", "policy/v1", "policy.v1.poddisruptionbudgets", "spec.selector"},
+}
+
+type relatedNode struct {
+	ID         string `json:"id"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Root       bool   `json:"root,omitempty"`
+	Missing    bool   `json:"missing,omitempty"`
+}
+
+type relatedEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+	Via      string `json:"via,omitempty"`
+}
+
+// relatedGraph accumulates nodes and edges while walking; lists are cached per namespace/type.
+type relatedGraph struct {
+	nodes   map[string]*relatedNode
+	order   []string
+	edges   []relatedEdge
+	seen    map[string]bool // edge dedup
+	checked map[string]bool // referenced objects already fetched
+	lists   map[string][]rancher.SteveResource
+}
+
+func relatedID(kind, namespace, name string) string {
+	if namespace == "" {
+		return kind + "/" + name
+	}
+	return kind + "/" + namespace + "/" + name
+}
+
+func (g *relatedGraph) node(apiVersion, kind, namespace, name string) *relatedNode {
+	id := relatedID(kind, namespace, name)
+	if n, ok := g.nodes[id]; ok {
+		return n
+	}
+	n := &relatedNode{ID: id, APIVersion: apiVersion, Kind: kind, Namespace: namespace, Name: name}
+	g.nodes[id] = n
+	g.order = append(g.order, id)
+	return n
+}
+
+func (g *relatedGraph) edge(from, to, relation, via string) {
+	key := from + "|" + to + "|" + relation + "|" + via
+	if g.seen[key] {
+		return
+	}
+	g.seen[key] = true
+	g.edges = append(g.edges, relatedEdge{From: from, To: to, Relation: relation, Via: via})
+}
+
+func (t *Toolset) relatedTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_related",
+		mcp.WithDescription("Relationship graph for a resource: owners (ownerReferences up), dependents (owned objects down), spec references (ConfigMaps, Secrets, PVCs, ServiceAccount, Node), Services/NetworkPolicies/PodDisruptionBudgets whose selectors match its pods, and the Helm release managing it. Returns nodes, edges and a compact tree."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, apps/v1)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Pod, Deployment)")),
+		mcp.WithString("namespace", mcp.Description("Namespace (required for namespaced resources)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Resource name")),
+		mcp.WithNumber("dependent_depth", mcp.Description("Levels of owned objects to walk down (default: 2, max: 4)")),
+		mcp.WithString("format", mcp.Description("Output format: json (graph + tree), tree (text only) (default: json)")),
+	)
+}
+
+func (t *Toolset) relatedHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	apiVersion, err := req.RequireString("api_version")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	depth := req.GetInt("dependent_depth", defaultRelatedDependDepth)
+	if depth < 0 {
+		depth = defaultRelatedDependDepth
+	}
+	if depth > maxRelatedDependDepth {
+		depth = maxRelatedDependDepth
+	}
+	format := req.GetString("format", "json")
+
+	root, err := t.client.Get(ctx, cluster, rancher.SteveType(apiVersion, kind), namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s %q not found: %v", kind, name, err)), nil
+	}
+	g := &relatedGraph{
+		nodes:   map[string]*relatedNode{},
+		seen:    map[string]bool{},
+		checked: map[string]bool{},
+		lists:   map[string][]rancher.SteveResource{},
+	}
+	rootNode := g.node(apiVersion, kind, namespace, name)
+	rootNode.Root = true
+
+	top := t.walkOwners(ctx, cluster, g, root, rootNode, 0)
+	t.walkDependents(ctx, cluster, g, root, rootNode, depth)
+	t.addHelmRelease(g, top.res, top.node)
+
+	// References and selectors come from the root's pod spec (or pod template for workloads).
+	t.addReferences(ctx, cluster, g, root, rootNode)
+	t.addSelectors(ctx, cluster, g, root, rootNode)
+
+	tree := g.tree(top.node.ID)
+	if format == "tree" {
+		return mcp.NewToolResultText(tree), nil
+	}
+	nodes := make([]*relatedNode, 0, len(g.order))
+	for _, id := range g.order {
+		nodes = append(nodes, g.nodes[id])
+	}
+	out, err := t.formatter.Format(map[string]interface{}{
+		"root":  rootNode.ID,
+		"nodes": nodes,
+		"edges": g.edges,
+		"tree":  tree,
+	}, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+type ownedResource struct {
+	res  *rancher.SteveResource
+	node *relatedNode
+}
+
+// walkOwners follows ownerReferences upward and returns the top-most owner found (res itself when unowned).
+func (t *Toolset) walkOwners(ctx context.Context, cluster string, g *relatedGraph, res *rancher.SteveResource, node *relatedNode, level int) ownedResource {
+	top := ownedResource{res: res, node: node}
+	if level >= maxOwnerDepth {
+		return top
+	}
+	for _, ref := range res.ObjectMeta.OwnerReferences {
+		owner := g.node(ref.APIVersion, ref.Kind, res.ObjectMeta.Namespace, ref.Name)
+		g.edge(owner.ID, node.ID, relationOwns, "")
+		ownerRes, err := t.client.Get(ctx, cluster, rancher.SteveType(ref.APIVersion, ref.Kind), res.ObjectMeta.Namespace, ref.Name)
+		if err != nil {
+			owner.Missing = true
+			continue
+		}
+		if (ref.Controller != nil && *ref.Controller) || len(res.ObjectMeta.OwnerReferences) == 1 {
+			top = t.walkOwners(ctx, cluster, g, ownerRes, owner, level+1)
+		}
+	}
+	return top
+}
+
+// walkDependents lists objects owned by res (by UID) down to depth levels.
+func (t *Toolset) walkDependents(ctx context.Context, cluster string, g *relatedGraph, res *rancher.SteveResource, node *relatedNode, depth int) {
+	if depth <= 0 || res.ObjectMeta.UID == "" {
+		return
+	}
+	for _, resourceType := range dependentTypes[node.Kind] {
+		items, err := t.cachedList(ctx, cluster, g, resourceType, res.ObjectMeta.Namespace)
+		if err != nil {
+			continue
+		}
+		for i := range items {
+			child := &items[i]
+			if !ownedBy(child.ObjectMeta, res.ObjectMeta) {
+				continue
+			}
+			childKind, childAPIVersion := child.TypeMeta.Kind, child.TypeMeta.APIVersion
+			if childKind == "" {
+				childKind, childAPIVersion = kindForType(resourceType)
+			}
+			childNode := g.node(childAPIVersion, childKind, child.ObjectMeta.Namespace, child.ObjectMeta.Name)
+			g.edge(node.ID, childNode.ID, relationOwns, "")
+			t.walkDependents(ctx, cluster, g, child, childNode, depth-1)
+		}
+	}
+}
+
+// kindForType returns the kind and apiVersion for the Steve types walked as dependents.
+func kindForType(resourceType string) (string, string) {
+	switch resourceType {
+	case "apps.v1.replicasets":
+		return "ReplicaSet", "apps/v1"
+	case "batch.v1.jobs":
+		return "Job", "batch/v1"
+	case "core.v1.persistentvolumeclaims":
+		return "PersistentVolumeClaim", "v1"
+	case rancher.TypeVirtualMachineInstances:
+		return "VirtualMachineInstance", "kubevirt.io/v1"
+	}
+	return "Pod", "v1"
+}
+
+func (t *Toolset) cachedList(ctx context.Context, cluster string, g *relatedGraph, resourceType, namespace string) ([]rancher.SteveResource, error) {
+	key := resourceType + "/" + namespace
+	if items, ok := g.lists[key]; ok {
+		return items, nil
+	}
+	items, err := t.listAll(ctx, cluster, resourceType, rancher.ListOpts{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	g.lists[key] = items
+	return items, nil
+}
+
+// addHelmRelease links the Helm release recorded in the top-most owner's annotations.
+func (t *Toolset) addHelmRelease(g *relatedGraph, res *rancher.SteveResource, node *relatedNode) {
+	release := res.ObjectMeta.Annotations[annotationHelmReleaseName]
+	if release == "" {
+		return
+	}
+	releaseNS := res.ObjectMeta.Annotations[annotationHelmReleaseNamespace]
+	if releaseNS == "" {
+		releaseNS = res.ObjectMeta.Namespace
+	}
+	if t.policy.CheckNamespace(releaseNS) != nil {
+		return
+	}
+	helm := g.node("helm.sh/v3", "HelmRelease", releaseNS, release)
+	g.edge(helm.ID, node.ID, relationManages, "")
+}
+
+// podSpecOf returns the pod spec and pod labels of a Pod or of a workload's pod template.
+func podSpecOf(kind string, res *rancher.SteveResource) (map[string]interface{}, map[string]string) {
+	spec := asMap(res.Spec)
+	switch kind {
+	case "Pod":
+		return spec, res.ObjectMeta.Labels
+	case "CronJob":
+		spec = nestedMap(spec, "jobTemplate", "spec")
+	}
+	template := nestedMap(spec, "template")
+	if template == nil {
+		return nil, nil
+	}
+	lbls := map[string]string{}
+	for k, v := range nestedMap(template, "metadata", "labels") {
+		if s, ok := v.(string); ok {
+			lbls[k] = s
+		}
+	}
+	return nestedMap(template, "spec"), lbls
+}
+
+// addReferences links ConfigMaps, Secrets, PVCs, the ServiceAccount and the Node referenced by the pod spec.
+func (t *Toolset) addReferences(ctx context.Context, cluster string, g *relatedGraph, res *rancher.SteveResource, node *relatedNode) {
+	spec, _ := podSpecOf(node.Kind, res)
+	if spec == nil {
+		return
+	}
+	ns := res.ObjectMeta.Namespace
+	ref := func(kind, resourceType, namespace, name, via string) {
+		if name == "" {
+			return
+		}
+		target := g.node("v1", kind, namespace, name)
+		g.edge(node.ID, target.ID, relationReferences, via)
+		if g.checked[target.ID] {
+			return
+		}
+		g.checked[target.ID] = true
+		if _, err := t.client.Get(ctx, cluster, resourceType, namespace, name); err != nil {
+			target.Missing = true
+		}
+	}
+	str := func(m map[string]interface{}, key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+
+	sa := str(spec, "serviceAccountName")
+	if sa == "" && node.Kind == "Pod" {
+		sa = "default"
+	}
+	ref("ServiceAccount", "core.v1.serviceaccounts", ns, sa, "serviceAccountName")
+	if nodeName := str(spec, "nodeName"); nodeName != "" {
+		target := g.node("v1", "Node", "", nodeName)
+		g.edge(node.ID, target.ID, relationScheduled, "spec.nodeName")
+	}
+	pullSecrets, _ := spec["imagePullSecrets"].([]interface{})
+	for _, s := range pullSecrets {
+		ref("Secret", "core.v1.secrets", ns, str(asMap(s), "name"), "imagePullSecrets")
+	}
+	volumes, _ := spec["volumes"].([]interface{})
+	for _, v := range volumes {
+		vm := asMap(v)
+		via := "volume " + str(vm, "name")
+		ref("ConfigMap", "core.v1.configmaps", ns, str(nestedMap(vm, "configMap"), "name"), via)
+		ref("Secret", "core.v1.secrets", ns, str(nestedMap(vm, "secret"), "secretName"), via)
+		ref("PersistentVolumeClaim", "core.v1.persistentvolumeclaims", ns, str(nestedMap(vm, "persistentVolumeClaim"), "claimName"), via)
+		sources, _ := nestedMap(vm, "projected")["sources"].([]interface{})
+		for _, src := range sources {
+			sm := asMap(src)
+			ref("ConfigMap", "core.v1.configmaps", ns, str(nestedMap(sm, "configMap"), "name"), via)
+			ref("Secret", "core.v1.secrets", ns, str(nestedMap(sm, "secret"), "name"), via)
+		}
+	}
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, _ := spec[key].([]interface{})
+		for _, c := range containers {
+			cm := asMap(c)
+			via := "container " + str(cm, "name")
+			envFrom, _ := cm["envFrom"].([]interface{})
+			for _, ef := range envFrom {
+				efm := asMap(ef)
+				ref("ConfigMap", "core.v1.configmaps", ns, str(nestedMap(efm, "configMapRef"), "name"), via+" envFrom")
+				ref("Secret", "core.v1.secrets", ns, str(nestedMap(efm, "secretRef"), "name"), via+" envFrom")
+			}
+			env, _ := cm["env"].([]interface{})
+			for _, e := range env {
+				em := asMap(e)
+				ref("ConfigMap", "core.v1.configmaps", ns, str(nestedMap(em, "valueFrom", "configMapKeyRef"), "name"), via+" env "+str(em, "name"))
+				ref("Secret", "core.v1.secrets", ns, str(nestedMap(em, "valueFrom", "secretKeyRef"), "name"), via+" env "+str(em, "name"))
+			}
+		}
+	}
+}
+
+// addSelectors links Services, NetworkPolicies and PodDisruptionBudgets whose selectors match the pod labels.
+func (t *Toolset) addSelectors(ctx context.Context, cluster string, g *relatedGraph, res *rancher.SteveResource, node *relatedNode) {
+	_, podLabels := podSpecOf(node.Kind, res)
+	if podLabels == nil {
+		return
+	}
+	for _, st := range selectorTypes {
+		items, err := t.cachedList(ctx, cluster, g, st.resourceType, res.ObjectMeta.Namespace)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			if !selectorMatches(st.kind, nestedMap(asMap(item.Spec), strings.Split(strings.TrimPrefix(st.path, "spec."), ".")...), podLabels) {
+				continue
+			}
+			n := g.node(st.apiVersion, st.kind, item.ObjectMeta.Namespace, item.ObjectMeta.Name)
+			g.edge(n.ID, node.ID, relationSelects, st.path)
+		}
+	}
+}
+
+// selectorMatches evaluates a Service selector (plain map; empty selects nothing) or a LabelSelector
+// (matchLabels/matchExpressions; empty selects everything) against pod labels.
+func selectorMatches(kind string, selector map[string]interface{}, podLabels map[string]string) bool {
+	if kind == "Service" {
+		if len(selector) == 0 {
+			return false
+		}
+		for k, v := range selector {
+			if podLabels[k] != v {
+				return false
+			}
+		}
+		return true
+	}
+	var ls metav1.LabelSelector
+	b, err := json.Marshal(selector)
+	if err != nil || json.Unmarshal(b, &ls) != nil {
+		return false
+	}
+	sel, err := metav1.LabelSelectorAsSelector(&ls)
+	if err != nil {
+		return false
+	}
+	return sel.Matches(labels.Set(podLabels))
+}
+
+// tree renders the ownership hierarchy from top plus each node's other relations as indented text.
+func (g *relatedGraph) tree(top string) string {
+	children := map[string][]relatedEdge{}
+	for _, e := range g.edges {
+		from := e.From
+		if e.Relation == relationSelects || e.Relation == relationManages {
+			from = e.To // show "selected by"/"managed by" under the target
+		}
+		children[from] = append(children[from], e)
+	}
+	var b strings.Builder
+	label := func(id string) string {
+		n := g.nodes[id]
+		s := id
+		if n.Root {
+			s += " *"
+		}
+		if n.Missing {
+			s += " [missing]"
+		}
+		return s
+	}
+	visited := map[string]bool{}
+	var walk func(id string, indent string)
+	walk = func(id, indent string) {
+		visited[id] = true
+		edges := children[id]
+		sort.SliceStable(edges, func(i, j int) bool { return edges[i].Relation == relationOwns && edges[j].Relation != relationOwns })
+		for _, e := range edges {
+			switch e.Relation {
+			case relationOwns:
+				fmt.Fprintf(&b, "%s└─ %s\n", indent, label(e.To))
+				if !visited[e.To] {
+					walk(e.To, indent+"   ")
+				}
+			case relationSelects:
+				fmt.Fprintf(&b, "%s· selected by %s\n", indent, label(e.From))
+			case relationManages:
+				fmt.Fprintf(&b, "%s· managed by %s\n", indent, label(e.From))
+			default:
+				via := ""
+				if e.Via != "" {
+					via = " (" + e.Via + ")"
+				}
+				fmt.Fprintf(&b, "%s· %s %s%s\n", indent, e.Relation, label(e.To), via)
+			}
+		}
+	}
+	b.WriteString(label(top) + "\n")
+	walk(top, "")
+	return strings.TrimRight(b.String(), "\n")
+}
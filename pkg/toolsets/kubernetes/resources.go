@@ -0,0 +1,140 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// maxListPages bounds listAll so a huge cluster cannot turn one tool call into an unbounded scan.
+const maxListPages = 20
+
+// listAll follows continue tokens (500 per page, up to maxListPages pages) and returns all items.
+func (t *Toolset) listAll(ctx context.Context, cluster, resourceType string, opts rancher.ListOpts) ([]rancher.SteveResource, error) {
+	opts.Limit = 500
+	var items []rancher.SteveResource
+	for page := 0; page < maxListPages; page++ {
+		col, err := t.client.List(ctx, cluster, resourceType, opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, col.Data...)
+		if col.Continue == "" {
+			break
+		}
+		opts.Continue = col.Continue
+	}
+	return items, nil
+}
+
+// resourceTotals holds CPU in millicores and memory in bytes.
+type resourceTotals struct {
+	CPUMilli    int64
+	MemoryBytes int64
+}
+
+func (r *resourceTotals) add(o resourceTotals) {
+	r.CPUMilli += o.CPUMilli
+	r.MemoryBytes += o.MemoryBytes
+}
+
+// parseQuantity parses a Kubernetes quantity (string or number); invalid or missing values are zero.
+func parseQuantity(v interface{}) resource.Quantity {
+	switch x := v.(type) {
+	case string:
+		if q, err := resource.ParseQuantity(x); err == nil {
+			return q
+		}
+	case float64:
+		return *resource.NewMilliQuantity(int64(x*1000), resource.DecimalSI)
+	}
+	return resource.Quantity{}
+}
+
+// resourceList converts a ResourceList map (e.g. status.allocatable, resources.requests) to totals.
+func resourceList(m map[string]interface{}) resourceTotals {
+	cpu := parseQuantity(m["cpu"])
+	mem := parseQuantity(m["memory"])
+	return resourceTotals{CPUMilli: cpu.MilliValue(), MemoryBytes: mem.Value()}
+}
+
+// podResources returns the effective requests and limits of a pod spec the way the scheduler counts them:
+// max(sum of containers, largest init container) plus pod overhead.
+func podResources(spec map[string]interface{}) (requests, limits resourceTotals) {
+	sum := func(key string) (total, initMax resourceTotals) {
+		containers, _ := spec["containers"].([]interface{})
+		for _, c := range containers {
+			total.add(resourceList(nestedMap(asMap(c), "resources", key)))
+		}
+		initContainers, _ := spec["initContainers"].([]interface{})
+		for _, c := range initContainers {
+			r := resourceList(nestedMap(asMap(c), "resources", key))
+			initMax.CPUMilli = max(initMax.CPUMilli, r.CPUMilli)
+			initMax.MemoryBytes = max(initMax.MemoryBytes, r.MemoryBytes)
+		}
+		return total, initMax
+	}
+	overhead := resourceList(asMap(spec["overhead"]))
+	for i, key := range []string{"requests", "limits"} {
+		total, initMax := sum(key)
+		r := resourceTotals{CPUMilli: max(total.CPUMilli, initMax.CPUMilli), MemoryBytes: max(total.MemoryBytes, initMax.MemoryBytes)}
+		r.add(overhead)
+		if i == 0 {
+			requests = r
+		} else {
+			limits = r
+		}
+	}
+	return requests, limits
+}
+
+// podPhase returns status.phase of a pod.
+func podPhase(pod rancher.SteveResource) string {
+	phase, _ := asMap(pod.Status)["phase"].(string)
+	return phase
+}
+
+// formatCPU renders millicores like kubectl (e.g. 250m, 2).
+func formatCPU(milli int64) string {
+	if milli%1000 == 0 {
+		return fmt.Sprintf("%d", milli/1000)
+	}
+	return fmt.Sprintf("%dm", milli)
+}
+
+// formatMemory renders bytes in binary units (Ki, Mi, Gi).
+func formatMemory(b int64) string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1fGi", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%dMi", b/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%dKi", b/(1<<10))
+	}
+	return fmt.Sprintf("%d", b)
+}
+
+// percent returns used/total as a whole percentage (0 when total is 0).
+func percent(used, total int64) int64 {
+	if total <= 0 {
+		return 0
+	}
+	return used * 100 / total
+}
+
+// renderTable writes rows as a tab-aligned table with the given header.
+func renderTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,661 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
+)
+
+const (
+	annotationRestartedAt        = "kubectl.kubernetes.io/restartedAt"
+	annotationDeploymentRevision = "deployment.kubernetes.io/revision"
+)
+
+// rolloutKinds maps the workload kinds supported by rollout tools to their Steve types.
+var rolloutKinds = map[string]string{
+	"deployment":  "apps.v1.deployments",
+	"statefulset": "apps.v1.statefulsets",
+	"daemonset":   "apps.v1.daemonsets",
+}
+
+// rolloutType returns the canonical kind and Steve type for a rollout kind (case-insensitive).
+func rolloutType(kind string) (string, string, error) {
+	k := strings.ToLower(kind)
+	resourceType, ok := rolloutKinds[k]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported kind %q; use Deployment, StatefulSet or DaemonSet", kind)
+	}
+	switch k {
+	case "statefulset":
+		return "StatefulSet", resourceType, nil
+	case "daemonset":
+		return "DaemonSet", resourceType, nil
+	}
+	return "Deployment", resourceType, nil
+}
+
+func (t *Toolset) rolloutStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_rollout_status",
+		mcp.WithDescription("Report rollout status of a Deployment, StatefulSet or DaemonSet: desired/updated/ready/available replicas, whether the rollout is complete, and stuck conditions (e.g. ProgressDeadlineExceeded)"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind: Deployment, StatefulSet, DaemonSet")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Workload name")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) rolloutStatusHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kindArg, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	format := req.GetString("format", "json")
+
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, resourceType, err := rolloutType(kindArg)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	res, err := t.client.Get(ctx, cluster, resourceType, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s %q not found: %v", kind, name, err)), nil
+	}
+	out, err := t.formatter.Format(rolloutStatus(kind, res), format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// rolloutStatus summarizes a workload's rollout the way kubectl rollout status does.
+func rolloutStatus(kind string, res *rancher.SteveResource) map[string]interface{} {
+	spec, _ := res.Spec.(map[string]interface{})
+	status, _ := res.Status.(map[string]interface{})
+	name := res.ObjectMeta.Name
+	observedGeneration := nestedInt(status, "observedGeneration")
+	data := map[string]interface{}{
+		"kind":                kind,
+		"name":                name,
+		"namespace":           res.ObjectMeta.Namespace,
+		"generation":          res.ObjectMeta.Generation,
+		"observed_generation": observedGeneration,
+	}
+	complete := false
+	var message string
+	var stuck []string
+
+	switch kind {
+	case "Deployment":
+		desired := int64(1)
+		if _, ok := spec["replicas"]; ok {
+			desired = nestedInt(spec, "replicas")
+		}
+		updated := nestedInt(status, "updatedReplicas")
+		current := nestedInt(status, "replicas")
+		available := nestedInt(status, "availableReplicas")
+		data["replicas"] = map[string]interface{}{
+			"desired":     desired,
+			"current":     current,
+			"updated":     updated,
+			"ready":       nestedInt(status, "readyReplicas"),
+			"available":   available,
+			"unavailable": nestedInt(status, "unavailableReplicas"),
+		}
+		for _, c := range wait.Conditions(status) {
+			typ, _ := c["type"].(string)
+			st, _ := c["status"].(string)
+			reason, _ := c["reason"].(string)
+			msg, _ := c["message"].(string)
+			if (typ == "Progressing" && reason == "ProgressDeadlineExceeded") || (typ == "ReplicaFailure" && st == "True") {
+				stuck = append(stuck, fmt.Sprintf("%s: %s", reason, msg))
+			}
+		}
+		switch {
+		case res.ObjectMeta.Generation > observedGeneration:
+			message = "Waiting for deployment spec update to be observed"
+		case updated < desired:
+			message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated", name, updated, desired)
+		case current > updated:
+			message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination", name, current-updated)
+		case available < updated:
+			message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available", name, available, updated)
+		default:
+			complete = true
+			message = fmt.Sprintf("deployment %q successfully rolled out", name)
+		}
+
+	case "StatefulSet":
+		desired := int64(1)
+		if _, ok := spec["replicas"]; ok {
+			desired = nestedInt(spec, "replicas")
+		}
+		ready := nestedInt(status, "readyReplicas")
+		updated := nestedInt(status, "updatedReplicas")
+		currentRevision, _ := status["currentRevision"].(string)
+		updateRevision, _ := status["updateRevision"].(string)
+		data["replicas"] = map[string]interface{}{
+			"desired": desired,
+			"current": nestedInt(status, "currentReplicas"),
+			"updated": updated,
+			"ready":   ready,
+		}
+		data["current_revision"] = currentRevision
+		data["update_revision"] = updateRevision
+		strategy, _ := nestedMap(spec, "updateStrategy")["type"].(string)
+		partition := nestedInt(nestedMap(spec, "updateStrategy", "rollingUpdate"), "partition")
+		switch {
+		case strategy == "OnDelete":
+			complete = true
+			message = "rollout status is only available for RollingUpdate strategy (OnDelete updates pods only when they are deleted)"
+		case res.ObjectMeta.Generation > observedGeneration:
+			message = "Waiting for statefulset spec update to be observed"
+		case ready < desired:
+			message = fmt.Sprintf("Waiting for %d pods to be ready", desired-ready)
+		case partition > 0 && updated < desired-partition:
+			message = fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated", updated, desired-partition)
+		case partition == 0 && updateRevision != currentRevision:
+			message = fmt.Sprintf("Waiting for statefulset rolling update to complete: %d pods at revision %s", updated, updateRevision)
+		default:
+			complete = true
+			message = fmt.Sprintf("statefulset %q rolling update complete: %d pods at revision %s", name, ready, updateRevision)
+		}
+
+	case "DaemonSet":
+		desired := nestedInt(status, "desiredNumberScheduled")
+		updated := nestedInt(status, "updatedNumberScheduled")
+		available := nestedInt(status, "numberAvailable")
+		misscheduled := nestedInt(status, "numberMisscheduled")
+		data["replicas"] = map[string]interface{}{
+			"desired":      desired,
+			"current":      nestedInt(status, "currentNumberScheduled"),
+			"updated":      updated,
+			"ready":        nestedInt(status, "numberReady"),
+			"available":    available,
+			"misscheduled": misscheduled,
+		}
+		if misscheduled > 0 {
+			stuck = append(stuck, fmt.Sprintf("%d pods running on nodes they should not run on", misscheduled))
+		}
+		strategy, _ := nestedMap(spec, "updateStrategy")["type"].(string)
+		switch {
+		case strategy == "OnDelete":
+			complete = true
+			message = "rollout status is only available for RollingUpdate strategy (OnDelete updates pods only when they are deleted)"
+		case res.ObjectMeta.Generation > observedGeneration:
+			message = "Waiting for daemon set spec update to be observed"
+		case updated < desired:
+			message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated", name, updated, desired)
+		case available < desired:
+			message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available", name, available, desired)
+		default:
+			complete = true
+			message = fmt.Sprintf("daemon set %q successfully rolled out", name)
+		}
+	}
+
+	if spec["paused"] == true {
+		stuck = append(stuck, "rollout is paused (spec.paused=true)")
+	}
+	data["complete"] = complete
+	data["message"] = message
+	data["stuck"] = len(stuck) > 0
+	data["stuck_reasons"] = stuck
+	data["conditions"] = wait.Conditions(status)
+	return data
+}
+
+func (t *Toolset) rolloutRestartTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_rollout_restart",
+		mcp.WithDescription("Restart a Deployment, StatefulSet or DaemonSet by setting the kubectl.kubernetes.io/restartedAt pod template annotation (rolling restart)"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind: Deployment, StatefulSet, DaemonSet")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Workload name")),
+	)
+}
+
+func (t *Toolset) rolloutRestartHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kindArg, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, resourceType, err := rolloutType(kindArg)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	restartedAt := time.Now().UTC().Format(time.RFC3339)
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						annotationRestartedAt: restartedAt,
+					},
+				},
+			},
+		},
+	}
+	if _, err := t.client.Patch(ctx, cluster, resourceType, namespace, name, patch); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_rollout_restart: %v", err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s %q restarted (restartedAt=%s)", kind, name, restartedAt)), nil
+}
+
+func (t *Toolset) rolloutUndoTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_rollout_undo",
+		mcp.WithDescription("Roll back a Deployment (via ReplicaSet history) or StatefulSet/DaemonSet (via ControllerRevision history) to a previous pod template revision"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind: Deployment, StatefulSet, DaemonSet")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Workload name")),
+		mcp.WithNumber("to_revision", mcp.Description("Revision to roll back to (default: previous revision)")),
+	)
+}
+
+// revision is one entry of a workload's pod template history.
+type revision struct {
+	number   int64
+	source   string // ReplicaSet or ControllerRevision name
+	template map[string]interface{}
+}
+
+func (t *Toolset) rolloutUndoHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kindArg, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	toRevision := int64(req.GetInt("to_revision", 0))
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, resourceType, err := rolloutType(kindArg)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	workload, err := t.client.Get(ctx, cluster, resourceType, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s %q not found: %v", kind, name, err)), nil
+	}
+	var history []revision
+	if kind == "Deployment" {
+		history, err = t.replicaSetHistory(ctx, cluster, workload)
+	} else {
+		history, err = t.controllerRevisionHistory(ctx, cluster, workload)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_rollout_undo: %v", err)), nil
+	}
+	if len(history) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("no rollout history found for %s %q", kind, name)), nil
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].number < history[j].number })
+	current := history[len(history)-1].number
+	if kind == "Deployment" {
+		if v, err := strconv.ParseInt(workload.ObjectMeta.Annotations[annotationDeploymentRevision], 10, 64); err == nil {
+			current = v
+		}
+	}
+
+	var target *revision
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if (toRevision > 0 && h.number == toRevision) || (toRevision <= 0 && h.number < current) {
+			target = &history[i]
+			break
+		}
+	}
+	if target == nil {
+		if toRevision > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("revision %d not found for %s %q", toRevision, kind, name)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("no previous revision found for %s %q (current revision %d)", kind, name, current)), nil
+	}
+	if target.number == current {
+		return mcp.NewToolResultText(fmt.Sprintf("%s %q is already at revision %d; skipped rollback", kind, name, current)), nil
+	}
+
+	// Replace only the pod template, and only if the workload is unchanged since it was read; a PUT of the
+	// decoded object would drop the fields SteveResource does not carry.
+	ops := []map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": workload.ObjectMeta.ResourceVersion},
+		{"op": "replace", "path": "/spec/template", "value": target.template},
+	}
+	patch, _ := json.Marshal(ops)
+	path, err := rancher.ResourcePath(resourceType, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeJSON)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_rollout_undo: %v", err)), nil
+	}
+	if status == http.StatusUnprocessableEntity {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_rollout_undo: patch rejected, %s %q may have changed since it was read; retry: %s", kind, name, strings.TrimSpace(string(body)))), nil
+	}
+	if status < 200 || status >= 300 {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_rollout_undo: patch %d: %s", status, string(body))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s %q rolled back from revision %d to revision %d (%s)", kind, name, current, target.number, target.source)), nil
+}
+
+func (t *Toolset) rolloutPauseTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_rollout_pause",
+		mcp.WithDescription("Pause the rollout of a Deployment (spec.paused=true): template changes are not rolled out until it is resumed"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Deployment name")),
+	)
+}
+
+func (t *Toolset) rolloutResumeTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_rollout_resume",
+		mcp.WithDescription("Resume a paused Deployment rollout (spec.paused=false)"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Deployment name")),
+	)
+}
+
+func (t *Toolset) rolloutPauseHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.setRolloutPaused(ctx, req, true)
+}
+
+func (t *Toolset) rolloutResumeHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.setRolloutPaused(ctx, req, false)
+}
+
+// setRolloutPaused sets spec.paused of a Deployment with a merge patch. Only Deployments can be paused.
+func (t *Toolset) setRolloutPaused(ctx context.Context, req mcp.CallToolRequest, paused bool) (*mcp.CallToolResult, error) {
+	tool, verb := "kubernetes_rollout_resume", "resumed"
+	if paused {
+		tool, verb = "kubernetes_rollout_pause", "paused"
+	}
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	path, err := rancher.ResourcePath(rolloutKinds["deployment"], namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"paused": paused}})
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeMerge)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s: %v", tool, err)), nil
+	}
+	if status == http.StatusNotFound {
+		return mcp.NewToolResultError(fmt.Sprintf("Deployment %q not found in namespace %q", name, namespace)), nil
+	}
+	if status < 200 || status >= 300 {
+		return mcp.NewToolResultError(fmt.Sprintf("%s: patch %d: %s", tool, status, string(body))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Deployment %q %s", name, verb)), nil
+}
+
+// replicaSetHistory returns the pod templates of ReplicaSets owned by a Deployment, keyed by revision.
+func (t *Toolset) replicaSetHistory(ctx context.Context, cluster string, deployment *rancher.SteveResource) ([]revision, error) {
+	col, err := t.client.List(ctx, cluster, "apps.v1.replicasets", rancher.ListOpts{Namespace: deployment.ObjectMeta.Namespace, Limit: 500})
+	if err != nil {
+		return nil, fmt.Errorf("list replicasets: %w", err)
+	}
+	var out []revision
+	for _, rs := range col.Data {
+		if !ownedBy(rs.ObjectMeta, deployment.ObjectMeta) {
+			continue
+		}
+		n, err := strconv.ParseInt(rs.ObjectMeta.Annotations[annotationDeploymentRevision], 10, 64)
+		if err != nil {
+			continue
+		}
+		template := nestedMap(asMap(rs.Spec), "template")
+		if template == nil {
+			continue
+		}
+		// pod-template-hash is added by the Deployment controller and must not be copied back.
+		if labels := nestedMap(template, "metadata", "labels"); labels != nil {
+			delete(labels, "pod-template-hash")
+		}
+		out = append(out, revision{number: n, source: "ReplicaSet/" + rs.ObjectMeta.Name, template: template})
+	}
+	return out, nil
+}
+
+// controllerRevisionHistory returns the pod templates stored in ControllerRevisions owned by a StatefulSet or DaemonSet.
+// ControllerRevision keeps its payload in top-level revision/data fields, so it is read through the raw API.
+func (t *Toolset) controllerRevisionHistory(ctx context.Context, cluster string, workload *rancher.SteveResource) ([]revision, error) {
+	path, err := rancher.ResourcePath("apps.v1.controllerrevisions", workload.ObjectMeta.Namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, url.Values{"limit": {"500"}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("list controllerrevisions %d: %s", status, string(body))
+	}
+	var list struct {
+		Items []struct {
+			Metadata rancher.ObjectMeta     `json:"metadata"`
+			Revision int64                  `json:"revision"`
+			Data     map[string]interface{} `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("decode controllerrevisions: %w", err)
+	}
+	var out []revision
+	for _, cr := range list.Items {
+		if !ownedBy(cr.Metadata, workload.ObjectMeta) {
+			continue
+		}
+		template := nestedMap(cr.Data, "spec", "template")
+		if template == nil {
+			continue
+		}
+		delete(template, "$patch")
+		out = append(out, revision{number: cr.Revision, source: "ControllerRevision/" + cr.Metadata.Name, template: template})
+	}
+	return out, nil
+}
+
+func (t *Toolset) scaleTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_scale",
+		mcp.WithDescription("Scale a workload through the /scale subresource (Deployment, StatefulSet, ReplicaSet, or any resource exposing scale)"),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("api_version", mcp.Description("apiVersion (default: apps/v1)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Deployment, StatefulSet, ReplicaSet)")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Resource name")),
+		mcp.WithNumber("replicas", mcp.Required(), mcp.Description("Desired replica count (>= 0)")),
+	)
+}
+
+func (t *Toolset) scaleHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := t.policy.CheckWrite(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace, err := req.RequireString("namespace")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	replicas, err := req.RequireInt("replicas")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if replicas < 0 {
+		return mcp.NewToolResultError("replicas must be >= 0"), nil
+	}
+	apiVersion := req.GetString("api_version", "apps/v1")
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	path, err := rancher.ResourcePath(rancher.SteveType(apiVersion, kind), namespace, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	path += "/scale"
+	body, status, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_scale: %v", err)), nil
+	}
+	if status != http.StatusOK {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_scale: get scale %d: %s", status, string(body))), nil
+	}
+	var current map[string]interface{}
+	if err := json.Unmarshal(body, &current); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_scale: decode scale: %v", err)), nil
+	}
+	previous := nestedInt(nestedMap(current, "spec"), "replicas")
+
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"replicas": replicas}})
+	body, status, err = t.client.K8sRequest(ctx, cluster, http.MethodPatch, path, nil, patch, rancher.PatchTypeMerge)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_scale: %v", err)), nil
+	}
+	if status < 200 || status >= 300 {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_scale: patch scale %d: %s", status, string(body))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s %q scaled from %d to %d replicas", kind, name, previous, replicas)), nil
+}
+
+// ownedBy reports whether child has an ownerReference pointing at owner (by UID, or by name when UID is missing).
+func ownedBy(child, owner rancher.ObjectMeta) bool {
+	for _, ref := range child.OwnerReferences {
+		if owner.UID != "" && ref.UID == owner.UID {
+			return true
+		}
+		if owner.UID == "" && ref.Name == owner.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// asMap returns v as a map when it is a JSON object.
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// nestedMap walks m by keys and returns the map found there, or nil.
+func nestedMap(m map[string]interface{}, keys ...string) map[string]interface{} {
+	cur := m
+	for _, k := range keys {
+		if cur == nil {
+			return nil
+		}
+		cur, _ = cur[k].(map[string]interface{})
+	}
+	return cur
+}
+
+// nestedInt returns m[key] as int64 (JSON numbers decode as float64); 0 when absent.
+func nestedInt(m map[string]interface{}, key string) int64 {
+	switch v := m[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,272 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+)
+
+const (
+	// managementCluster is the Rancher local cluster where management.cattle.io resources live.
+	managementCluster = "local"
+
+	defaultSearchConcurrency = 8
+	maxSearchConcurrency     = 32
+	defaultSearchTimeout     = 15
+	maxSearchTimeout         = 120
+	defaultSearchLimit       = 50
+)
+
+type searchItem struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Created   string `json:"created,omitempty"`
+}
+
+type searchClusterResult struct {
+	Cluster     string       `json:"cluster"`
+	DisplayName string       `json:"display_name,omitempty"`
+	Count       int          `json:"count"`
+	Truncated   bool         `json:"truncated,omitempty"`
+	Items       []searchItem `json:"items,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	TimedOut    bool         `json:"timed_out,omitempty"`
+}
+
+func (t *Toolset) searchTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_search",
+		mcp.WithDescription("Search for resources across many clusters at once (e.g. \"where is deployment X running\", \"which clusters have namespace Y\"). Lists the given apiVersion/kind on every cluster (or a filtered set) concurrently and returns matches grouped by cluster, with per-cluster errors and timeouts."),
+		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, apps/v1)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Deployment, Namespace)")),
+		mcp.WithString("clusters", mcp.Description("Comma-separated cluster IDs or display names to search (default: all clusters)")),
+		mcp.WithString("cluster_selector", mcp.Description("Label selector on Rancher clusters (management.cattle.io clusters), e.g. env=prod")),
+		mcp.WithString("namespace", mcp.Description("Namespace (empty = all namespaces)")),
+		mcp.WithString("name_regex", mcp.Description("Regular expression matched against resource names (e.g. ^payments-)")),
+		mcp.WithString("label_selector", mcp.Description("Label selector")),
+		mcp.WithString("field_selector", mcp.Description("Field selector (e.g. status.phase=Running)")),
+		mcp.WithNumber("limit", mcp.Description("Max matches returned per cluster (default: 50)")),
+		mcp.WithNumber("concurrency", mcp.Description("Clusters searched in parallel (default: 8, max: 32)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Per-cluster timeout (default: 15, max: 120)")),
+		mcp.WithBoolean("include_empty", mcp.Description("Include clusters with no matches in the output (default: false)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) searchHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	apiVersion, err := req.RequireString("api_version")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var nameRe *regexp.Regexp
+	if expr := req.GetString("name_regex", ""); expr != "" {
+		if nameRe, err = regexp.Compile(expr); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid name_regex: %v", err)), nil
+		}
+	}
+	limit := req.GetInt("limit", defaultSearchLimit)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	concurrency := req.GetInt("concurrency", defaultSearchConcurrency)
+	if concurrency <= 0 {
+		concurrency = defaultSearchConcurrency
+	}
+	concurrency = min(concurrency, maxSearchConcurrency)
+	timeoutSec := req.GetInt("timeout_seconds", defaultSearchTimeout)
+	if timeoutSec <= 0 {
+		timeoutSec = defaultSearchTimeout
+	}
+	timeout := time.Duration(min(timeoutSec, maxSearchTimeout)) * time.Second
+	includeEmpty := req.GetBool("include_empty", false)
+	format := req.GetString("format", "json")
+
+	targets, err := t.searchTargets(ctx, req.GetString("clusters", ""), req.GetString("cluster_selector", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(targets) == 0 {
+		return mcp.NewToolResultError("no clusters match clusters/cluster_selector"), nil
+	}
+
+	resourceType := rancher.SteveType(apiVersion, kind)
+	opts := rancher.ListOpts{
+		Namespace:     namespace,
+		LabelSelector: req.GetString("label_selector", ""),
+		FieldSelector: req.GetString("field_selector", ""),
+	}
+	results := make([]searchClusterResult, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(targets)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = t.searchCluster(ctx, targets[i], resourceType, opts, nameRe, limit, timeout)
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	matched, failed, total := 0, 0, 0
+	out := make([]searchClusterResult, 0, len(results))
+	for _, r := range results {
+		total += r.Count
+		switch {
+		case r.Error != "":
+			failed++
+		case r.Count > 0:
+			matched++
+		case !includeEmpty:
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if (out[i].Error == "") != (out[j].Error == "") {
+			return out[i].Error == ""
+		}
+		return out[i].Cluster < out[j].Cluster
+	})
+
+	if format == formatter.FormatTable {
+		return mcp.NewToolResultText(searchTable(out)), nil
+	}
+	text, err := t.formatter.Format(map[string]interface{}{
+		"kind":              kind,
+		"clusters_searched": len(targets),
+		"clusters_matched":  matched,
+		"clusters_failed":   failed,
+		"total_matches":     total,
+		"results":           out,
+	}, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+type searchTarget struct {
+	id, displayName string
+}
+
+// searchTargets resolves the clusters to search from management.cattle.io clusters, filtered by
+// IDs/display names and a label selector.
+func (t *Toolset) searchTargets(ctx context.Context, clusters, selector string) ([]searchTarget, error) {
+	items, err := t.listAll(ctx, managementCluster, rancher.TypeManagementClusters, rancher.ListOpts{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("list clusters: %w", err)
+	}
+	var wanted []string
+	for _, c := range strings.Split(clusters, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			wanted = append(wanted, c)
+		}
+	}
+	targets := make([]searchTarget, 0, len(items))
+	found := map[string]bool{}
+	for _, c := range items {
+		displayName, _ := asMap(c.Spec)["displayName"].(string)
+		if len(wanted) > 0 {
+			i := slices.IndexFunc(wanted, func(w string) bool { return w == c.ObjectMeta.Name || strings.EqualFold(w, displayName) })
+			if i < 0 {
+				continue
+			}
+			found[wanted[i]] = true
+		}
+		targets = append(targets, searchTarget{id: c.ObjectMeta.Name, displayName: displayName})
+	}
+	for _, w := range wanted {
+		if !found[w] {
+			return nil, fmt.Errorf("cluster %q not found", w)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].id < targets[j].id })
+	return targets, nil
+}
+
+// searchCluster lists resourceType on one cluster under its own timeout and keeps up to limit name matches.
+func (t *Toolset) searchCluster(ctx context.Context, target searchTarget, resourceType string, opts rancher.ListOpts, nameRe *regexp.Regexp, limit int, timeout time.Duration) searchClusterResult {
+	r := searchClusterResult{Cluster: target.id, DisplayName: target.displayName}
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	items, err := t.listAll(cctx, target.id, resourceType, opts)
+	if err != nil {
+		if errors.Is(cctx.Err(), context.DeadlineExceeded) {
+			r.TimedOut = true
+			r.Error = fmt.Sprintf("timed out after %s", timeout)
+		} else {
+			r.Error = err.Error()
+		}
+		return r
+	}
+	for _, item := range items {
+		if nameRe != nil && !nameRe.MatchString(item.ObjectMeta.Name) {
+			continue
+		}
+		// A Namespace is cluster-scoped; the policy applies to the namespace it is.
+		namespace := item.ObjectMeta.Namespace
+		if resourceType == "core.v1.namespaces" {
+			namespace = item.ObjectMeta.Name
+		}
+		if t.policy.CheckNamespace(namespace) != nil {
+			continue
+		}
+		r.Count++
+		if len(r.Items) >= limit {
+			r.Truncated = true
+			continue
+		}
+		r.Items = append(r.Items, searchItem{Name: item.ObjectMeta.Name, Namespace: item.ObjectMeta.Namespace, Created: item.ObjectMeta.CreationTimestamp})
+	}
+	return r
+}
+
+func searchTable(results []searchClusterResult) string {
+	var rows [][]string
+	for _, r := range results {
+		cluster := r.Cluster
+		if r.DisplayName != "" && r.DisplayName != r.Cluster {
+			cluster += " (" + r.DisplayName + ")"
+		}
+		if r.Error != "" {
+			rows = append(rows, []string{cluster, "-", "ERROR: " + r.Error, "-"})
+			continue
+		}
+		if len(r.Items) == 0 {
+			rows = append(rows, []string{cluster, "-", "<none>", "0"})
+		}
+		for _, item := range r.Items {
+			ns := item.Namespace
+			if ns == "" {
+				ns = "-"
+			}
+			rows = append(rows, []string{cluster, ns, item.Name, strconv.Itoa(r.Count)})
+		}
+	}
+	return renderTable([]string{"CLUSTER", "NAMESPACE", "NAME", "MATCHES"}, rows)
+}
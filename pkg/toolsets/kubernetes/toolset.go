@@ -7,7 +7,7 @@ import (
 	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
 )
 
-// Toolset implements the Kubernetes MCP toolset (generic resources, describe, events, capacity).
+// Toolset implements the Kubernetes MCP toolset (generic resources, diff, export, describe, events, related, search, triage, capacity, node report, top, wait, rollouts, bulk operations, exec, probes).
 type Toolset struct {
 	client    *rancher.SteveClient
 	policy    *security.Policy
@@ -27,15 +27,39 @@ func NewToolset(client *rancher.SteveClient, policy *security.Policy) *Toolset {
 func (t *Toolset) Register(s *server.MCPServer) {
 	s.AddTool(t.listTool(), t.listHandler)
 	s.AddTool(t.getTool(), t.getHandler)
+	s.AddTool(t.diffTool(), t.diffHandler)
+	s.AddTool(t.diffManifestTool(), t.diffManifestHandler)
+	s.AddTool(t.exportTool(), t.exportHandler)
 	s.AddTool(t.describeTool(), t.describeHandler)
 	s.AddTool(t.logsTool(), t.logsHandler)
 	s.AddTool(t.eventsTool(), t.eventsHandler)
+	s.AddTool(t.relatedTool(), t.relatedHandler)
+	s.AddTool(t.searchTool(), t.searchHandler)
+	s.AddTool(t.triageTool(), t.triageHandler)
 	s.AddTool(t.capacityTool(), t.capacityHandler)
+	s.AddTool(t.nodeReportTool(), t.nodeReportHandler)
+	s.AddTool(t.topNodesTool(), t.topNodesHandler)
+	s.AddTool(t.topPodsTool(), t.topPodsHandler)
+	s.AddTool(t.waitTool(), t.waitHandler)
+	s.AddTool(t.rolloutStatusTool(), t.rolloutStatusHandler)
+	s.AddTool(t.httpProbeTool(), t.httpProbeHandler)
 	if t.policy.CanWrite() {
 		s.AddTool(t.createTool(), t.createHandler)
 		s.AddTool(t.patchTool(), t.patchHandler)
+		s.AddTool(t.rolloutRestartTool(), t.rolloutRestartHandler)
+		s.AddTool(t.rolloutUndoTool(), t.rolloutUndoHandler)
+		s.AddTool(t.rolloutPauseTool(), t.rolloutPauseHandler)
+		s.AddTool(t.rolloutResumeTool(), t.rolloutResumeHandler)
+		s.AddTool(t.scaleTool(), t.scaleHandler)
+		s.AddTool(t.labelTool(), t.labelHandler)
+		s.AddTool(t.annotateTool(), t.annotateHandler)
+	}
+	if t.policy.CanExec() {
+		s.AddTool(t.execTool(), t.execHandler)
 	}
 	if t.policy.CanDelete() {
 		s.AddTool(t.deleteTool(), t.deleteHandler)
+		s.AddTool(t.deleteCollectionTool(), t.deleteCollectionHandler)
+		s.AddTool(t.removeFinalizersTool(), t.removeFinalizersHandler)
 	}
 }
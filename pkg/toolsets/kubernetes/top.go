@@ -0,0 +1,342 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+)
+
+const (
+	metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+	// nearLimitPercent is the usage/limit ratio at which a container is flagged.
+	nearLimitPercent = 90
+	defaultTopLimit  = 20
+)
+
+// metricsItem is a NodeMetrics or PodMetrics object from metrics.k8s.io.
+type metricsItem struct {
+	Metadata   rancher.ObjectMeta      `json:"metadata"`
+	Timestamp  string                  `json:"timestamp"`
+	Window     string                  `json:"window"`
+	Usage      map[string]interface{}  `json:"usage"`
+	Containers []metricsContainerUsage `json:"containers"`
+}
+
+type metricsContainerUsage struct {
+	Name  string                 `json:"name"`
+	Usage map[string]interface{} `json:"usage"`
+}
+
+// listMetrics reads metrics.k8s.io/v1beta1 nodes or pods; a missing metrics API returns a descriptive error.
+func (t *Toolset) listMetrics(ctx context.Context, cluster, namespace, resource, labelSelector string) ([]metricsItem, error) {
+	apiPath := metricsAPIPath
+	if namespace != "" {
+		apiPath += "/namespaces/" + namespace
+	}
+	apiPath += "/" + resource
+	var query url.Values
+	if labelSelector != "" {
+		query = url.Values{"labelSelector": {labelSelector}}
+	}
+	body, code, err := t.client.K8sRequest(ctx, cluster, http.MethodGet, apiPath, query, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code == http.StatusNotFound || code == http.StatusServiceUnavailable:
+		return nil, fmt.Errorf("metrics API (metrics.k8s.io/v1beta1) is not available in cluster %s (HTTP %d); install metrics-server to use top tools", cluster, code)
+	case code < 200 || code >= 300:
+		return nil, fmt.Errorf("metrics API returned %d: %s", code, strings.TrimSpace(string(body)))
+	}
+	var list struct {
+		Items []metricsItem `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("decode metrics: %w", err)
+	}
+	return list.Items, nil
+}
+
+func topSortBy(req mcp.CallToolRequest) (string, error) {
+	sortBy := strings.ToLower(req.GetString("sort_by", "cpu"))
+	if sortBy != "cpu" && sortBy != "memory" {
+		return "", fmt.Errorf("unsupported sort_by %q; use cpu or memory", sortBy)
+	}
+	return sortBy, nil
+}
+
+func (t *Toolset) topNodesTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_top_nodes",
+		mcp.WithDescription("Live CPU/memory usage per node from metrics-server (metrics.k8s.io), with percentage of allocatable. Like kubectl top nodes."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("label_selector", mcp.Description("Node label selector (optional)")),
+		mcp.WithString("sort_by", mcp.Description("Sort by: cpu, memory (default: cpu)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+type topNode struct {
+	Name          string `json:"name"`
+	CPU           string `json:"cpu"`
+	CPUPercent    int64  `json:"cpu_percent"`
+	Memory        string `json:"memory"`
+	MemoryPercent int64  `json:"memory_percent"`
+	cpuMilli      int64
+	memoryBytes   int64
+}
+
+func (t *Toolset) topNodesHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sortBy, err := topSortBy(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	format := req.GetString("format", "json")
+	labelSelector := req.GetString("label_selector", "")
+
+	metrics, err := t.listMetrics(ctx, cluster, "", "nodes", labelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	nodes, err := t.listAll(ctx, cluster, rancher.TypeNodes, rancher.ListOpts{LabelSelector: labelSelector})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list nodes: %v", err)), nil
+	}
+	allocatable := make(map[string]resourceTotals, len(nodes))
+	for _, n := range nodes {
+		allocatable[n.ObjectMeta.Name] = resourceList(asMap(asMap(n.Status)["allocatable"]))
+	}
+
+	rows := make([]topNode, 0, len(metrics))
+	for _, m := range metrics {
+		usage := resourceList(m.Usage)
+		alloc := allocatable[m.Metadata.Name]
+		rows = append(rows, topNode{
+			Name:          m.Metadata.Name,
+			CPU:           formatCPU(usage.CPUMilli),
+			CPUPercent:    percent(usage.CPUMilli, alloc.CPUMilli),
+			Memory:        formatMemory(usage.MemoryBytes),
+			MemoryPercent: percent(usage.MemoryBytes, alloc.MemoryBytes),
+			cpuMilli:      usage.CPUMilli,
+			memoryBytes:   usage.MemoryBytes,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if sortBy == "memory" {
+			return rows[i].memoryBytes > rows[j].memoryBytes
+		}
+		return rows[i].cpuMilli > rows[j].cpuMilli
+	})
+
+	if format == formatter.FormatTable {
+		table := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			table = append(table, []string{r.Name, r.CPU, fmt.Sprintf("%d%%", r.CPUPercent), r.Memory, fmt.Sprintf("%d%%", r.MemoryPercent)})
+		}
+		return mcp.NewToolResultText(renderTable([]string{"NAME", "CPU", "CPU%", "MEMORY", "MEMORY%"}, table)), nil
+	}
+	out, err := t.formatter.Format(rows, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+func (t *Toolset) topPodsTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_top_pods",
+		mcp.WithDescription("Live CPU/memory usage per pod from metrics-server (metrics.k8s.io), joined with requests/limits from pod specs. Flags containers near their memory limit (OOM risk) or CPU limit (likely throttled)."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Description("Namespace (optional; empty = all allowed namespaces)")),
+		mcp.WithString("label_selector", mcp.Description("Pod label selector (optional)")),
+		mcp.WithString("sort_by", mcp.Description("Sort by: cpu, memory (default: cpu)")),
+		mcp.WithNumber("limit", mcp.Description("Return the top N pods (default: 20)")),
+		mcp.WithBoolean("flagged_only", mcp.Description("Only return pods with containers near their limits (default: false)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+type topContainer struct {
+	Name          string   `json:"name"`
+	CPU           string   `json:"cpu"`
+	CPURequest    string   `json:"cpu_request,omitempty"`
+	CPULimit      string   `json:"cpu_limit,omitempty"`
+	Memory        string   `json:"memory"`
+	MemoryRequest string   `json:"memory_request,omitempty"`
+	MemoryLimit   string   `json:"memory_limit,omitempty"`
+	Flags         []string `json:"flags,omitempty"`
+}
+
+type topPod struct {
+	Namespace      string         `json:"namespace"`
+	Name           string         `json:"name"`
+	Node           string         `json:"node,omitempty"`
+	CPU            string         `json:"cpu"`
+	CPULimitPct    int64          `json:"cpu_limit_percent,omitempty"`
+	Memory         string         `json:"memory"`
+	MemoryLimitPct int64          `json:"memory_limit_percent,omitempty"`
+	Containers     []topContainer `json:"containers"`
+	Flags          []string       `json:"flags,omitempty"`
+	cpuMilli       int64
+	memoryBytes    int64
+}
+
+func (t *Toolset) topPodsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sortBy, err := topSortBy(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	labelSelector := req.GetString("label_selector", "")
+	limit := req.GetInt("limit", defaultTopLimit)
+	if limit <= 0 {
+		limit = defaultTopLimit
+	}
+	flaggedOnly := req.GetBool("flagged_only", false)
+	format := req.GetString("format", "json")
+
+	metrics, err := t.listMetrics(ctx, cluster, namespace, "pods", labelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pods, err := t.listAll(ctx, cluster, "core.v1.pods", rancher.ListOpts{Namespace: namespace, LabelSelector: labelSelector})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("list pods: %v", err)), nil
+	}
+	specs := make(map[string]map[string]interface{}, len(pods))
+	for _, p := range pods {
+		specs[p.ObjectMeta.Namespace+"/"+p.ObjectMeta.Name] = asMap(p.Spec)
+	}
+
+	rows := make([]topPod, 0, len(metrics))
+	for _, m := range metrics {
+		if t.policy.CheckNamespace(m.Metadata.Namespace) != nil {
+			continue
+		}
+		row := buildTopPod(m, specs[m.Metadata.Namespace+"/"+m.Metadata.Name])
+		if flaggedOnly && len(row.Flags) == 0 {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if sortBy == "memory" {
+			return rows[i].memoryBytes > rows[j].memoryBytes
+		}
+		return rows[i].cpuMilli > rows[j].cpuMilli
+	})
+	total := len(rows)
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	if format == formatter.FormatTable {
+		table := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			flags := strings.Join(r.Flags, "; ")
+			if flags == "" {
+				flags = "-"
+			}
+			table = append(table, []string{r.Namespace, r.Name, r.CPU, limitPct(r.CPULimitPct), r.Memory, limitPct(r.MemoryLimitPct), flags})
+		}
+		return mcp.NewToolResultText(renderTable([]string{"NAMESPACE", "NAME", "CPU", "CPU/LIMIT", "MEMORY", "MEM/LIMIT", "FLAGS"}, table)), nil
+	}
+	out, err := t.formatter.Format(map[string]interface{}{
+		"total": total,
+		"pods":  rows,
+	}, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// buildTopPod joins pod metrics with container requests/limits from the pod spec (nil when the pod is gone).
+func buildTopPod(m metricsItem, spec map[string]interface{}) topPod {
+	resources := map[string]map[string]interface{}{}
+	containers, _ := spec["containers"].([]interface{})
+	for _, c := range containers {
+		cm := asMap(c)
+		if name, _ := cm["name"].(string); name != "" {
+			resources[name] = asMap(cm["resources"])
+		}
+	}
+	row := topPod{Namespace: m.Metadata.Namespace, Name: m.Metadata.Name}
+	row.Node, _ = spec["nodeName"].(string)
+
+	var usage, limits resourceTotals
+	cpuLimited, memLimited := true, true
+	for _, c := range m.Containers {
+		u := resourceList(c.Usage)
+		usage.add(u)
+		res := resources[c.Name]
+		req := resourceList(asMap(res["requests"]))
+		lim := resourceList(asMap(res["limits"]))
+		limits.add(lim)
+		cpuLimited = cpuLimited && lim.CPUMilli > 0
+		memLimited = memLimited && lim.MemoryBytes > 0
+		tc := topContainer{Name: c.Name, CPU: formatCPU(u.CPUMilli), Memory: formatMemory(u.MemoryBytes)}
+		if req.CPUMilli > 0 {
+			tc.CPURequest = formatCPU(req.CPUMilli)
+		}
+		if req.MemoryBytes > 0 {
+			tc.MemoryRequest = formatMemory(req.MemoryBytes)
+		}
+		if lim.CPUMilli > 0 {
+			tc.CPULimit = formatCPU(lim.CPUMilli)
+			if pct := percent(u.CPUMilli, lim.CPUMilli); pct >= nearLimitPercent {
+				tc.Flags = append(tc.Flags, fmt.Sprintf("cpu at %d%% of limit (likely throttled)", pct))
+			}
+		}
+		if lim.MemoryBytes > 0 {
+			tc.MemoryLimit = formatMemory(lim.MemoryBytes)
+			if pct := percent(u.MemoryBytes, lim.MemoryBytes); pct >= nearLimitPercent {
+				tc.Flags = append(tc.Flags, fmt.Sprintf("memory at %d%% of limit (OOM risk)", pct))
+			}
+		}
+		if req.MemoryBytes > 0 && u.MemoryBytes > req.MemoryBytes && lim.MemoryBytes == 0 {
+			tc.Flags = append(tc.Flags, "memory above request with no limit")
+		}
+		for _, f := range tc.Flags {
+			row.Flags = append(row.Flags, c.Name+": "+f)
+		}
+		row.Containers = append(row.Containers, tc)
+	}
+	row.CPU = formatCPU(usage.CPUMilli)
+	row.Memory = formatMemory(usage.MemoryBytes)
+	row.cpuMilli = usage.CPUMilli
+	row.memoryBytes = usage.MemoryBytes
+	if cpuLimited && len(m.Containers) > 0 {
+		row.CPULimitPct = percent(usage.CPUMilli, limits.CPUMilli)
+	}
+	if memLimited && len(m.Containers) > 0 {
+		row.MemoryLimitPct = percent(usage.MemoryBytes, limits.MemoryBytes)
+	}
+	return row
+}
+
+func limitPct(pct int64) string {
+	if pct == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", pct)
+}
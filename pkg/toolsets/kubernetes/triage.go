@@ -0,0 +1,365 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/formatter"
+)
+
+const (
+	defaultTriageSinceMinutes = 60
+	defaultTriageMaxObjects   = 10
+)
+
+// Triage severities, most urgent first.
+const (
+	severityCritical = "critical"
+	severityHigh     = "high"
+	severityMedium   = "medium"
+	severityLow      = "low"
+)
+
+var severityRank = map[string]int{severityCritical: 0, severityHigh: 1, severityMedium: 2, severityLow: 3}
+
+// triageCause describes a root cause category found by kubernetes_triage.
+type triageCause struct {
+	severity string
+	hint     string
+}
+
+var triageCauses = map[string]triageCause{
+	"CrashLoopBackOff":           {severityCritical, "Container keeps exiting; check previous container logs and the last termination reason/exit code."},
+	"OOMKilled":                  {severityCritical, "Container exceeded its memory limit; compare usage (kubernetes_top_pods) with limits and raise the limit or fix the leak."},
+	"DeploymentUnavailable":      {severityCritical, "Deployment has no available replicas; inspect its pods and rollout status."},
+	"ImagePullBackOff":           {severityHigh, "Image cannot be pulled; verify image name/tag, registry reachability and imagePullSecrets."},
+	"CreateContainerConfigError": {severityHigh, "Container config is invalid, usually a missing ConfigMap/Secret or key referenced by env/volumes."},
+	"Unschedulable":              {severityHigh, "Scheduler cannot place the pod; check requests vs node capacity (kubernetes_node_report), taints/tolerations, affinity and PVC binding."},
+	"PVCUnbound":                 {severityHigh, "PersistentVolumeClaim is not bound; check the StorageClass, provisioner and volume events."},
+	"JobFailed":                  {severityMedium, "Job exhausted its backoff limit or deadline; check logs of its failed pods."},
+	"DeploymentDegraded":         {severityMedium, "Deployment has fewer available replicas than desired or its rollout is stuck."},
+	"PodFailed":                  {severityMedium, "Pod terminated in Failed phase (e.g. Evicted); check the reason and node conditions."},
+	"PreviouslyOOMKilled":        {severityLow, "Container was OOMKilled before the triage window and is ready again; check whether its memory usage is still close to the limit."},
+	"WarningEvents":              {severityLow, "Recent Warning events not explained by the issues above."},
+}
+
+type triageObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Detail    string `json:"detail,omitempty"`
+	Next      string `json:"next,omitempty"`
+}
+
+type triageIssue struct {
+	Cause    string         `json:"cause"`
+	Severity string         `json:"severity"`
+	Count    int            `json:"count"`
+	Hint     string         `json:"hint"`
+	Objects  []triageObject `json:"objects"`
+}
+
+// triageReport collects issues by cause while scanning.
+type triageReport struct {
+	issues map[string]*triageIssue
+	seen   map[string]bool // kind/namespace/name of objects already reported
+}
+
+func (r *triageReport) add(cause string, obj triageObject) {
+	key := obj.Kind + "/" + obj.Namespace + "/" + obj.Name
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+	issue := r.issues[cause]
+	if issue == nil {
+		c := triageCauses[cause]
+		issue = &triageIssue{Cause: cause, Severity: c.severity, Hint: c.hint}
+		r.issues[cause] = issue
+	}
+	issue.Count++
+	issue.Objects = append(issue.Objects, obj)
+}
+
+func (t *Toolset) triageTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_triage",
+		mcp.WithDescription("Scan a cluster or namespace for broken workloads: CrashLoopBackOff, ImagePullBackOff, OOMKilled, unschedulable pods, failed Jobs, unavailable Deployments, unbound PVCs and recent Warning events. Groups findings by root cause and returns a prioritized summary with objects to inspect next."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("namespace", mcp.Description("Namespace (optional; empty = all allowed namespaces)")),
+		mcp.WithNumber("since_minutes", mcp.Description("Window for Warning events and OOM kills of containers that are ready again, in minutes (default: 60)")),
+		mcp.WithNumber("max_objects", mcp.Description("Max objects listed per cause (default: 10)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) triageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sinceMinutes := req.GetInt("since_minutes", defaultTriageSinceMinutes)
+	if sinceMinutes <= 0 {
+		sinceMinutes = defaultTriageSinceMinutes
+	}
+	maxObjects := req.GetInt("max_objects", defaultTriageMaxObjects)
+	if maxObjects <= 0 {
+		maxObjects = defaultTriageMaxObjects
+	}
+	format := req.GetString("format", "json")
+
+	report := &triageReport{issues: map[string]*triageIssue{}, seen: map[string]bool{}}
+	scanned := map[string]int{}
+	var scanErrors []string
+	list := func(label, resourceType string) []rancher.SteveResource {
+		items, err := t.listAll(ctx, cluster, resourceType, rancher.ListOpts{Namespace: namespace})
+		if err != nil {
+			scanErrors = append(scanErrors, fmt.Sprintf("%s: %v", label, err))
+			return nil
+		}
+		allowed := items[:0]
+		for _, item := range items {
+			if t.policy.CheckNamespace(item.ObjectMeta.Namespace) == nil {
+				allowed = append(allowed, item)
+			}
+		}
+		scanned[label] = len(allowed)
+		return allowed
+	}
+
+	cutoff := time.Now().Add(-time.Duration(sinceMinutes) * time.Minute)
+	for _, pod := range list("pods", "core.v1.pods") {
+		triagePod(report, pod, cutoff)
+	}
+	for _, d := range list("deployments", "apps.v1.deployments") {
+		triageDeployment(report, d)
+	}
+	for _, job := range list("jobs", "batch.v1.jobs") {
+		triageJob(report, job)
+	}
+	for _, pvc := range list("pvcs", "core.v1.persistentvolumeclaims") {
+		triagePVC(report, pvc)
+	}
+
+	events, _, err := t.fetchEvents(ctx, cluster, namespace, eventsAPIAuto, eventFilter{Type: "Warning"}, nil)
+	if err != nil {
+		scanErrors = append(scanErrors, fmt.Sprintf("events: %v", err))
+	}
+	warnings := 0
+	for _, e := range events {
+		if e.Type != "Warning" || e.lastSeen().Before(cutoff) || t.policy.CheckNamespace(e.InvolvedObject.Namespace) != nil {
+			continue
+		}
+		warnings++
+		obj := e.InvolvedObject
+		report.add("WarningEvents", triageObject{
+			Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name,
+			Detail: fmt.Sprintf("%s: %s", e.Reason, e.Message),
+			Next:   fmt.Sprintf("kubernetes_describe kind=%s namespace=%s name=%s", obj.Kind, obj.Namespace, obj.Name),
+		})
+	}
+	scanned["warning_events"] = warnings
+
+	issues := make([]*triageIssue, 0, len(report.issues))
+	affected := 0
+	for _, issue := range report.issues {
+		affected += issue.Count
+		if len(issue.Objects) > maxObjects {
+			issue.Objects = issue.Objects[:maxObjects]
+		}
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if ri, rj := severityRank[issues[i].Severity], severityRank[issues[j].Severity]; ri != rj {
+			return ri < rj
+		}
+		if issues[i].Count != issues[j].Count {
+			return issues[i].Count > issues[j].Count
+		}
+		return issues[i].Cause < issues[j].Cause
+	})
+
+	if format == formatter.FormatTable {
+		rows := make([][]string, 0, len(issues))
+		for _, issue := range issues {
+			names := make([]string, 0, len(issue.Objects))
+			for _, o := range issue.Objects {
+				names = append(names, strings.Trim(o.Namespace+"/"+o.Name, "/"))
+			}
+			rows = append(rows, []string{issue.Severity, issue.Cause, fmt.Sprint(issue.Count), strings.Join(names, ", ")})
+		}
+		if len(rows) == 0 {
+			return mcp.NewToolResultText("No issues found."), nil
+		}
+		return mcp.NewToolResultText(renderTable([]string{"SEVERITY", "CAUSE", "COUNT", "OBJECTS"}, rows)), nil
+	}
+	summary := "No issues found."
+	if len(issues) > 0 {
+		summary = fmt.Sprintf("%d affected objects across %d causes; most urgent: %s (%s)", affected, len(issues), issues[0].Cause, issues[0].Severity)
+	}
+	data := map[string]interface{}{
+		"cluster": cluster,
+		"summary": summary,
+		"scanned": scanned,
+		"issues":  issues,
+	}
+	if namespace != "" {
+		data["namespace"] = namespace
+	}
+	if len(scanErrors) > 0 {
+		data["errors"] = scanErrors
+	}
+	out, err := t.formatter.Format(data, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
+
+// triagePod classifies a pod by its most specific failure: OOMKilled and image/config errors win over
+// CrashLoopBackOff, which wins over scheduling and phase. A container that was OOMKilled before cutoff and
+// is ready again is only reported as PreviouslyOOMKilled.
+func triagePod(r *triageReport, pod rancher.SteveResource, cutoff time.Time) {
+	status := asMap(pod.Status)
+	ns, name := pod.ObjectMeta.Namespace, pod.ObjectMeta.Name
+	obj := func(detail, next string) triageObject {
+		return triageObject{Kind: "Pod", Namespace: ns, Name: name, Detail: detail, Next: next}
+	}
+	var statuses []interface{}
+	for _, key := range []string{"initContainerStatuses", "containerStatuses"} {
+		s, _ := status[key].([]interface{})
+		statuses = append(statuses, s...)
+	}
+	var crashLoop, previousOOM *triageObject
+	for _, s := range statuses {
+		cs := asMap(s)
+		container, _ := cs["name"].(string)
+		waiting := nestedMap(cs, "state", "waiting")
+		waitingReason, _ := waiting["reason"].(string)
+		waitingMsg, _ := waiting["message"].(string)
+		lastTerminated := nestedMap(cs, "lastState", "terminated")
+		lastReason, _ := lastTerminated["reason"].(string)
+		termReason, _ := nestedMap(cs, "state", "terminated")["reason"].(string)
+		logs := fmt.Sprintf("kubernetes_logs namespace=%s pod=%s container=%s", ns, name, container)
+		ready, _ := cs["ready"].(bool)
+		finishedAt, _ := lastTerminated["finishedAt"].(string)
+		finished, _ := time.Parse(time.RFC3339, finishedAt)
+		switch {
+		case termReason == "OOMKilled" || (lastReason == "OOMKilled" && (!ready || finished.After(cutoff))):
+			r.add("OOMKilled", obj(fmt.Sprintf("container %s OOMKilled (restarts: %v)", container, cs["restartCount"]), "kubernetes_top_pods namespace="+ns))
+			return
+		case lastReason == "OOMKilled" && previousOOM == nil:
+			o := obj(fmt.Sprintf("container %s OOMKilled at %s, ready since (restarts: %v)", container, finishedAt, cs["restartCount"]), "kubernetes_top_pods namespace="+ns)
+			previousOOM = &o
+		case waitingReason == "ImagePullBackOff" || waitingReason == "ErrImagePull" || waitingReason == "InvalidImageName":
+			r.add("ImagePullBackOff", obj(fmt.Sprintf("container %s: %s %s", container, waitingReason, waitingMsg), fmt.Sprintf("kubernetes_describe kind=Pod namespace=%s name=%s", ns, name)))
+			return
+		case waitingReason == "CreateContainerConfigError" || waitingReason == "CreateContainerError":
+			r.add("CreateContainerConfigError", obj(fmt.Sprintf("container %s: %s", container, waitingMsg), fmt.Sprintf("kubernetes_describe kind=Pod namespace=%s name=%s", ns, name)))
+			return
+		case waitingReason == "CrashLoopBackOff" && crashLoop == nil:
+			detail := fmt.Sprintf("container %s restarting (restarts: %v", container, cs["restartCount"])
+			if lastReason != "" {
+				detail += ", last exit: " + lastReason
+			}
+			o := obj(detail+")", logs+" (check previous logs)")
+			crashLoop = &o
+		}
+	}
+	if crashLoop != nil {
+		r.add("CrashLoopBackOff", *crashLoop)
+		return
+	}
+	if previousOOM != nil {
+		r.add("PreviouslyOOMKilled", *previousOOM)
+		return
+	}
+	phase, _ := status["phase"].(string)
+	conditions, _ := status["conditions"].([]interface{})
+	for _, c := range conditions {
+		cm := asMap(c)
+		if cm["type"] == "PodScheduled" && cm["status"] == "False" && cm["reason"] == "Unschedulable" {
+			msg, _ := cm["message"].(string)
+			r.add("Unschedulable", obj(msg, "kubernetes_node_report"))
+			return
+		}
+	}
+	if phase == "Failed" {
+		reason, _ := status["reason"].(string)
+		msg, _ := status["message"].(string)
+		r.add("PodFailed", obj(strings.TrimSpace(reason+": "+msg), fmt.Sprintf("kubernetes_describe kind=Pod namespace=%s name=%s", ns, name)))
+	}
+}
+
+func triageDeployment(r *triageReport, d rancher.SteveResource) {
+	spec, status := asMap(d.Spec), asMap(d.Status)
+	desired := int64(1)
+	if _, ok := spec["replicas"]; ok {
+		desired = nestedInt(spec, "replicas")
+	}
+	if desired == 0 {
+		return
+	}
+	available := nestedInt(status, "availableReplicas")
+	var stuck string
+	conditions, _ := status["conditions"].([]interface{})
+	for _, c := range conditions {
+		cm := asMap(c)
+		if cm["type"] == "Progressing" && cm["reason"] == "ProgressDeadlineExceeded" {
+			stuck, _ = cm["message"].(string)
+		}
+	}
+	if available >= desired && stuck == "" {
+		return
+	}
+	detail := fmt.Sprintf("%d/%d available", available, desired)
+	if stuck != "" {
+		detail += "; " + stuck
+	}
+	cause := "DeploymentDegraded"
+	if available == 0 {
+		cause = "DeploymentUnavailable"
+	}
+	r.add(cause, triageObject{
+		Kind: "Deployment", Namespace: d.ObjectMeta.Namespace, Name: d.ObjectMeta.Name, Detail: detail,
+		Next: fmt.Sprintf("kubernetes_rollout_status kind=Deployment namespace=%s name=%s", d.ObjectMeta.Namespace, d.ObjectMeta.Name),
+	})
+}
+
+func triageJob(r *triageReport, job rancher.SteveResource) {
+	conditions, _ := asMap(job.Status)["conditions"].([]interface{})
+	for _, c := range conditions {
+		cm := asMap(c)
+		if cm["type"] == "Failed" && cm["status"] == "True" {
+			reason, _ := cm["reason"].(string)
+			msg, _ := cm["message"].(string)
+			r.add("JobFailed", triageObject{
+				Kind: "Job", Namespace: job.ObjectMeta.Namespace, Name: job.ObjectMeta.Name, Detail: strings.TrimSpace(reason + ": " + msg),
+				Next: fmt.Sprintf("kubernetes_list kind=Pod namespace=%s label_selector=job-name=%s", job.ObjectMeta.Namespace, job.ObjectMeta.Name),
+			})
+			return
+		}
+	}
+}
+
+func triagePVC(r *triageReport, pvc rancher.SteveResource) {
+	phase, _ := asMap(pvc.Status)["phase"].(string)
+	if phase != "Pending" && phase != "Lost" {
+		return
+	}
+	storageClass, _ := asMap(pvc.Spec)["storageClassName"].(string)
+	r.add("PVCUnbound", triageObject{
+		Kind: "PersistentVolumeClaim", Namespace: pvc.ObjectMeta.Namespace, Name: pvc.ObjectMeta.Name,
+		Detail: fmt.Sprintf("phase %s, storageClass %q", phase, storageClass),
+		Next:   fmt.Sprintf("kubernetes_events namespace=%s involved_object_name=%s", pvc.ObjectMeta.Namespace, pvc.ObjectMeta.Name),
+	})
+}
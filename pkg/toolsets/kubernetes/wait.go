@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"github.com/mrostamii/rancher-mcp-server/pkg/wait"
+)
+
+const (
+	defaultWaitTimeoutSeconds = 120
+	maxWaitTimeoutSeconds     = 1800
+)
+
+func (t *Toolset) waitTool() mcp.Tool {
+	return mcp.NewTool(
+		"kubernetes_wait",
+		mcp.WithDescription("Wait for a Kubernetes resource to reach a condition (like kubectl wait). Uses the watch API through the Rancher proxy and sends MCP progress notifications while waiting."),
+		mcp.WithString("cluster", mcp.Required(), mcp.Description("Cluster ID")),
+		mcp.WithString("api_version", mcp.Required(), mcp.Description("apiVersion (e.g. v1, apps/v1, kubevirt.io/v1)")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("Kind (e.g. Pod, Deployment, VirtualMachine)")),
+		mcp.WithString("namespace", mcp.Description("Namespace (for namespaced resources)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Resource name")),
+		mcp.WithString("for", mcp.Required(), mcp.Description("Condition: delete, exists, condition=<Type>[=<Status>] (e.g. condition=Ready), or jsonpath={<path>}[=<value>] (e.g. jsonpath={.status.phase}=Running)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Max seconds to wait (default: 120, max: 1800)")),
+		mcp.WithString("format", mcp.Description("Output format: json, table (default: json)")),
+	)
+}
+
+func (t *Toolset) waitHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := req.RequireString("cluster")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	apiVersion, err := req.RequireString("api_version")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind, err := req.RequireString("kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	forStr, err := req.RequireString("for")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	namespace := req.GetString("namespace", "")
+	format := req.GetString("format", "json")
+	timeoutSeconds := req.GetInt("timeout_seconds", defaultWaitTimeoutSeconds)
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultWaitTimeoutSeconds
+	}
+	if timeoutSeconds > maxWaitTimeoutSeconds {
+		timeoutSeconds = maxWaitTimeoutSeconds
+	}
+
+	if err := t.policy.CheckNamespace(namespace); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cond, err := wait.ParseCondition(forStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	target := wait.Target{
+		Cluster:      cluster,
+		ResourceType: rancher.SteveType(apiVersion, kind),
+		Namespace:    namespace,
+		Name:         name,
+	}
+	res, err := wait.Until(ctx, t.client, target, cond, timeout, wait.Progress(ctx, req, timeout))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("kubernetes_wait %s %q: %v", kind, name, err)), nil
+	}
+	data := map[string]interface{}{
+		"kind":            kind,
+		"name":            name,
+		"namespace":       namespace,
+		"condition":       cond.String(),
+		"met":             res.Met,
+		"observed":        res.Observed,
+		"elapsed_seconds": int(res.Elapsed.Seconds()),
+	}
+	out, err := t.formatter.Format(data, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("format: %v", err)), nil
+	}
+	return mcp.NewToolResultText(out), nil
+}
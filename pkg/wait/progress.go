@@ -0,0 +1,30 @@
+package wait
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Progress returns a ProgressFunc that sends MCP notifications/progress for req, with progress and
+// total expressed in seconds. It is a no-op when the client did not send a progressToken.
+func Progress(ctx context.Context, req mcp.CallToolRequest, timeout time.Duration) ProgressFunc {
+	if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+	token := req.Params.Meta.ProgressToken
+	return func(elapsed time.Duration, observed string) {
+		_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      elapsed.Seconds(),
+			"total":         timeout.Seconds(),
+			"message":       observed,
+		})
+	}
+}
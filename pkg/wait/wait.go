@@ -0,0 +1,295 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Condition kinds, mirroring kubectl wait --for.
+const (
+	KindDelete    = "delete"
+	KindExists    = "exists"
+	KindCondition = "condition"
+	KindJSONPath  = "jsonpath"
+)
+
+// pollInterval is used when the watch API is unavailable through the proxy.
+const pollInterval = 2 * time.Second
+
+// Condition describes what to wait for on a single object.
+type Condition struct {
+	Kind  string
+	Type  string // status.conditions[].type for KindCondition
+	Path  string // JSONPath template for KindJSONPath, e.g. {.status.phase}
+	Value string // Expected value; empty for KindJSONPath means "any non-empty value"
+}
+
+// ParseCondition parses a kubectl-style wait condition:
+// "delete", "exists", "condition=Ready", "condition=Ready=False", "jsonpath={.status.phase}=Running".
+func ParseCondition(s string) (Condition, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "delete", "deleted":
+		return Condition{Kind: KindDelete}, nil
+	case "exists", "create", "created":
+		return Condition{Kind: KindExists}, nil
+	}
+	if rest, ok := cutPrefixFold(s, "condition="); ok {
+		typ, value, found := strings.Cut(rest, "=")
+		if typ == "" {
+			return Condition{}, fmt.Errorf("condition requires a type, e.g. condition=Ready")
+		}
+		if !found || value == "" {
+			value = "True"
+		}
+		return Condition{Kind: KindCondition, Type: typ, Value: value}, nil
+	}
+	if rest, ok := cutPrefixFold(s, "jsonpath="); ok {
+		rest = strings.NewReplacer("'", "", "\"", "").Replace(rest)
+		var path, value string
+		if strings.HasPrefix(rest, "{") {
+			end := strings.Index(rest, "}")
+			if end < 0 {
+				return Condition{}, fmt.Errorf("unterminated JSONPath expression %q", rest)
+			}
+			path = rest[:end+1]
+			value = strings.TrimPrefix(rest[end+1:], "=")
+		} else {
+			// Allow the short form ".status.phase=Running"
+			p, v, _ := strings.Cut(rest, "=")
+			path = "{" + p + "}"
+			value = v
+		}
+		if _, err := newJSONPath(path); err != nil {
+			return Condition{}, err
+		}
+		return Condition{Kind: KindJSONPath, Path: path, Value: value}, nil
+	}
+	return Condition{}, fmt.Errorf("invalid condition %q; use delete, exists, condition=<Type>[=<Status>], or jsonpath={<path>}[=<value>]", s)
+}
+
+// String returns the condition in kubectl --for form.
+func (c Condition) String() string {
+	switch c.Kind {
+	case KindCondition:
+		return fmt.Sprintf("condition=%s=%s", c.Type, c.Value)
+	case KindJSONPath:
+		if c.Value == "" {
+			return "jsonpath=" + c.Path
+		}
+		return fmt.Sprintf("jsonpath=%s=%s", c.Path, c.Value)
+	default:
+		return c.Kind
+	}
+}
+
+// Evaluate reports whether obj satisfies the condition and a short description of what was observed.
+// obj is nil when the object does not exist.
+func (c Condition) Evaluate(obj *rancher.SteveResource) (bool, string) {
+	if obj == nil {
+		return c.Kind == KindDelete, "not found"
+	}
+	switch c.Kind {
+	case KindDelete:
+		return false, "exists"
+	case KindExists:
+		return true, "exists"
+	case KindCondition:
+		for _, cond := range Conditions(obj.Status) {
+			t, _ := cond["type"].(string)
+			if !strings.EqualFold(t, c.Type) {
+				continue
+			}
+			status, _ := cond["status"].(string)
+			observed := fmt.Sprintf("%s=%s", t, status)
+			if reason, _ := cond["reason"].(string); reason != "" {
+				observed += " (" + reason + ")"
+			}
+			return strings.EqualFold(status, c.Value), observed
+		}
+		return false, fmt.Sprintf("condition %s not present", c.Type)
+	case KindJSONPath:
+		got, err := evalJSONPath(c.Path, obj)
+		if err != nil {
+			return false, err.Error()
+		}
+		if c.Value == "" {
+			return got != "", got
+		}
+		return got == c.Value, got
+	}
+	return false, ""
+}
+
+// Conditions returns status.conditions as a slice of maps (empty when absent).
+func Conditions(status interface{}) []map[string]interface{} {
+	m, ok := status.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	list, _ := m["conditions"].([]interface{})
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, c := range list {
+		if cm, ok := c.(map[string]interface{}); ok {
+			out = append(out, cm)
+		}
+	}
+	return out
+}
+
+// Target identifies the object to wait on.
+type Target struct {
+	Cluster      string
+	ResourceType string
+	Namespace    string
+	Name         string
+}
+
+// Result is the outcome of Until.
+type Result struct {
+	Met      bool
+	Observed string
+	Elapsed  time.Duration
+	Object   *rancher.SteveResource
+}
+
+// ProgressFunc is called whenever a new object state is observed while waiting.
+type ProgressFunc func(elapsed time.Duration, observed string)
+
+// Until waits until cond holds for target or timeout expires. It evaluates the current object first,
+// then follows changes with the Kubernetes watch API through the Rancher proxy, falling back to
+// polling when the watch cannot be established. On timeout it returns the last observation and an error.
+func Until(ctx context.Context, client *rancher.SteveClient, target Target, cond Condition, timeout time.Duration, progress ProgressFunc) (*Result, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res := &Result{}
+	observe := func(obj *rancher.SteveResource) bool {
+		met, observed := cond.Evaluate(obj)
+		res.Met, res.Object, res.Elapsed = met, obj, time.Since(start)
+		if observed != res.Observed && progress != nil {
+			progress(res.Elapsed, observed)
+		}
+		res.Observed = observed
+		return met
+	}
+
+	for {
+		obj, err := client.Get(ctx, target.Cluster, target.ResourceType, target.Namespace, target.Name)
+		switch {
+		case err == nil:
+			if observe(obj) {
+				return res, nil
+			}
+		case strings.Contains(err.Error(), "404"):
+			if observe(nil) {
+				return res, nil
+			}
+		case ctx.Err() != nil:
+			return res, timeoutError(cond, timeout, res)
+		default:
+			return res, fmt.Errorf("get %s %q: %w", target.ResourceType, target.Name, err)
+		}
+
+		opts := rancher.WatchOpts{
+			Namespace:     target.Namespace,
+			FieldSelector: "metadata.name=" + target.Name,
+		}
+		if obj != nil {
+			opts.ResourceVersion = obj.ObjectMeta.ResourceVersion
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			opts.TimeoutSeconds = int(time.Until(deadline).Seconds()) + 1
+		}
+		watchStart := time.Now()
+		werr := client.Watch(ctx, target.Cluster, target.ResourceType, opts, func(ev rancher.WatchEvent) (bool, error) {
+			switch ev.Type {
+			case rancher.WatchEventBookmark:
+				return false, nil
+			case rancher.WatchEventDeleted:
+				return observe(nil), nil
+			default:
+				o := ev.Object
+				return observe(&o), nil
+			}
+		})
+		if res.Met {
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return res, timeoutError(cond, timeout, res)
+		}
+		if werr != nil || time.Since(watchStart) < pollInterval {
+			// Watch not available, resourceVersion expired, or the proxy closed the stream early;
+			// re-check after a short delay instead of spinning.
+			select {
+			case <-ctx.Done():
+				return res, timeoutError(cond, timeout, res)
+			case <-time.After(pollInterval):
+			}
+		}
+	}
+}
+
+func timeoutError(cond Condition, timeout time.Duration, res *Result) error {
+	return fmt.Errorf("timed out after %s waiting for %s (last observed: %s)", timeout, cond, res.Observed)
+}
+
+func newJSONPath(path string) (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("wait").AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	return jp, nil
+}
+
+// evalJSONPath evaluates a JSONPath template against the object (apiVersion, kind, metadata, spec, status).
+func evalJSONPath(path string, obj *rancher.SteveResource) (string, error) {
+	jp, err := newJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := ObjectMap(obj)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("evaluate %s: %w", path, err)
+	}
+	return buf.String(), nil
+}
+
+// ObjectMap converts a SteveResource into a generic Kubernetes-shaped object map.
+func ObjectMap(obj *rancher.SteveResource) (map[string]interface{}, error) {
+	b, err := json.Marshal(map[string]interface{}{
+		"apiVersion": obj.TypeMeta.APIVersion,
+		"kind":       obj.TypeMeta.Kind,
+		"metadata":   obj.ObjectMeta,
+		"spec":       obj.Spec,
+		"status":     obj.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
@@ -0,0 +1,132 @@
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrostamii/rancher-mcp-server/pkg/client/rancher"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Condition
+		wantErr bool
+	}{
+		{"delete", Condition{Kind: KindDelete}, false},
+		{"exists", Condition{Kind: KindExists}, false},
+		{"condition=Ready", Condition{Kind: KindCondition, Type: "Ready", Value: "True"}, false},
+		{"condition=Available=False", Condition{Kind: KindCondition, Type: "Available", Value: "False"}, false},
+		{"jsonpath={.status.phase}=Running", Condition{Kind: KindJSONPath, Path: "{.status.phase}", Value: "Running"}, false},
+		{"jsonpath='{.status.readyToUse}'=true", Condition{Kind: KindJSONPath, Path: "{.status.readyToUse}", Value: "true"}, false},
+		{"jsonpath=.status.phase=Running", Condition{Kind: KindJSONPath, Path: "{.status.phase}", Value: "Running"}, false},
+		{"jsonpath={.status.phase", Condition{}, true},
+		{"ready", Condition{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCondition(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCondition(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCondition(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCondition_Evaluate(t *testing.T) {
+	obj := &rancher.SteveResource{
+		ObjectMeta: rancher.ObjectMeta{Name: "vm-1"},
+		Status: map[string]interface{}{
+			"printableStatus": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Paused", "status": "False", "reason": "NotPaused"},
+			},
+		},
+	}
+	cases := []struct {
+		cond string
+		obj  *rancher.SteveResource
+		want bool
+	}{
+		{"condition=Ready", obj, true},
+		{"condition=ready=true", obj, true},
+		{"condition=Paused", obj, false},
+		{"condition=Missing", obj, false},
+		{"jsonpath={.status.printableStatus}=Running", obj, true},
+		{"jsonpath={.status.printableStatus}=Stopped", obj, false},
+		{"jsonpath={.status.printableStatus}", obj, true},
+		{"jsonpath={.status.nothing}", obj, false},
+		{"exists", obj, true},
+		{"exists", nil, false},
+		{"delete", obj, false},
+		{"delete", nil, true},
+	}
+	for _, c := range cases {
+		cond, err := ParseCondition(c.cond)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q): %v", c.cond, err)
+		}
+		if got, observed := cond.Evaluate(c.obj); got != c.want {
+			t.Errorf("%s: Evaluate = %v (observed %q), want %v", c.cond, got, observed, c.want)
+		}
+	}
+}
+
+func TestUntil_Watch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") == "true" {
+			if got := r.URL.Query().Get("fieldSelector"); got != "metadata.name=web" {
+				t.Errorf("fieldSelector = %q", got)
+			}
+			if got := r.URL.Query().Get("resourceVersion"); got != "10" {
+				t.Errorf("resourceVersion = %q, want 10", got)
+			}
+			enc := json.NewEncoder(w)
+			for _, phase := range []string{"ContainerCreating", "Running"} {
+				enc.Encode(map[string]interface{}{
+					"type": "MODIFIED",
+					"object": map[string]interface{}{
+						"apiVersion": "v1", "kind": "Pod",
+						"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+						"status":   map[string]interface{}{"phase": phase},
+					},
+				})
+			}
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/pods/web") {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"apiVersion": "v1", "kind": "Pod",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default", "resourceVersion": "10"},
+				"status":   map[string]interface{}{"phase": "Pending"},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := rancher.NewSteveClient(srv.URL, "token", true)
+	cond, _ := ParseCondition("jsonpath={.status.phase}=Running")
+	var seen []string
+	res, err := Until(context.Background(), client, Target{Cluster: "c-xxx", ResourceType: "core.v1.pods", Namespace: "default", Name: "web"}, cond, 5*time.Second,
+		func(_ time.Duration, observed string) { seen = append(seen, observed) })
+	if err != nil {
+		t.Fatalf("Until: %v", err)
+	}
+	if !res.Met || res.Observed != "Running" {
+		t.Errorf("result = %+v, want met with Running", res)
+	}
+	if strings.Join(seen, ",") != "Pending,ContainerCreating,Running" {
+		t.Errorf("progress = %v", seen)
+	}
+}